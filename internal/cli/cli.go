@@ -18,6 +18,16 @@ func Run(ctx context.Context, args []string) int {
 		return 0
 	}
 
+	// Hooks, checks, branch cleanup and distill started by a command run in
+	// the background; let them finish before the process exits, then send the
+	// webhooks queued meanwhile (`hazel up` is not necessarily running).
+	defer func() {
+		hazel.WaitBackground()
+		if root, err := resolveCommandRoot(); err == nil {
+			hazel.FlushWebhooks(ctx, root)
+		}
+	}()
+
 	cmd := args[0]
 	switch cmd {
 	case "init":
@@ -36,10 +46,20 @@ func Run(ctx context.Context, args []string) int {
 		return cmdDown(ctx, args[1:])
 	case "plan":
 		return cmdPlan(ctx, args[1:])
+	case "distill":
+		return cmdDistill(ctx, args[1:])
+	case "packet":
+		return cmdPacket(ctx, args[1:])
 	case "sync-wiki":
 		return cmdSyncWiki(ctx, args[1:])
+	case "pr-sync":
+		return cmdPRSync(ctx, args[1:])
 	case "config":
 		return cmdConfig(ctx, args[1:])
+	case "import":
+		return cmdImport(ctx, args[1:])
+	case "search":
+		return cmdSearch(ctx, args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", cmd)
 		usage(os.Stderr)
@@ -56,8 +76,13 @@ func usage(w *os.File) {
 	fmt.Fprintln(w, "  hazel down")
 	fmt.Fprintln(w, "  hazel run")
 	fmt.Fprintln(w, "  hazel plan HZ-0001")
+	fmt.Fprintln(w, "  hazel distill [--project KEY] [--agent] HZ-0001")
+	fmt.Fprintln(w, "  hazel packet render [--project KEY] [--mode plan|implement|review|distill|chat] [--prompt TEXT] HZ-0001")
 	fmt.Fprintln(w, "  hazel sync-wiki [--project KEY]")
-	fmt.Fprintln(w, "  hazel config [--github-token TOKEN] [--clear-github-token] [--git-base-branch BRANCH]")
+	fmt.Fprintln(w, "  hazel pr-sync [--project KEY]")
+	fmt.Fprintln(w, "  hazel search QUERY [--project KEY] [--kind wiki|task|chat] [--limit N]")
+	fmt.Fprintln(w, "  hazel import github --project KEY [--label L] [--state open|closed|all]")
+	fmt.Fprintln(w, "  hazel config [--forge-token KIND=TOKEN] [--clear-forge-token KIND] [--forge-host HOST=KIND] [--git-base-branch BRANCH]")
 	fmt.Fprintln(w, "  hazel export --html [--chatgpt-project]")
 	fmt.Fprintln(w, "  hazel archive [--before DATE]")
 	fmt.Fprintln(w, "  hazel doctor")
@@ -219,6 +244,9 @@ func cmdRun(ctx context.Context, args []string) int {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	for _, w := range res.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
 	if res.DispatchedTaskID == "" {
 		fmt.Println("No READY tasks")
 		return 0
@@ -267,6 +295,9 @@ func cmdArchive(ctx context.Context, args []string) int {
 	for _, id := range res.ArchivedIDs {
 		fmt.Println(" -", id)
 	}
+	for _, id := range res.DistillPendingIDs {
+		fmt.Printf("Kept %s: wiki distill proposal awaiting accept/decline\n", id)
+	}
 	return 0
 }
 
@@ -421,6 +452,83 @@ func cmdPlan(ctx context.Context, args []string) int {
 	return 0
 }
 
+func cmdDistill(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("distill", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	project := fs.String("project", "", "tracked project key (nexus mode)")
+	agent := fs.Bool("agent", false, "have the plan agent write the page instead of extracting it")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: hazel distill [--project KEY] [--agent] HZ-0001")
+		return 2
+	}
+	id := fs.Arg(0)
+
+	root, err := resolveCommandRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res, err := hazel.Distill(ctx, root, id, hazel.DistillOptions{Project: strings.TrimSpace(*project), Agent: *agent})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Distilled %s (%s) -> wiki/%s\n", id, res.Method, res.Page)
+	if res.Note != "" {
+		fmt.Printf("Fell back to extract: %s\n", res.Note)
+	}
+	if res.AgentExitCode != nil {
+		fmt.Printf("Agent exit: %d\n", *res.AgentExitCode)
+	}
+	if res.RunLogPath != "" {
+		fmt.Printf("Run log: %s\n", res.RunLogPath)
+	}
+	fmt.Printf("Proposal: %s (accept or decline on the task page)\n", res.Path)
+	return 0
+}
+
+func cmdPacket(ctx context.Context, args []string) int {
+	_ = ctx
+	const usageLine = "usage: hazel packet render [--project KEY] [--mode plan|implement|review|distill|chat] [--prompt TEXT] HZ-0001"
+	if len(args) == 0 || args[0] != "render" {
+		fmt.Fprintln(os.Stderr, usageLine)
+		return 2
+	}
+	fs := flag.NewFlagSet("packet render", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	project := fs.String("project", "", "tracked project key (nexus mode)")
+	mode := fs.String("mode", "implement", "packet mode: plan, implement, review, distill or chat")
+	prompt := fs.String("prompt", "", "chat message (chat mode)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if fs.NArg() > 1 || (fs.NArg() == 0 && strings.TrimSpace(*mode) != "chat") {
+		fmt.Fprintln(os.Stderr, usageLine)
+		return 2
+	}
+
+	root, err := resolveCommandRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	body, err := hazel.RenderPacket(root, hazel.PacketOptions{
+		Project: strings.TrimSpace(*project),
+		TaskID:  fs.Arg(0),
+		Mode:    *mode,
+		Prompt:  *prompt,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(body)
+	return 0
+}
+
 func cmdSyncWiki(ctx context.Context, args []string) int {
 	_ = ctx
 	fs := flag.NewFlagSet("sync-wiki", flag.ContinueOnError)
@@ -444,6 +552,136 @@ func cmdSyncWiki(ctx context.Context, args []string) int {
 	return 0
 }
 
+func cmdPRSync(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("pr-sync", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	project := fs.String("project", "", "optional tracked project key to reconcile")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	root, err := resolveCommandRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res, err := hazel.SyncPRs(ctx, root, strings.TrimSpace(*project))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, c := range res.Changes {
+		line := fmt.Sprintf("%s %s: %s", c.ProjectKey, c.TaskID, c.State)
+		if c.Repo != "" {
+			line = fmt.Sprintf("%s %s [%s]: %s", c.ProjectKey, c.TaskID, c.Repo, c.State)
+		}
+		if c.Review != "" {
+			line += " review=" + c.Review
+		}
+		if c.Checks != "" {
+			line += " checks=" + c.Checks
+		}
+		if c.MovedDone {
+			line += " -> DONE (" + c.MergedSHA + ")"
+		}
+		if c.Flagged {
+			line += " (closed without merge)"
+		}
+		fmt.Println(line)
+	}
+	for _, e := range res.Errors {
+		fmt.Fprintln(os.Stderr, "warning:", e)
+	}
+	fmt.Printf("Checked %d PR(s), %d changed\n", res.Checked, len(res.Changes))
+	return 0
+}
+
+func cmdImport(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] != "github" {
+		fmt.Fprintln(os.Stderr, "usage: hazel import github --project KEY [--label L] [--state open|closed|all]")
+		return 2
+	}
+	fs := flag.NewFlagSet("import github", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	project := fs.String("project", "", "tracked project key to import into")
+	label := fs.String("label", "", "only import issues with this label")
+	state := fs.String("state", "open", "issue state: open, closed or all")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if strings.TrimSpace(*project) == "" {
+		fmt.Fprintln(os.Stderr, "--project is required")
+		return 2
+	}
+
+	root, err := resolveCommandRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	res, err := hazel.ImportGitHub(ctx, root, hazel.GitHubImportOptions{Project: strings.TrimSpace(*project), Label: *label, State: *state})
+	if res != nil {
+		for _, c := range res.Created {
+			fmt.Printf("%s <- #%d %s (%s)\n", c.TaskID, c.Number, c.Title, c.Status)
+		}
+		for _, l := range res.LinkedPRs {
+			fmt.Printf("%s: linked %s (%s)\n", l.TaskID, l.URL, l.Branch)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Imported %d issue(s), %d already on the board, linked %d PR(s)\n", len(res.Created), res.Skipped, len(res.LinkedPRs))
+	return 0
+}
+
+func cmdSearch(ctx context.Context, args []string) int {
+	_ = ctx
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	project := fs.String("project", "", "only search this tracked project")
+	kind := fs.String("kind", "", "only search wiki, task or chat documents")
+	limit := fs.Int("limit", 20, "maximum number of results")
+	// Accept flags before or after the query words.
+	var words []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return 2
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		words = append(words, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	query := strings.TrimSpace(strings.Join(words, " "))
+	if query == "" {
+		fmt.Fprintln(os.Stderr, "usage: hazel search QUERY [--project KEY] [--kind wiki|task|chat] [--limit N]")
+		return 2
+	}
+
+	root, err := resolveCommandRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	hits, err := hazel.SearchRoot(root, query, hazel.SearchOptions{Project: strings.TrimSpace(*project), Kind: strings.TrimSpace(*kind), Limit: *limit})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, h := range hits {
+		where := h.Path
+		if h.Project != "" {
+			where = h.Project + ":" + where
+		}
+		fmt.Printf("%-4s %s (%.2f)\n     %s\n", h.Kind, where, h.Score, h.Snippet)
+	}
+	fmt.Printf("%d result(s)\n", len(hits))
+	return 0
+}
+
 func cmdConfig(ctx context.Context, args []string) int {
 	_ = ctx
 	fs := flag.NewFlagSet("config", flag.ContinueOnError)
@@ -451,11 +689,15 @@ func cmdConfig(ctx context.Context, args []string) int {
 	token := fs.String("github-token", "", "GitHub token for PR automation")
 	clearToken := fs.Bool("clear-github-token", false, "remove stored github token")
 	baseBranch := fs.String("git-base-branch", "", "default base branch for task PR flow")
+	forgeToken := fs.String("forge-token", "", "forge token as KIND=TOKEN or HOST=TOKEN (github, gitlab, gitea)")
+	clearForgeToken := fs.String("clear-forge-token", "", "remove stored token for a forge kind or host")
+	forgeHost := fs.String("forge-host", "", "map a self-hosted remote to a forge as HOST=KIND (gitlab, gitea, github, none)")
 	if err := fs.Parse(args); err != nil {
 		return 2
 	}
-	if strings.TrimSpace(*token) == "" && !*clearToken && strings.TrimSpace(*baseBranch) == "" {
-		fmt.Fprintln(os.Stderr, "usage: hazel config [--github-token TOKEN] [--clear-github-token] [--git-base-branch BRANCH]")
+	if strings.TrimSpace(*token) == "" && !*clearToken && strings.TrimSpace(*baseBranch) == "" &&
+		strings.TrimSpace(*forgeToken) == "" && strings.TrimSpace(*clearForgeToken) == "" && strings.TrimSpace(*forgeHost) == "" {
+		fmt.Fprintln(os.Stderr, "usage: hazel config [--forge-token KIND=TOKEN] [--clear-forge-token KIND] [--forge-host HOST=KIND] [--github-token TOKEN] [--clear-github-token] [--git-base-branch BRANCH]")
 		return 2
 	}
 
@@ -476,6 +718,25 @@ func cmdConfig(ctx context.Context, args []string) int {
 		b := strings.TrimSpace(*baseBranch)
 		upd.GitBaseBranch = &b
 	}
+	if v := strings.TrimSpace(*forgeToken); v != "" {
+		k, t, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(k) == "" || strings.TrimSpace(t) == "" {
+			fmt.Fprintln(os.Stderr, "--forge-token must be KIND=TOKEN")
+			return 2
+		}
+		upd.ForgeTokens = map[string]string{k: t}
+	}
+	if v := strings.TrimSpace(*clearForgeToken); v != "" {
+		upd.ClearForgeTokens = []string{v}
+	}
+	if v := strings.TrimSpace(*forgeHost); v != "" {
+		h, k, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(h) == "" {
+			fmt.Fprintln(os.Stderr, "--forge-host must be HOST=KIND")
+			return 2
+		}
+		upd.ForgeHosts = map[string]string{h: k}
+	}
 	if err := hazel.UpdateConfig(root, upd); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
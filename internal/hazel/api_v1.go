@@ -0,0 +1,844 @@
+package hazel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The /api/v1 surface is a JSON mirror of what the HTML UI can do, intended for
+// editor plugins and internal tooling. Every route is declared once in
+// apiV1Routes; the OpenAPI document is generated from the same table.
+
+const apiV1Prefix = "/api/v1"
+
+type apiV1Ctx struct {
+	Root    string
+	Nexus   *Nexus
+	Params  map[string]string
+	Project TrackedProject
+}
+
+type apiV1Route struct {
+	Method  string
+	Pattern string // path below /api/v1; {name} captures one segment, {name...} the rest
+	Summary string
+	Query   []string // documented query parameters
+	Body    string   // request schema name (components/schemas)
+	Returns string   // response schema name; "[]Name" for arrays, "text" for plain text
+	Status  int      // success status code (default 200)
+	Handler func(http.ResponseWriter, *http.Request, *apiV1Ctx)
+}
+
+var apiV1Routes []apiV1Route
+
+func init() {
+	apiV1Routes = []apiV1Route{
+		{Method: http.MethodGet, Pattern: "/openapi.json", Summary: "OpenAPI document for this API", Returns: "object", Handler: apiV1OpenAPI},
+		{Method: http.MethodGet, Pattern: "/search", Summary: "Full-text search over wiki pages, task files and chat transcripts", Query: []string{"q", "project", "kind", "limit"}, Returns: "[]SearchHit", Handler: apiV1Search},
+		{Method: http.MethodGet, Pattern: "/projects", Summary: "List tracked projects", Returns: "[]Project", Handler: apiV1ListProjects},
+		{Method: http.MethodGet, Pattern: "/projects/{project}", Summary: "Get a tracked project", Returns: "Project", Handler: apiV1GetProject},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/preflight", Summary: "Repository safety checks run before git and agent actions", Returns: "Preflight", Handler: apiV1GetPreflight},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/tasks", Summary: "List board tasks", Query: []string{"status", "priority", "q"}, Returns: "[]Task", Handler: apiV1ListTasks},
+		{Method: http.MethodPost, Pattern: "/projects/{project}/tasks", Summary: "Create a task in BACKLOG", Body: "TaskCreate", Returns: "Task", Status: http.StatusCreated, Handler: apiV1CreateTask},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/tasks/{id}", Summary: "Get a task", Returns: "Task", Handler: apiV1GetTask},
+		{Method: http.MethodPatch, Pattern: "/projects/{project}/tasks/{id}", Summary: "Update task title, order, deps, linked projects, color or priority", Body: "TaskPatch", Returns: "Task", Handler: apiV1PatchTask},
+		{Method: http.MethodDelete, Pattern: "/projects/{project}/tasks/{id}", Summary: "Remove a task from the board (files move to the archive)", Status: http.StatusNoContent, Handler: apiV1DeleteTask},
+		{Method: http.MethodPost, Pattern: "/projects/{project}/tasks/{id}/transition", Summary: "Move a task to another status (guardrails apply)", Body: "Transition", Returns: "Task", Handler: apiV1TransitionTask},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/tasks/{id}/markdown", Summary: "Read task.md with the HAZEL-CONFIG block as structured fields", Returns: "TaskMarkdown", Handler: apiV1GetTaskMarkdown},
+		{Method: http.MethodPut, Pattern: "/projects/{project}/tasks/{id}/markdown", Summary: "Replace task.md body and optionally its config", Body: "TaskMarkdown", Returns: "TaskMarkdown", Handler: apiV1PutTaskMarkdown},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/runs", Summary: "List agent runs, newest first", Query: []string{"task"}, Returns: "[]Run", Handler: apiV1ListRuns},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/runs/{run}", Summary: "Get run metadata", Returns: "Run", Handler: apiV1GetRun},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/runs/{run}/log", Summary: "Get the run log as plain text", Query: []string{"tail"}, Returns: "text", Handler: apiV1GetRunLog},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/wiki", Summary: "List wiki pages", Returns: "[]WikiPage", Handler: apiV1ListWiki},
+		{Method: http.MethodGet, Pattern: "/projects/{project}/wiki/{path...}", Summary: "Get a wiki page", Returns: "WikiPage", Handler: apiV1GetWikiPage},
+	}
+}
+
+type apiV1Error struct {
+	Error apiV1ErrorBody `json:"error"`
+}
+
+type apiV1ErrorBody struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type apiV1Project struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	RepoPath string `json:"repo_path"`
+	RepoSlug string `json:"repo_slug,omitempty"`
+}
+
+type apiV1Task struct {
+	ID        string        `json:"id"`
+	Project   string        `json:"project"`
+	Title     string        `json:"title"`
+	Status    Status        `json:"status"`
+	Order     *int          `json:"order,omitempty"`
+	Deps      []string      `json:"deps,omitempty"`
+	Projects  []string      `json:"projects,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Config    taskHazelMeta `json:"config"`
+}
+
+type apiV1TaskMarkdown struct {
+	Body   string         `json:"body"`
+	Config *taskHazelMeta `json:"config,omitempty"`
+}
+
+type apiV1WikiPage struct {
+	Path       string        `json:"path"`
+	Size       int64         `json:"size"`
+	ModifiedAt time.Time     `json:"modified_at"`
+	Meta       *wikiPageMeta `json:"meta,omitempty"`
+	Content    string        `json:"content,omitempty"`
+}
+
+// apiV1WikiMeta returns a page's frontmatter, or nil when it has none.
+func apiV1WikiMeta(rel string, b []byte) *wikiPageMeta {
+	if !isWikiMarkdown(rel) {
+		return nil
+	}
+	if meta, _, _ := splitWikiFrontmatter(b); !meta.empty() {
+		return &meta
+	}
+	return nil
+}
+
+func writeAPIV1JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIV1Error(w http.ResponseWriter, status int, code string, msg string) {
+	writeAPIV1JSON(w, status, apiV1Error{Error: apiV1ErrorBody{Status: status, Code: code, Message: msg}})
+}
+
+func decodeAPIV1Body(r *http.Request, out any) error {
+	dec := json.NewDecoder(io.LimitReader(r.Body, 4<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+func matchAPIV1Pattern(pattern, path string) (map[string]string, bool) {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	xs := strings.Split(strings.Trim(path, "/"), "/")
+	params := map[string]string{}
+	for i, seg := range ps {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			if i >= len(xs) {
+				return nil, false
+			}
+			params[strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "...}")] = strings.Join(xs[i:], "/")
+			return params, true
+		}
+		if i >= len(xs) {
+			return nil, false
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if xs[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(seg, "{}")] = xs[i]
+			continue
+		}
+		if seg != xs[i] {
+			return nil, false
+		}
+	}
+	return params, len(xs) == len(ps)
+}
+
+func apiV1(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	path := strings.TrimPrefix(r.URL.Path, apiV1Prefix)
+	methodMismatch := false
+	for _, rt := range apiV1Routes {
+		params, ok := matchAPIV1Pattern(rt.Pattern, path)
+		if !ok {
+			continue
+		}
+		if rt.Method != r.Method {
+			methodMismatch = true
+			continue
+		}
+		c := &apiV1Ctx{Root: root, Nexus: nexus, Params: params}
+		if key, ok := params["project"]; ok {
+			if nexus == nil {
+				writeAPIV1Error(w, http.StatusInternalServerError, "nexus_unavailable", "nexus mode required")
+				return
+			}
+			p, found := nexus.ProjectByKey(key)
+			if !found {
+				writeAPIV1Error(w, http.StatusNotFound, "project_not_found", fmt.Sprintf("unknown project %q", key))
+				return
+			}
+			c.Project = p
+		}
+		rt.Handler(w, r, c)
+		return
+	}
+	if methodMismatch {
+		writeAPIV1Error(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	writeAPIV1Error(w, http.StatusNotFound, "not_found", "no such endpoint: "+r.URL.Path)
+}
+
+func apiV1ProjectOf(p TrackedProject) apiV1Project {
+	return apiV1Project{Key: p.Key, Name: p.Name, RepoPath: p.RepoPath, RepoSlug: p.RepoSlug}
+}
+
+func apiV1TaskOf(project TrackedProject, t *BoardTask) apiV1Task {
+	out := apiV1Task{
+		ID:        t.ID,
+		Project:   project.Key,
+		Title:     t.Title,
+		Status:    t.Status,
+		Order:     t.Order,
+		Deps:      t.Deps,
+		Projects:  t.Projects,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if md, err := readTaskMD(project.StorageRoot, t.ID); err == nil {
+		if cfg, ok := getTaskConfig(md); ok {
+			out.Config = cfg
+		}
+	}
+	return out
+}
+
+func apiV1ListProjects(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	out := []apiV1Project{}
+	if c.Nexus != nil {
+		for _, p := range c.Nexus.Projects {
+			out = append(out, apiV1ProjectOf(p))
+		}
+	}
+	writeAPIV1JSON(w, http.StatusOK, out)
+}
+
+func apiV1GetProject(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	writeAPIV1JSON(w, http.StatusOK, apiV1ProjectOf(c.Project))
+}
+
+func apiV1GetPreflight(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	pcfg, _ := loadConfigOrDefault(c.Project.StorageRoot)
+	cfg, _ := loadConfigOrDefault(c.Root)
+	problems := checkRepoPreflight(c.Project, projectBaseBranch(cfg, c.Project))
+	if problems == nil {
+		problems = []preflightProblem{}
+	}
+	writeAPIV1JSON(w, http.StatusOK, map[string]any{"mode": preflightMode(pcfg), "problems": problems})
+}
+
+func apiV1ListTasks(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	var b Board
+	if err := readYAMLFile(boardPath(c.Project.StorageRoot), &b); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "board_unreadable", err.Error())
+		return
+	}
+	q := r.URL.Query()
+	statuses := map[Status]bool{}
+	for _, raw := range q["status"] {
+		for _, s := range strings.Split(raw, ",") {
+			st := Status(strings.ToUpper(strings.TrimSpace(s)))
+			if st == "" {
+				continue
+			}
+			if !st.Valid() {
+				writeAPIV1Error(w, http.StatusBadRequest, "invalid_status", fmt.Sprintf("invalid status %q", s))
+				return
+			}
+			statuses[st] = true
+		}
+	}
+	priority := strings.ToUpper(strings.TrimSpace(q.Get("priority")))
+	text := strings.ToLower(strings.TrimSpace(q.Get("q")))
+
+	out := []apiV1Task{}
+	for _, t := range b.Tasks {
+		if len(statuses) > 0 && !statuses[t.Status] {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(t.ID+" "+t.Title), text) {
+			continue
+		}
+		at := apiV1TaskOf(c.Project, t)
+		if priority != "" && strings.ToUpper(at.Config.Priority) != priority {
+			continue
+		}
+		out = append(out, at)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	writeAPIV1JSON(w, http.StatusOK, out)
+}
+
+func apiV1CreateTask(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	var in struct {
+		Title string `json:"title"`
+	}
+	if err := decodeAPIV1Body(r, &in); err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	if strings.TrimSpace(in.Title) == "" {
+		writeAPIV1Error(w, http.StatusBadRequest, "title_required", "title is required")
+		return
+	}
+	t, err := createNewTask(c.Project.StorageRoot, in.Title)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "create_failed", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusCreated, apiV1TaskOf(c.Project, t))
+}
+
+// apiV1LoadTask resolves the {id} path parameter, writing a 404 when absent.
+func apiV1LoadTask(w http.ResponseWriter, c *apiV1Ctx) (*BoardTask, bool) {
+	t, err := findTaskInBoard(c.Project.StorageRoot, c.Params["id"])
+	if err != nil {
+		writeAPIV1Error(w, http.StatusNotFound, "task_not_found", err.Error())
+		return nil, false
+	}
+	return t, true
+}
+
+func apiV1GetTask(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	t, ok := apiV1LoadTask(w, c)
+	if !ok {
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, apiV1TaskOf(c.Project, t))
+}
+
+func apiV1PatchTask(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	var in struct {
+		Title    *string   `json:"title"`
+		Order    *int      `json:"order"`
+		Deps     *[]string `json:"deps"`
+		Projects *[]string `json:"projects"`
+		Color    *string   `json:"color"`
+		Priority *string   `json:"priority"`
+	}
+	if err := decodeAPIV1Body(r, &in); err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	id := c.Params["id"]
+	root := c.Project.StorageRoot
+	if in.Color != nil && !validColorKey(strings.TrimSpace(*in.Color)) {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_color", fmt.Sprintf("invalid color %q", *in.Color))
+		return
+	}
+	if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
+		writeAPIV1Error(w, http.StatusBadRequest, "title_required", "title cannot be empty")
+		return
+	}
+	var projects []string
+	if in.Projects != nil {
+		var err error
+		if projects, err = normalizeLinkedProjects(c.Nexus, c.Project.Key, *in.Projects); err != nil {
+			writeAPIV1Error(w, http.StatusBadRequest, "invalid_projects", err.Error())
+			return
+		}
+	}
+
+	var b Board
+	if err := readYAMLFile(boardPath(root), &b); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "board_unreadable", err.Error())
+		return
+	}
+	var task *BoardTask
+	for _, t := range b.Tasks {
+		if t.ID == id {
+			task = t
+			break
+		}
+	}
+	if task == nil {
+		writeAPIV1Error(w, http.StatusNotFound, "task_not_found", "task not found: "+id)
+		return
+	}
+	// Everything is validated before anything is written, so a rejected
+	// PATCH leaves both task.md and the board untouched.
+	md := ""
+	if in.Color != nil || in.Priority != nil {
+		var err error
+		if md, err = readTaskMD(root, id); err != nil {
+			writeAPIV1Error(w, http.StatusInternalServerError, "task_unreadable", err.Error())
+			return
+		}
+		if in.Color != nil {
+			if md, err = setTaskColorInMD(md, strings.TrimSpace(*in.Color)); err != nil {
+				writeAPIV1Error(w, http.StatusBadRequest, "invalid_config", err.Error())
+				return
+			}
+		}
+		if in.Priority != nil {
+			if md, err = setTaskPriorityInMD(md, *in.Priority); err != nil {
+				writeAPIV1Error(w, http.StatusBadRequest, "invalid_priority", err.Error())
+				return
+			}
+		}
+	}
+	if in.Title != nil {
+		task.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.Order != nil {
+		o := *in.Order
+		task.Order = &o
+	}
+	if in.Deps != nil {
+		task.Deps = *in.Deps
+	}
+	if in.Projects != nil {
+		task.Projects = projects
+	}
+	task.UpdatedAt = time.Now()
+	if err := b.Validate(); err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_task", err.Error())
+		return
+	}
+	if md != "" {
+		if err := writeTaskMD(root, id, md); err != nil {
+			writeAPIV1Error(w, http.StatusInternalServerError, "write_failed", err.Error())
+			return
+		}
+	}
+	if err := writeYAMLFile(boardPath(root), &b); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "write_failed", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, apiV1TaskOf(c.Project, task))
+}
+
+func apiV1DeleteTask(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	root := c.Project.StorageRoot
+	id := c.Params["id"]
+	var b Board
+	if err := readYAMLFile(boardPath(root), &b); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "board_unreadable", err.Error())
+		return
+	}
+	keep := b.Tasks[:0]
+	found := false
+	for _, t := range b.Tasks {
+		if t.ID == id {
+			found = true
+			continue
+		}
+		keep = append(keep, t)
+	}
+	if !found {
+		writeAPIV1Error(w, http.StatusNotFound, "task_not_found", "task not found: "+id)
+		return
+	}
+	b.Tasks = keep
+	// Archive the task the way `hazel archive` does: a distill of it is
+	// stopped first, and its merged branch is cleaned up before the move.
+	waitTaskDistill(root, id, true)
+	src, dst := taskDir(root, id), filepath.Join(archiveDir(root), id)
+	moved := exists(src)
+	if err := archiveTaskFiles(root, id); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "archive_failed", err.Error())
+		return
+	}
+	if err := writeYAMLFile(boardPath(root), &b); err != nil {
+		if moved {
+			_ = os.Rename(dst, src) // keep the task where the board still lists it
+		}
+		writeAPIV1Error(w, http.StatusInternalServerError, "write_failed", err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiV1TransitionTask(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	var in struct {
+		Status Status `json:"status"`
+	}
+	if err := decodeAPIV1Body(r, &in); err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	if _, ok := apiV1LoadTask(w, c); !ok {
+		return
+	}
+	t, err := transitionTaskStatus(c.Project.StorageRoot, c.Params["id"], Status(strings.ToUpper(strings.TrimSpace(string(in.Status)))))
+	if err != nil {
+		if isTransitionError(err) {
+			writeAPIV1Error(w, http.StatusConflict, "transition_blocked", err.Error())
+			return
+		}
+		writeAPIV1Error(w, http.StatusInternalServerError, "transition_failed", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, apiV1TaskOf(c.Project, t))
+}
+
+func apiV1TaskMarkdownOf(md string) (apiV1TaskMarkdown, error) {
+	fm, has, without, err := parseHazelConfigBlock(md)
+	if err != nil {
+		return apiV1TaskMarkdown{}, err
+	}
+	out := apiV1TaskMarkdown{Body: strings.TrimRight(without, " \t\r\n") + "\n"}
+	if has {
+		cfg := fm.Hazel
+		out.Config = &cfg
+	}
+	return out, nil
+}
+
+func apiV1GetTaskMarkdown(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	if _, ok := apiV1LoadTask(w, c); !ok {
+		return
+	}
+	md, err := readTaskMD(c.Project.StorageRoot, c.Params["id"])
+	if err != nil {
+		writeAPIV1Error(w, http.StatusNotFound, "task_md_missing", err.Error())
+		return
+	}
+	out, err := apiV1TaskMarkdownOf(md)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusUnprocessableEntity, "invalid_config_block", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, out)
+}
+
+func apiV1PutTaskMarkdown(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	var in apiV1TaskMarkdown
+	if err := decodeAPIV1Body(r, &in); err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_body", err.Error())
+		return
+	}
+	if _, ok := apiV1LoadTask(w, c); !ok {
+		return
+	}
+	root := c.Project.StorageRoot
+	id := c.Params["id"]
+	if err := ensureTaskScaffold(root, id); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "scaffold_failed", err.Error())
+		return
+	}
+	current, err := readTaskMD(root, id)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "task_unreadable", err.Error())
+		return
+	}
+	// The body never carries config; a stray block in it is dropped in favor of
+	// the structured field (or the existing block when config is omitted).
+	body := in.Body
+	if _, has, without, err := parseHazelConfigBlock(body); err == nil && has {
+		body = without
+	}
+	cfg, hasCfg, _, err := parseHazelConfigBlock(current)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusUnprocessableEntity, "invalid_config_block", err.Error())
+		return
+	}
+	if in.Config != nil {
+		if k := strings.TrimSpace(in.Config.Color); k != "" && !validColorKey(k) {
+			writeAPIV1Error(w, http.StatusBadRequest, "invalid_color", fmt.Sprintf("invalid color %q", k))
+			return
+		}
+		p := strings.ToUpper(strings.TrimSpace(in.Config.Priority))
+		if p != "" && p != "HIGH" && p != "MEDIUM" && p != "LOW" {
+			writeAPIV1Error(w, http.StatusBadRequest, "invalid_priority", fmt.Sprintf("invalid priority %q", in.Config.Priority))
+			return
+		}
+		cfg.Hazel = *in.Config
+		cfg.Hazel.Priority = p
+		hasCfg = true
+	}
+	updated := strings.TrimRight(body, " \t\r\n") + "\n"
+	if hasCfg {
+		block, err := formatHazelConfigBlock(cfg)
+		if err != nil {
+			writeAPIV1Error(w, http.StatusInternalServerError, "format_failed", err.Error())
+			return
+		}
+		updated += block
+	}
+	if err := writeTaskMD(root, id, updated); err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "write_failed", err.Error())
+		return
+	}
+	_ = bumpBoardUpdatedAt(root, id)
+	out, err := apiV1TaskMarkdownOf(updated)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "invalid_config_block", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, out)
+}
+
+func apiV1ListRuns(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	runs, err := listRunRecords(c.Project.StorageRoot, strings.TrimSpace(r.URL.Query().Get("task")))
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "runs_unreadable", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, runs)
+}
+
+func apiV1GetRun(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	p, ok := runLogPathForName(c.Project.StorageRoot, c.Params["run"])
+	if !ok {
+		writeAPIV1Error(w, http.StatusNotFound, "run_not_found", "run not found: "+c.Params["run"])
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, readRunRecord(p))
+}
+
+func apiV1GetRunLog(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	p, ok := runLogPathForName(c.Project.StorageRoot, c.Params["run"])
+	if !ok {
+		writeAPIV1Error(w, http.StatusNotFound, "run_not_found", "run not found: "+c.Params["run"])
+		return
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "log_unreadable", err.Error())
+		return
+	}
+	out := string(b)
+	if raw := strings.TrimSpace(r.URL.Query().Get("tail")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeAPIV1Error(w, http.StatusBadRequest, "invalid_tail", "tail must be a positive integer")
+			return
+		}
+		out = tailLines(out, n)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(out))
+}
+
+func apiV1ListWiki(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	dir := projectWikiDir(c.Project.StorageRoot)
+	out := []apiV1WikiPage{}
+	for _, rel := range listWikiFiles(dir) {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		st, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		page := apiV1WikiPage{Path: rel, Size: st.Size(), ModifiedAt: st.ModTime()}
+		if b, err := os.ReadFile(p); err == nil {
+			page.Meta = apiV1WikiMeta(rel, b)
+		}
+		out = append(out, page)
+	}
+	writeAPIV1JSON(w, http.StatusOK, out)
+}
+
+func apiV1GetWikiPage(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	rel, ok := cleanWikiRel(c.Params["path"])
+	if !ok {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_path", "invalid wiki path")
+		return
+	}
+	p := filepath.Join(projectWikiDir(c.Project.StorageRoot), filepath.FromSlash(rel))
+	st, err := os.Stat(p)
+	if err != nil || st.IsDir() {
+		writeAPIV1Error(w, http.StatusNotFound, "page_not_found", "wiki page not found: "+rel)
+		return
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		writeAPIV1Error(w, http.StatusInternalServerError, "page_unreadable", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, apiV1WikiPage{Path: rel, Size: st.Size(), ModifiedAt: st.ModTime(), Meta: apiV1WikiMeta(rel, b), Content: string(b)})
+}
+
+func apiV1Search(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	hits, err := Search(c.Root, c.Nexus, q.Get("q"), SearchOptions{
+		Project: strings.TrimSpace(q.Get("project")),
+		Kind:    strings.TrimSpace(q.Get("kind")),
+		Limit:   limit,
+	})
+	if err != nil {
+		writeAPIV1Error(w, http.StatusBadRequest, "invalid_search", err.Error())
+		return
+	}
+	writeAPIV1JSON(w, http.StatusOK, hits)
+}
+
+func apiV1OpenAPI(w http.ResponseWriter, r *http.Request, c *apiV1Ctx) {
+	writeAPIV1JSON(w, http.StatusOK, buildAPIV1OpenAPI())
+}
+
+// buildAPIV1OpenAPI renders apiV1Routes as an OpenAPI 3 document.
+func buildAPIV1OpenAPI() map[string]any {
+	schemaRef := func(name string) map[string]any {
+		switch {
+		case name == "object":
+			return map[string]any{"type": "object"}
+		case strings.HasPrefix(name, "[]"):
+			return map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/" + strings.TrimPrefix(name, "[]")}}
+		default:
+			return map[string]any{"$ref": "#/components/schemas/" + name}
+		}
+	}
+	errorResp := map[string]any{
+		"description": "Error",
+		"content":     map[string]any{"application/json": map[string]any{"schema": schemaRef("Error")}},
+	}
+
+	paths := map[string]any{}
+	for _, rt := range apiV1Routes {
+		p := apiV1Prefix + strings.ReplaceAll(rt.Pattern, "...}", "}")
+		item, _ := paths[p].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[p] = item
+		}
+		var params []any
+		for _, seg := range strings.Split(rt.Pattern, "/") {
+			if strings.HasPrefix(seg, "{") {
+				params = append(params, map[string]any{
+					"name": strings.TrimSuffix(strings.Trim(seg, "{}"), "..."), "in": "path", "required": true,
+					"schema": map[string]any{"type": "string"},
+				})
+			}
+		}
+		for _, q := range rt.Query {
+			params = append(params, map[string]any{"name": q, "in": "query", "required": false, "schema": map[string]any{"type": "string"}})
+		}
+		status := rt.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := map[string]any{"description": http.StatusText(status)}
+		switch {
+		case rt.Returns == "text":
+			ok["content"] = map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}}
+		case rt.Returns != "":
+			ok["content"] = map[string]any{"application/json": map[string]any{"schema": schemaRef(rt.Returns)}}
+		}
+		op := map[string]any{
+			"summary":     rt.Summary,
+			"operationId": apiV1OperationID(rt),
+			"responses":   map[string]any{strconv.Itoa(status): ok, "default": errorResp},
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+		if rt.Body != "" {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemaRef(rt.Body)}},
+			}
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+
+	str := map[string]any{"type": "string"}
+	strOpt := func(desc string) map[string]any { return map[string]any{"type": "string", "description": desc} }
+	dt := map[string]any{"type": "string", "format": "date-time"}
+	statusEnum := map[string]any{"type": "string", "enum": []string{"BACKLOG", "READY", "ACTIVE", "REVIEW", "DONE"}}
+	obj := func(props map[string]any, required ...string) map[string]any {
+		o := map[string]any{"type": "object", "properties": props}
+		if len(required) > 0 {
+			o["required"] = required
+		}
+		return o
+	}
+	gitMeta := obj(map[string]any{
+		"branch": str, "base": str, "last_commit": str, "pr_url": str, "merge_sha": str, "merged_at": str,
+	})
+	taskConfig := obj(map[string]any{
+		"color":    strOpt("pastel color key"),
+		"priority": map[string]any{"type": "string", "enum": []string{"", "HIGH", "MEDIUM", "LOW"}},
+		"git":      gitMeta,
+		"repos":    map[string]any{"type": "object", "additionalProperties": gitMeta},
+	})
+	schemas := map[string]any{
+		"Error": obj(map[string]any{
+			"error": obj(map[string]any{"status": map[string]any{"type": "integer"}, "code": str, "message": str}, "status", "code", "message"),
+		}, "error"),
+		"Project": obj(map[string]any{"key": str, "name": str, "repo_path": str, "repo_slug": str}, "key", "name", "repo_path"),
+		"Task": obj(map[string]any{
+			"id": str, "project": str, "title": str, "status": statusEnum,
+			"order": map[string]any{"type": "integer"}, "deps": map[string]any{"type": "array", "items": str},
+			"projects":   map[string]any{"type": "array", "items": str},
+			"created_at": dt, "updated_at": dt, "config": taskConfig,
+		}, "id", "project", "title", "status"),
+		"TaskCreate": obj(map[string]any{"title": str}, "title"),
+		"TaskPatch": obj(map[string]any{
+			"title": str, "order": map[string]any{"type": "integer"}, "deps": map[string]any{"type": "array", "items": str},
+			"projects": map[string]any{"type": "array", "items": str}, "color": str, "priority": str,
+		}),
+		"Transition":   obj(map[string]any{"status": statusEnum}, "status"),
+		"TaskMarkdown": obj(map[string]any{"body": strOpt("task.md without the HAZEL-CONFIG block"), "config": taskConfig}, "body"),
+		"Run": obj(map[string]any{
+			"name": str, "task_id": str, "mode": str, "started_at": dt, "ended_at": dt,
+			"exit_code": map[string]any{"type": "integer"}, "log_path": str, "json_summary": map[string]any{"type": "object"},
+		}, "name", "log_path"),
+		"Preflight": obj(map[string]any{
+			"mode": map[string]any{"type": "string", "enum": []string{preflightStrict, preflightWarn, preflightOff}},
+			"problems": map[string]any{"type": "array", "items": obj(map[string]any{
+				"repo": str, "code": str, "message": str, "stashable": map[string]any{"type": "boolean"},
+			}, "repo", "code", "message")},
+		}, "mode", "problems"),
+		"SearchHit": obj(map[string]any{
+			"project": str, "kind": map[string]any{"type": "string", "enum": searchKinds}, "path": str, "task_id": str,
+			"archived": map[string]any{"type": "boolean"}, "href": str, "score": map[string]any{"type": "number"},
+			"snippet": str,
+			"highlights": map[string]any{"type": "array", "description": "byte ranges [start, end) of matched terms in snippet",
+				"items": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}}},
+		}, "kind", "path", "href", "score", "snippet"),
+		"WikiPage": obj(map[string]any{
+			"path": str, "size": map[string]any{"type": "integer"}, "modified_at": dt, "content": str,
+			"meta": obj(map[string]any{
+				"type": map[string]any{"type": "string", "enum": wikiPageTypes}, "tags": map[string]any{"type": "array", "items": str},
+				"owner": str, "review_by": map[string]any{"type": "string", "format": "date"}, "source_task": str,
+			}),
+		}, "path"),
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Hazel API",
+			"version":     "1",
+			"description": "JSON API for Hazel boards, tasks, runs, wiki pages and search.",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func apiV1OperationID(rt apiV1Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.Method))
+	for _, seg := range strings.Split(rt.Pattern, "/") {
+		seg = strings.TrimSuffix(strings.Trim(seg, "{}"), "...")
+		seg = strings.NewReplacer(".", "", "-", "", "_", "").Replace(seg)
+		if seg == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(seg[:1]) + seg[1:])
+	}
+	return b.String()
+}
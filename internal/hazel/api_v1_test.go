@@ -0,0 +1,180 @@
+package hazel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchAPIV1Pattern(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		ok            bool
+		want          map[string]string
+	}{
+		{"/projects", "/projects", true, map[string]string{}},
+		{"/projects/{project}/tasks/{id}", "/projects/app/tasks/HZ-0001", true, map[string]string{"project": "app", "id": "HZ-0001"}},
+		{"/projects/{project}/tasks/{id}", "/projects/app/tasks", false, nil},
+		{"/projects/{project}/tasks", "/projects/app/tasks/HZ-0001", false, nil},
+		{"/projects/{project}/wiki/{path...}", "/projects/app/wiki/notes/a.md", true, map[string]string{"project": "app", "path": "notes/a.md"}},
+		{"/projects/{project}/wiki/{path...}", "/projects/app/wiki", false, nil},
+	}
+	for _, c := range cases {
+		got, ok := matchAPIV1Pattern(c.pattern, c.path)
+		if ok != c.ok {
+			t.Fatalf("%s vs %s: ok=%v want %v", c.pattern, c.path, ok, c.ok)
+		}
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Fatalf("%s vs %s: param %s=%q want %q", c.pattern, c.path, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestAPIV1OpenAPICoversRoutes(t *testing.T) {
+	doc := buildAPIV1OpenAPI()
+	paths := doc["paths"].(map[string]any)
+	for _, rt := range apiV1Routes {
+		p := apiV1Prefix + strings.ReplaceAll(rt.Pattern, "...}", "}")
+		item, ok := paths[p].(map[string]any)
+		if !ok {
+			t.Fatalf("missing path %s", p)
+		}
+		if _, ok := item[strings.ToLower(rt.Method)]; !ok {
+			t.Fatalf("missing %s %s", rt.Method, p)
+		}
+	}
+}
+
+func TestAPIV1PatchAndDeleteTask(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{
+		{ID: "HZ-0001", Title: "first", Status: StatusBacklog, CreatedAt: now, UpdatedAt: now},
+		{ID: "HZ-0002", Title: "second", Status: StatusBacklog, CreatedAt: now, UpdatedAt: now},
+	}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	for _, tk := range b.Tasks {
+		if err := ensureTaskScaffold(root, tk.ID); err != nil {
+			t.Fatalf("scaffold: %v", err)
+		}
+	}
+	nx := &Nexus{Projects: []TrackedProject{{Key: "app", StorageRoot: root}}}
+	call := func(method, path, body string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		apiV1(rec, httptest.NewRequest(method, apiV1Prefix+path, strings.NewReader(body)), root, nx)
+		var e apiV1Error
+		_ = json.Unmarshal(rec.Body.Bytes(), &e)
+		return rec.Code, e.Error.Code
+	}
+	snapshot := func() (string, string) {
+		board, _ := os.ReadFile(boardPath(root))
+		md, _ := readTaskMD(root, "HZ-0001")
+		return string(board), md
+	}
+
+	if code, _ := call(http.MethodPatch, "/projects/app/tasks/HZ-0001", `{"title":"renamed","priority":"high"}`); code != http.StatusOK {
+		t.Fatalf("patch = %d", code)
+	}
+	if tk, _ := findTaskInBoard(root, "HZ-0001"); tk.Title != "renamed" {
+		t.Fatalf("title = %q", tk.Title)
+	}
+	if md, _ := readTaskMD(root, "HZ-0001"); !strings.Contains(md, "HIGH") {
+		t.Fatalf("priority not written:\n%s", md)
+	}
+
+	// Rejected PATCHes write nothing, including the task.md half.
+	board, md := snapshot()
+	for _, tc := range []struct{ body, code string }{
+		{`{"title":"  "}`, "title_required"},
+		{`{"priority":"urgent"}`, "invalid_priority"},
+		{`{"color":"nope"}`, "invalid_color"},
+		{`{"bogus":1}`, "invalid_body"},
+	} {
+		if code, ecode := call(http.MethodPatch, "/projects/app/tasks/HZ-0001", tc.body); code != http.StatusBadRequest || ecode != tc.code {
+			t.Fatalf("patch %s = %d %s", tc.body, code, ecode)
+		}
+	}
+	broken := strings.Replace(board, "status: BACKLOG", "status: SOMEDAY", 1)
+	if err := os.WriteFile(boardPath(root), []byte(broken), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, ecode := call(http.MethodPatch, "/projects/app/tasks/HZ-0002", `{"priority":"low"}`); code != http.StatusBadRequest || ecode != "invalid_task" {
+		t.Fatalf("patch on invalid board = %d %s", code, ecode)
+	}
+	if md2, _ := readTaskMD(root, "HZ-0002"); strings.Contains(md2, "LOW") {
+		t.Fatalf("task.md written although the board was rejected:\n%s", md2)
+	}
+	if err := os.WriteFile(boardPath(root), []byte(board), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if b2, md2 := snapshot(); b2 != board || md2 != md {
+		t.Fatal("rejected patch changed files")
+	}
+
+	if code, _ := call(http.MethodDelete, "/projects/app/tasks/HZ-0009", ""); code != http.StatusNotFound {
+		t.Fatalf("delete unknown = %d", code)
+	}
+	if code, _ := call(http.MethodDelete, "/projects/app/tasks/HZ-0001", ""); code != http.StatusNoContent {
+		t.Fatalf("delete = %d", code)
+	}
+	if _, err := findTaskInBoard(root, "HZ-0001"); err == nil {
+		t.Fatal("task still on the board")
+	}
+	if exists(taskDir(root, "HZ-0001")) || !exists(filepath.Join(archiveDir(root), "HZ-0001", "task.md")) {
+		t.Fatal("task files not moved to the archive")
+	}
+}
+
+func TestAPIV1DeleteTaskCleansUpMergedBranch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	git := testGitRepo(t, root)
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("branch", "task/hz-0003-gone")
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.DeleteMergedBranches = true
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0003", Title: "gone", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0003"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	project := TrackedProject{Key: "app", RepoPath: root, StorageRoot: root}
+	if err := saveTaskGitMeta(project, "HZ-0003", func(g *taskGitMeta) {
+		g.Branch, g.Base, g.MergeSHA = "task/hz-0003-gone", "main", git("rev-parse", "HEAD")
+	}); err != nil {
+		t.Fatalf("save git meta: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	apiV1(rec, httptest.NewRequest(http.MethodDelete, apiV1Prefix+"/projects/app/tasks/HZ-0003", nil), root, &Nexus{Projects: []TrackedProject{project}})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete = %d %s", rec.Code, rec.Body)
+	}
+	if localBranchExists(root, "task/hz-0003-gone") {
+		t.Fatal("merged branch kept after DELETE")
+	}
+	md, _ := os.ReadFile(filepath.Join(archiveDir(root), "HZ-0003", "task.md"))
+	if g, _ := getTaskGitFromMD(string(md)); g.BranchDeletedAt == "" {
+		t.Fatalf("archived task.md without branch_deleted_at:\n%s", md)
+	}
+}
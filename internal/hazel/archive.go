@@ -15,6 +15,9 @@ type ArchiveOptions struct {
 
 type ArchiveResult struct {
 	ArchivedIDs []string
+	// DistillPendingIDs are DONE tasks kept on the board until their wiki
+	// distill proposal is accepted or declined.
+	DistillPendingIDs []string
 }
 
 func ArchiveDone(ctx context.Context, root string, opt ArchiveOptions) (*ArchiveResult, error) {
@@ -28,7 +31,7 @@ func ArchiveDone(ctx context.Context, root string, opt ArchiveOptions) (*Archive
 	}
 
 	var keep []*BoardTask
-	var archived []string
+	var archived, pending []string
 	for _, t := range b.Tasks {
 		if t.Status != StatusDone {
 			keep = append(keep, t)
@@ -38,18 +41,20 @@ func ArchiveDone(ctx context.Context, root string, opt ArchiveOptions) (*Archive
 			keep = append(keep, t)
 			continue
 		}
+		if !opt.DryRun {
+			// A distill still running for the task reads its files; let it
+			// finish, so its proposal keeps the task on the board below.
+			waitTaskDistill(root, t.ID, false)
+		}
+		if hasDistillProposal(root, t.ID) {
+			keep = append(keep, t)
+			pending = append(pending, t.ID)
+			continue
+		}
 		archived = append(archived, t.ID)
 		if !opt.DryRun {
-			src := taskDir(root, t.ID)
-			dst := filepath.Join(archiveDir(root), t.ID)
-			if exists(src) {
-				if err := ensureDir(archiveDir(root)); err != nil {
-					return nil, err
-				}
-				_ = os.RemoveAll(dst)
-				if err := os.Rename(src, dst); err != nil {
-					return nil, fmt.Errorf("archive %s: %w", t.ID, err)
-				}
+			if err := archiveTaskFiles(root, t.ID); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -59,5 +64,24 @@ func ArchiveDone(ctx context.Context, root string, opt ArchiveOptions) (*Archive
 			return nil, err
 		}
 	}
-	return &ArchiveResult{ArchivedIDs: archived}, nil
+	return &ArchiveResult{ArchivedIDs: archived, DistillPendingIDs: pending}, nil
+}
+
+// archiveTaskFiles cleans up the task's merged branch and moves its directory
+// to the archive. The caller removes the task from the board.
+func archiveTaskFiles(root, taskID string) error {
+	cleanupMergedBranch(root, taskID, false)
+	src := taskDir(root, taskID)
+	dst := filepath.Join(archiveDir(root), taskID)
+	if !exists(src) {
+		return nil
+	}
+	if err := ensureDir(archiveDir(root)); err != nil {
+		return err
+	}
+	_ = os.RemoveAll(dst)
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("archive %s: %w", taskID, err)
+	}
+	return nil
 }
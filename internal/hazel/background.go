@@ -0,0 +1,24 @@
+package hazel
+
+import "sync"
+
+// Side effects of status changes (on_* hooks, checks, merged branch cleanup,
+// distill) run in the background so they never hold up the request or tick
+// that caused them. Short-lived commands call WaitBackground before exiting so
+// that none of them is lost.
+var backgroundJobs sync.WaitGroup
+
+func goBackground(fn func()) {
+	backgroundJobs.Add(1)
+	go func() {
+		defer backgroundJobs.Done()
+		fn()
+	}()
+}
+
+// WaitBackground blocks until every background job has finished, including
+// jobs started by other background jobs. It must not be called while holding
+// the repo lock, since checks and branch cleanup wait for it.
+func WaitBackground() {
+	backgroundJobs.Wait()
+}
@@ -0,0 +1,435 @@
+package hazel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The wiki changelog is built from the commits on the base branch recorded in
+// .hazel/changelog.json. Each update only reads the commits since the recorded
+// head, so history
+// beyond the initial window is kept even after the repo's own log is trimmed
+// or shallow. Commits are grouped by release (tag), then by task and by
+// conventional-commit type.
+
+const (
+	changelogFile           = "CHANGELOG.md"
+	changelogInitialCommits = 1000
+	changelogUnreleased     = "Unreleased"
+)
+
+type changelogCommit struct {
+	SHA      string   `json:"sha"`
+	Date     string   `json:"date"` // YYYY-MM-DD
+	Author   string   `json:"author"`
+	Subject  string   `json:"subject"`
+	Merge    bool     `json:"merge,omitempty"`
+	Breaking bool     `json:"breaking,omitempty"`
+	Tasks    []string `json:"tasks,omitempty"` // from trailers, scopes and task/<id>- branch names
+}
+
+type changelogState struct {
+	Head    string            `json:"head"`
+	Tags    string            `json:"tags"`    // for-each-ref output, to notice new tags
+	Commits []changelogCommit `json:"commits"` // newest first
+}
+
+func changelogStatePath(base string) string {
+	return filepath.Join(hazelDir(base), "changelog.json")
+}
+
+var (
+	conventionalRe     = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	changelogTrailerRe = regexp.MustCompile(`(?mi)^(?:hazel-task|task|refs):\s*(HZ-\d{4,})\s*$`)
+	changelogBranchRe  = regexp.MustCompile(`(?i)\btask/(hz-\d{4,})-`)
+	changelogBreakRe   = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:`)
+)
+
+// changelogTypes orders the type sections; anything else is "Other Changes".
+var changelogTypes = []struct{ Type, Heading string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance"},
+	{"refactor", "Refactoring"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build and CI"},
+	{"ci", "Build and CI"},
+	{"chore", "Chores"},
+	{"revert", "Reverts"},
+}
+
+// updateChangelog records new commits on the project's base branch (origin's
+// copy when there is one) and rewrites wiki/CHANGELOG.md, so whatever branch
+// happens to be checked out never leaks into it. Unless force is set it does nothing when
+// neither the base branch nor the tags moved.
+func updateChangelog(p TrackedProject, force bool) error {
+	base := p.StorageRoot
+	out := filepath.Join(projectWikiDir(base), changelogFile)
+	branch := projectBaseBranch(nexusConfigFor(base), p)
+	ref := baseRef(p.RepoPath, branch)
+	head, err := runCmd(p.RepoPath, nil, "git", "rev-parse", "--verify", "-q", ref+"^{commit}")
+	if err != nil {
+		if _, gerr := runCmd(p.RepoPath, nil, "git", "rev-parse", "--git-dir"); gerr != nil {
+			body := "# Changelog\n\nUnable to read git history: " + gerr.Error() + "\n"
+			return writeFileAtomic(out, []byte(body), 0o644)
+		}
+		if _, herr := runCmd(p.RepoPath, nil, "git", "rev-parse", "--verify", "-q", "HEAD"); herr == nil {
+			body := fmt.Sprintf("# Changelog\n\nBase branch %q not found; set the git base branch in Settings or with `hazel config --git-base-branch`.\n", branch)
+			return writeFileAtomic(out, []byte(body), 0o644)
+		}
+		head = "" // a repo without commits yet
+	}
+	tags, _ := runCmd(p.RepoPath, nil, "git", "for-each-ref", "refs/tags", "--format=%(refname:short)%09%(objectname)%09%(*objectname)")
+
+	var st changelogState
+	if b, err := os.ReadFile(changelogStatePath(base)); err == nil {
+		_ = json.Unmarshal(b, &st)
+	}
+	if !force && st.Head == head && st.Tags == tags && exists(out) {
+		return nil
+	}
+	if head == "" {
+		st.Commits, st.Head = nil, ""
+	} else if st.Head != head {
+		commits, err := changelogNewCommits(p.RepoPath, st, head)
+		if err != nil {
+			return err
+		}
+		st.Commits, st.Head = commits, head
+	}
+	st.Tags = tags
+	if err := writeFileAtomic(changelogStatePath(base), mustJSONIndent(st), 0o644); err != nil {
+		return err
+	}
+	return writeFileAtomic(out, []byte(renderChangelog(base, st, changelogTagsBySHA(tags))), 0o644)
+}
+
+// changelogNewCommits returns the recorded commits plus those since st.Head.
+// When the old head is gone (rebase, force push) the latest window is read
+// again and older recorded commits are kept below it, except those git still
+// has but that are no longer in the branch's history: they were rewritten.
+func changelogNewCommits(repo string, st changelogState, head string) ([]changelogCommit, error) {
+	if st.Head != "" {
+		if _, err := runCmd(repo, nil, "git", "merge-base", "--is-ancestor", st.Head, head); err == nil {
+			fresh, err := gitChangelogCommits(repo, st.Head+".."+head, 0)
+			if err != nil {
+				return nil, err
+			}
+			return append(fresh, st.Commits...), nil
+		}
+	}
+	fresh, err := gitChangelogCommits(repo, head, changelogInitialCommits)
+	if err != nil {
+		return nil, err
+	}
+	if len(fresh) < changelogInitialCommits {
+		return fresh, nil // the whole history fit in the window
+	}
+	seen := map[string]bool{}
+	for _, c := range fresh {
+		seen[c.SHA] = true
+	}
+	history, err := runCmd(repo, nil, "git", "rev-list", head)
+	if err != nil {
+		return nil, err
+	}
+	reachable := map[string]bool{}
+	for _, sha := range strings.Fields(history) {
+		reachable[sha] = true
+	}
+	for _, c := range st.Commits {
+		if seen[c.SHA] {
+			continue
+		}
+		if !reachable[c.SHA] {
+			if _, err := runCmd(repo, nil, "git", "cat-file", "-e", c.SHA+"^{commit}"); err == nil {
+				continue // git has it, but not on the branch any more
+			}
+		}
+		fresh = append(fresh, c)
+	}
+	return fresh, nil
+}
+
+func gitChangelogCommits(repo, rev string, limit int) ([]changelogCommit, error) {
+	args := []string{"log", "--topo-order", "--date=short", "--format=%H%x1f%ad%x1f%an%x1f%P%x1f%s%x1f%b%x1e"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-n%d", limit))
+	}
+	out, err := runCmd(repo, nil, "git", append(args, rev)...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []changelogCommit
+	type taskMerge struct {
+		parents []string
+		tasks   []string
+	}
+	var merges []taskMerge
+	for _, rec := range strings.Split(out, "\x1e") {
+		f := strings.Split(strings.TrimLeft(rec, "\n"), "\x1f")
+		if len(f) < 6 || f[0] == "" {
+			continue
+		}
+		c := changelogCommit{
+			SHA:     f[0],
+			Date:    f[1],
+			Author:  f[2],
+			Merge:   len(strings.Fields(f[3])) > 1,
+			Subject: strings.TrimSpace(f[4]),
+		}
+		msg := f[4] + "\n" + f[5]
+		if m := conventionalRe.FindStringSubmatch(c.Subject); m != nil {
+			c.Breaking = m[3] == "!"
+			if id := strings.ToUpper(strings.TrimSpace(m[2])); taskIDRe.MatchString(id) {
+				c.Tasks = append(c.Tasks, id)
+			}
+		}
+		c.Breaking = c.Breaking || changelogBreakRe.MatchString(f[5])
+		for _, m := range changelogTrailerRe.FindAllStringSubmatch(msg, -1) {
+			c.Tasks = append(c.Tasks, strings.ToUpper(m[1]))
+		}
+		var branchTasks []string
+		for _, m := range changelogBranchRe.FindAllStringSubmatch(msg, -1) {
+			branchTasks = append(branchTasks, strings.ToUpper(m[1]))
+		}
+		if parents := strings.Fields(f[3]); c.Merge && len(branchTasks) > 0 {
+			merges = append(merges, taskMerge{parents: parents, tasks: branchTasks})
+		}
+		c.Tasks = dedupeStrings(append(c.Tasks, branchTasks...))
+		commits = append(commits, c)
+	}
+	// A task branch merge brings in the branch's commits (merge^1..merge^2),
+	// which belong to the task even without a trailer of their own.
+	index := map[string]int{}
+	for i, c := range commits {
+		index[c.SHA] = i
+	}
+	for _, m := range merges {
+		out, err := runCmd(repo, nil, "git", "rev-list", m.parents[0]+".."+m.parents[1])
+		if err != nil {
+			continue
+		}
+		for _, sha := range strings.Fields(out) {
+			if i, ok := index[sha]; ok {
+				commits[i].Tasks = dedupeStrings(append(commits[i].Tasks, m.tasks...))
+			}
+		}
+	}
+	return commits, nil
+}
+
+// changelogTagsBySHA maps commits to their tags; annotated tags are peeled.
+func changelogTagsBySHA(refs string) map[string][]string {
+	out := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(refs), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) < 2 || f[0] == "" {
+			continue
+		}
+		sha := f[1]
+		if len(f) > 2 && f[2] != "" {
+			sha = f[2]
+		}
+		out[sha] = append(out[sha], f[0])
+	}
+	for sha := range out {
+		sort.Sort(sort.Reverse(sort.StringSlice(out[sha])))
+	}
+	return out
+}
+
+// changelogTaskInfo indexes the project's tasks: titles from the board, and
+// the commits each task recorded (merge_sha, last_commit, commits).
+type changelogTaskInfo struct {
+	known  map[string]bool
+	titles map[string]string
+	shas   map[string]string // commit (possibly abbreviated) -> task ID
+}
+
+func loadChangelogTaskInfo(base string) changelogTaskInfo {
+	info := changelogTaskInfo{known: map[string]bool{}, titles: map[string]string{}, shas: map[string]string{}}
+	var b Board
+	if err := readYAMLFile(boardPath(base), &b); err == nil {
+		for _, t := range b.Tasks {
+			info.titles[t.ID] = t.Title
+		}
+	}
+	for _, dir := range []string{tasksDir(base), archiveDir(base)} {
+		ents, _ := os.ReadDir(dir)
+		for _, e := range ents {
+			if !e.IsDir() || !taskIDRe.MatchString(e.Name()) {
+				continue
+			}
+			info.known[e.Name()] = true
+			md, err := os.ReadFile(filepath.Join(dir, e.Name(), "task.md"))
+			if err != nil {
+				continue
+			}
+			g, ok := getTaskGitFromMD(string(md))
+			if !ok {
+				continue
+			}
+			for _, sha := range append([]string{g.MergeSHA, g.LastCommit}, g.Commits...) {
+				if sha = strings.TrimSpace(sha); len(sha) >= 7 {
+					info.shas[sha] = e.Name()
+				}
+			}
+		}
+	}
+	return info
+}
+
+func (info changelogTaskInfo) tasksFor(c changelogCommit) []string {
+	ids := append([]string(nil), c.Tasks...)
+	for sha, id := range info.shas {
+		if strings.HasPrefix(c.SHA, sha) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return dedupeStrings(ids)
+}
+
+// link renders a task ID as a [[wiki link]] when this project has the task.
+func (info changelogTaskInfo) link(id string) string {
+	if info.known[id] {
+		return "[[" + id + "]]"
+	}
+	return id
+}
+
+type changelogRelease struct {
+	Name    string
+	Date    string
+	Commits []changelogCommit
+}
+
+func renderChangelog(base string, st changelogState, tags map[string][]string) string {
+	info := loadChangelogTaskInfo(base)
+	var releases []*changelogRelease
+	cur := &changelogRelease{Name: changelogUnreleased}
+	for _, c := range st.Commits {
+		if t := tags[c.SHA]; len(t) > 0 {
+			if len(cur.Commits) > 0 || cur.Name != changelogUnreleased {
+				releases = append(releases, cur)
+			}
+			cur = &changelogRelease{Name: strings.Join(t, ", "), Date: c.Date}
+		}
+		cur.Commits = append(cur.Commits, c)
+	}
+	if len(cur.Commits) > 0 || cur.Name != changelogUnreleased {
+		releases = append(releases, cur)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\ntags: [generated]\n---\n# Changelog\n\n")
+	sb.WriteString(fmt.Sprintf("Generated by Hazel from %d recorded commits (newest first), grouped by release tag, task and commit type. Edits are overwritten.\n", len(st.Commits)))
+	if len(releases) == 0 {
+		sb.WriteString("\nNo commits found.\n")
+		return sb.String()
+	}
+	for _, r := range releases {
+		sb.WriteString("\n## " + r.Name)
+		if r.Date != "" {
+			sb.WriteString(" (" + r.Date + ")")
+		}
+		sb.WriteString("\n")
+		writeChangelogRelease(&sb, r, info)
+	}
+	return sb.String()
+}
+
+func writeChangelogRelease(sb *strings.Builder, r *changelogRelease, info changelogTaskInfo) {
+	// Tasks in order of their newest commit.
+	var taskOrder []string
+	taskCommits := map[string]int{}
+	for _, c := range r.Commits {
+		for _, id := range info.tasksFor(c) {
+			if _, ok := taskCommits[id]; !ok {
+				taskOrder = append(taskOrder, id)
+			}
+			if !c.Merge {
+				taskCommits[id]++
+			}
+		}
+	}
+	if len(taskOrder) > 0 {
+		sb.WriteString("\n### Tasks\n\n")
+		for _, id := range taskOrder {
+			line := "- " + info.link(id)
+			if title := info.titles[id]; title != "" {
+				line += " " + title
+			}
+			if n := taskCommits[id]; n == 1 {
+				line += " (1 commit)"
+			} else if n > 1 {
+				line += fmt.Sprintf(" (%d commits)", n)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	sections := map[string][]string{}
+	var breaking []string
+	for _, c := range r.Commits {
+		if c.Merge {
+			continue // merges only tie commits to tasks
+		}
+		heading, line := changelogLine(c, info)
+		if c.Breaking {
+			breaking = append(breaking, line)
+		}
+		sections[heading] = append(sections[heading], line)
+	}
+	if len(breaking) > 0 {
+		sb.WriteString("\n### Breaking Changes\n\n" + strings.Join(breaking, "\n") + "\n")
+	}
+	var headings []string
+	for _, t := range changelogTypes {
+		if len(headings) == 0 || headings[len(headings)-1] != t.Heading {
+			headings = append(headings, t.Heading)
+		}
+	}
+	for _, h := range append(headings, "Other Changes") {
+		if lines := sections[h]; len(lines) > 0 {
+			sb.WriteString("\n### " + h + "\n\n" + strings.Join(lines, "\n") + "\n")
+		}
+	}
+}
+
+// changelogLine returns the section heading and list entry for a commit.
+func changelogLine(c changelogCommit, info changelogTaskInfo) (string, string) {
+	heading, subject := "Other Changes", c.Subject
+	if m := conventionalRe.FindStringSubmatch(c.Subject); m != nil {
+		typ := strings.ToLower(m[1])
+		for _, t := range changelogTypes {
+			if t.Type == typ {
+				heading, subject = t.Heading, m[4]
+				if scope := strings.TrimSpace(m[2]); scope != "" && !taskIDRe.MatchString(strings.ToUpper(scope)) {
+					subject = "**" + scope + ":** " + subject
+				}
+				break
+			}
+		}
+	}
+	sha := c.SHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	line := fmt.Sprintf("- %s (`%s`, %s, %s)", subject, sha, c.Date, c.Author)
+	var links []string
+	for _, id := range info.tasksFor(c) {
+		links = append(links, info.link(id))
+	}
+	if len(links) > 0 {
+		line += " " + strings.Join(links, ", ")
+	}
+	return heading, line
+}
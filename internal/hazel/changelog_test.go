@@ -0,0 +1,219 @@
+package hazel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestUpdateChangelog(t *testing.T) {
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	commit := func(msg string) string {
+		t.Helper()
+		git("commit", "-q", "--allow-empty", "-m", msg)
+		return git("rev-parse", "HEAD")
+	}
+	commit("chore: initial import")
+	commit("feat(api): add orders endpoint")
+	git("tag", "-a", "v0.1.0", "-m", "first release")
+
+	git("checkout", "-q", "-b", "task/hz-0002-fix-totals")
+	commit("fix(HZ-0002): round totals\n\nHazel-Task: HZ-0002")
+	commit("fix: handle zero totals") // tied to the task by the merge below
+	git("checkout", "-q", "main")
+	git("merge", "-q", "--no-ff", "-m", "Merge branch 'task/hz-0002-fix-totals'", "task/hz-0002-fix-totals")
+	commit("refactor!: drop the v1 client")
+	recorded := commit("docs: describe releases")
+	// The checked-out branch is not the base branch and stays out.
+	git("checkout", "-q", "-b", "task/hz-0004-wip")
+	commit("feat: unmerged work")
+
+	root := t.TempDir()
+	if err := ensureDir(tasksDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	b := Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0002", Title: "Fix totals"}, {ID: "HZ-0003", Title: "Write docs"}}}
+	if err := writeYAMLFile(boardPath(root), &b); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"HZ-0002", "HZ-0003"} {
+		if err := ensureDir(filepath.Join(tasksDir(root), id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	md, err := setTaskGitInMD("# HZ-0003\n", func(g *taskGitMeta) { g.MergeSHA = recorded[:10] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tasksDir(root), "HZ-0003", "task.md"), []byte(md), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	project := TrackedProject{Key: "app", Name: "App", RepoPath: repo, StorageRoot: root}
+	if err := ensureDir(projectWikiDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateChangelog(project, false); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	read := func() string {
+		b, _ := os.ReadFile(filepath.Join(projectWikiDir(root), changelogFile))
+		return string(b)
+	}
+	page := read()
+	for _, want := range []string{
+		"## Unreleased\n\n### Tasks\n\n- [[HZ-0003]] Write docs (1 commit)\n- [[HZ-0002]] Fix totals (2 commits)\n",
+		"### Breaking Changes\n\n- drop the v1 client (`",
+		"### Bug Fixes\n\n- handle zero totals (`",
+		"- round totals (`",
+		"### Documentation\n\n- describe releases (`" + recorded[:7] + "`",
+		"## v0.1.0 (",
+		"### Features\n\n- **api:** add orders endpoint (`",
+		"### Chores\n\n- initial import (`",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("changelog missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "Merge branch") || strings.Contains(page, "unmerged work") {
+		t.Fatalf("merge or unmerged commit listed:\n%s", page)
+	}
+	if !regexp.MustCompile("- handle zero totals \\(`[^\n]*\\[\\[HZ-0002\\]\\]").MatchString(page) {
+		t.Fatalf("task branch commit not attributed to its task:\n%s", page)
+	}
+	if strings.Index(page, "## Unreleased") > strings.Index(page, "## v0.1.0") {
+		t.Fatalf("releases out of order:\n%s", page)
+	}
+
+	// Later updates only read new commits, so recorded history survives even
+	// when git no longer has it (here: faked by an entry git never saw).
+	var st changelogState
+	raw, _ := os.ReadFile(changelogStatePath(root))
+	if err := json.Unmarshal(raw, &st); err != nil {
+		t.Fatal(err)
+	}
+	st.Commits = append(st.Commits, changelogCommit{SHA: strings.Repeat("f", 40), Date: "2020-01-01", Author: "Old", Subject: "feat: prehistoric"})
+	if err := writeFileAtomic(changelogStatePath(root), mustJSONIndent(st), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("checkout", "-q", "main")
+	commit("test: cover rounding\n\nRefs: HZ-0009")
+	if err := updateChangelog(project, false); err != nil {
+		t.Fatal(err)
+	}
+	page = read()
+	for _, want := range []string{"prehistoric (`fffffff`", "### Tests\n\n- cover rounding (`", "- HZ-0009 (1 commit)"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("incremental changelog missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestChangelogDropsRewrittenCommits(t *testing.T) {
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	// Fill the first run's window in one fast-import; each commit follows the last.
+	var stream strings.Builder
+	for i := 1; i <= changelogInitialCommits; i++ {
+		msg := fmt.Sprintf("chore: step %d", i)
+		fmt.Fprintf(&stream, "commit refs/heads/main\ncommitter t <t@example.com> %d +0000\ndata %d\n%s\n", 1700000000+i, len(msg), msg)
+	}
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir, cmd.Stdin = repo, strings.NewReader(stream.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("fast-import: %v: %s", err, out)
+	}
+	git("reset", "-q", "--hard", "main")
+	commit := func(msg string) {
+		t.Helper()
+		git("commit", "-q", "--allow-empty", "-m", msg)
+	}
+
+	root := t.TempDir()
+	if err := ensureDir(projectWikiDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	project := TrackedProject{Key: "app", Name: "App", RepoPath: repo, StorageRoot: root}
+	update := func() {
+		t.Helper()
+		if err := updateChangelog(project, false); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	update()
+	commit("feat: doomed")
+	update()
+
+	// Rewrite the tip, which pushes step 1 out of the window; also keep an
+	// entry git never saw, as trimmed history would.
+	git("reset", "-q", "--hard", "HEAD~1")
+	commit("feat: replacement")
+	var st changelogState
+	raw, _ := os.ReadFile(changelogStatePath(root))
+	if err := json.Unmarshal(raw, &st); err != nil {
+		t.Fatal(err)
+	}
+	st.Commits = append(st.Commits, changelogCommit{SHA: strings.Repeat("f", 40), Date: "2020-01-01", Author: "Old", Subject: "feat: prehistoric"})
+	if err := writeFileAtomic(changelogStatePath(root), mustJSONIndent(st), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	update()
+	b, _ := os.ReadFile(filepath.Join(projectWikiDir(root), changelogFile))
+	page := string(b)
+	for _, want := range []string{"- replacement (`", "- step 1 (`", "- prehistoric (`"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("changelog missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "doomed") {
+		t.Fatalf("rewritten commit kept:\n%s", page)
+	}
+}
+
+func TestChangelogFollowsNexusBaseBranch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.ProjectsRootDir, cfg.GitBaseBranch = "projects", "trunk"
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(root, "projects", "app")
+	if err := ensureDir(repo); err != nil {
+		t.Fatal(err)
+	}
+	git := testGitRepo(t, repo)
+	git("checkout", "-q", "-b", "trunk")
+	git("commit", "-q", "--allow-empty", "-m", "feat: on trunk")
+
+	nx, err := LoadNexus(root)
+	if err != nil || len(nx.Projects) != 1 {
+		t.Fatalf("load nexus: %+v, %v", nx, err)
+	}
+	p := nx.Projects[0]
+	if got := projectBaseBranch(nexusConfigFor(p.StorageRoot), p); got != "trunk" {
+		t.Fatalf("base = %q, want the nexus trunk", got)
+	}
+	b, _ := os.ReadFile(filepath.Join(projectWikiDir(p.StorageRoot), changelogFile))
+	if !strings.Contains(string(b), "- on trunk (`") {
+		t.Fatalf("changelog:\n%s", b)
+	}
+
+	// A base set in the project's own config wins.
+	pcfg, _ := loadConfigOrDefault(p.StorageRoot)
+	pcfg.GitBaseBranch = "release"
+	if err := writeYAMLFile(configPath(p.StorageRoot), &pcfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := projectBaseBranch(cfg, p); got != "release" {
+		t.Fatalf("base = %q, want the project override", got)
+	}
+}
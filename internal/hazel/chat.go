@@ -108,59 +108,15 @@ func runChatPrompt(ctx context.Context, root string, taskID string, prompt strin
 }
 
 func buildChatPromptPacket(root string, taskID string, prompt string, now time.Time) string {
-	repoRoot := resolveRepoRoot(root)
-	var taskContext string
+	var task *BoardTask
 	if strings.TrimSpace(taskID) != "" {
-		var b Board
-		if err := readYAMLFile(boardPath(root), &b); err == nil {
-			for _, t := range b.Tasks {
-				if t.ID == taskID {
-					body, err := buildPromptPacket(root, t, "chat", now)
-					if err == nil {
-						taskContext = body
-					}
-					break
-				}
-			}
-		}
-	}
-
-	wikiReadme, _ := os.ReadFile(filepath.Join(root, "wiki", "README.md"))
-	wikiFeatures, _ := os.ReadFile(filepath.Join(root, "wiki", "FEATURES_AND_USAGE.md"))
-	wikiChangelog, _ := os.ReadFile(filepath.Join(root, "wiki", "CHANGELOG.md"))
-	agentsMD, _ := os.ReadFile(filepath.Join(repoRoot, "AGENTS.md"))
-
-	var sb strings.Builder
-	sb.WriteString("# Hazel Chat Packet\n\n")
-	sb.WriteString("- Generated: `" + now.Format(time.RFC3339) + "`\n")
-	sb.WriteString("- State root: `" + root + "`\n")
-	sb.WriteString("- Repo root: `" + repoRoot + "`\n")
-	if taskID != "" {
-		sb.WriteString("- Focus task: `" + taskID + "`\n")
-	}
-	sb.WriteString("\n## User Prompt\n\n")
-	sb.WriteString("```text\n" + clipped(prompt, 8000) + "\n```\n")
-
-	if strings.TrimSpace(taskContext) != "" {
-		sb.WriteString("\n## Task Context\n\n")
-		sb.WriteString(taskContext)
-	}
-	if strings.TrimSpace(string(agentsMD)) != "" {
-		sb.WriteString("\n## AGENTS.md (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(agentsMD), 3000) + "\n```\n")
-	}
-	if strings.TrimSpace(string(wikiReadme)) != "" {
-		sb.WriteString("\n## Wiki README (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(wikiReadme), 2200) + "\n```\n")
+		task, _ = findTaskInBoard(root, taskID)
 	}
-	if strings.TrimSpace(string(wikiFeatures)) != "" {
-		sb.WriteString("\n## Wiki Features/Usage (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(wikiFeatures), 2200) + "\n```\n")
-	}
-	if strings.TrimSpace(string(wikiChangelog)) != "" {
-		sb.WriteString("\n## Wiki Changelog (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(wikiChangelog), 1800) + "\n```\n")
+	d := buildPacketTemplateData(root, task, "chat", prompt, now)
+	body, err := renderPacket(root, "chat", d)
+	if err != nil {
+		// A broken project template must not block chat; doctor reports it.
+		body, _ = executePacketTemplate("chat", packetTemplates["chat"], d)
 	}
-
-	return sb.String()
+	return body
 }
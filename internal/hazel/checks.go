@@ -0,0 +1,233 @@
+package hazel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Checks are the project's local CI commands (project config `checks:`). They
+// run in the task branch when a task moves to REVIEW or on demand from the
+// task page; with require_checks_for_pr, Open PR waits until they pass.
+
+const checksFile = "checks.json"
+
+// Check and report states.
+const (
+	checkPass    = "pass"
+	checkFail    = "fail"
+	checkRunning = "running"
+
+	checksPassing = "passing"
+	checksFailing = "failing"
+)
+
+// CheckConfig is one entry of `checks:`. A plain string is shorthand for {run: ...}.
+type CheckConfig struct {
+	Name string `yaml:"name,omitempty"`
+	Run  string `yaml:"run"`
+}
+
+func (c *CheckConfig) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		c.Run = n.Value
+		return nil
+	}
+	type plain CheckConfig
+	return n.Decode((*plain)(c))
+}
+
+func (c CheckConfig) label() string {
+	if s := strings.TrimSpace(c.Name); s != "" {
+		return s
+	}
+	return strings.TrimSpace(c.Run)
+}
+
+type checkResult struct {
+	Name       string `json:"name"`
+	Run        string `json:"run"`
+	Status     string `json:"status"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	LogPath    string `json:"log_path,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// checksReport is written to tasks/<id>/checks.json (latest) and to
+// runs/checks/<stamp>_<id>.json (history).
+type checksReport struct {
+	TaskID    string        `json:"task_id"`
+	Branch    string        `json:"branch,omitempty"`
+	Commit    string        `json:"commit,omitempty"`
+	Trigger   string        `json:"trigger"`
+	Status    string        `json:"status"` // running, passing or failing
+	StartedAt time.Time     `json:"started_at"`
+	EndedAt   time.Time     `json:"ended_at,omitzero"`
+	Results   []checkResult `json:"results"`
+}
+
+func checksPath(root, taskID string) string {
+	return taskFile(root, taskID, checksFile)
+}
+
+func readChecksReport(root, taskID string) (*checksReport, error) {
+	b, err := os.ReadFile(checksPath(root, taskID))
+	if err != nil {
+		return nil, err
+	}
+	var rep checksReport
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", checksFile, err)
+	}
+	return &rep, nil
+}
+
+// checksStatus is the badge shown on board cards: passing, failing, running or "".
+func checksStatus(root, taskID string) string {
+	rep, err := readChecksReport(root, taskID)
+	if err != nil {
+		return ""
+	}
+	return rep.Status
+}
+
+// checksWorkDir returns where the checks should run: the repo itself when the
+// task branch is checked out (so uncommitted work is tested), otherwise a
+// temporary worktree of the branch. cleanup removes that worktree.
+func checksWorkDir(repo, branch string) (dir string, commit string, cleanup func(), err error) {
+	cleanup = func() {}
+	if branch == "" || !localBranchExists(repo, branch) {
+		commit, _ = runCmd(repo, nil, "git", "rev-parse", "HEAD")
+		return repo, commit, cleanup, nil
+	}
+	commit, _ = runCmd(repo, nil, "git", "rev-parse", branch)
+	if cur, _ := runCmd(repo, nil, "git", "rev-parse", "--abbrev-ref", "HEAD"); cur == branch {
+		return repo, commit, cleanup, nil
+	}
+	tmp, err := os.MkdirTemp("", "hazel-checks-")
+	if err != nil {
+		return "", "", cleanup, err
+	}
+	wt := filepath.Join(tmp, "wt")
+	if _, err := runCmd(repo, nil, "git", "worktree", "add", "-q", "--detach", wt, branch); err != nil {
+		_ = os.RemoveAll(tmp)
+		return "", "", cleanup, err
+	}
+	return wt, commit, func() {
+		_, _ = runCmd(repo, nil, "git", "worktree", "remove", "--force", wt)
+		_ = os.RemoveAll(tmp)
+	}, nil
+}
+
+// runTaskChecks runs every configured check for the task, streaming each to
+// its own log under runs/checks/. It returns nil when no checks are configured.
+func runTaskChecks(ctx context.Context, stateRoot, taskID, trigger string) (*checksReport, error) {
+	cfg, err := loadConfigOrDefault(stateRoot)
+	if err != nil || len(cfg.Checks) == 0 {
+		return nil, err
+	}
+	md, err := readTaskMD(stateRoot, taskID)
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := getTaskGitFromMD(md)
+	project := trackedProjectForRoot(stateRoot)
+	rep := &checksReport{TaskID: taskID, Branch: meta.Branch, Trigger: trigger, Status: checkRunning, StartedAt: time.Now(), Results: []checkResult{}}
+	for _, c := range cfg.Checks {
+		rep.Results = append(rep.Results, checkResult{Name: c.label(), Run: c.Run, Status: checkRunning})
+	}
+	_ = writeFileAtomic(checksPath(stateRoot, taskID), mustJSONIndent(rep), 0o644)
+
+	logDir := filepath.Join(runsDir(stateRoot), "checks")
+	if err := ensureDir(logDir); err != nil {
+		return nil, err
+	}
+	stamp := rep.StartedAt.Format("20060102T150405")
+	dir, commit, cleanup, err := checksWorkDir(project.RepoPath, strings.TrimSpace(meta.Branch))
+	if err != nil {
+		rep.Status, rep.EndedAt = checksFailing, time.Now()
+		for i := range rep.Results {
+			rep.Results[i].Status, rep.Results[i].Error = checkFail, err.Error()
+		}
+		_ = writeFileAtomic(checksPath(stateRoot, taskID), mustJSONIndent(rep), 0o644)
+		return rep, err
+	}
+	defer cleanup()
+	rep.Commit = commit
+	rep.Status = checksPassing
+	for i, c := range cfg.Checks {
+		res := &rep.Results[i]
+		res.LogPath = filepath.Join(logDir, fmt.Sprintf("%s_%s_%d.log", stamp, taskID, i+1))
+		env := append(agentEnv(stateRoot, taskID, "checks"), "HAZEL_CHECK_NAME="+res.Name)
+		start := time.Now()
+		exit, rerr := runShellLogged(ctx, dir, env, c.Run, res.LogPath)
+		res.DurationMS = time.Since(start).Milliseconds()
+		switch {
+		case rerr != nil:
+			res.Status, res.Error = checkFail, rerr.Error()
+		case exit != 0:
+			res.Status, res.ExitCode = checkFail, &exit
+		default:
+			res.Status, res.ExitCode = checkPass, &exit
+		}
+		if res.Status == checkFail {
+			rep.Status = checksFailing
+		}
+		_ = writeFileAtomic(checksPath(stateRoot, taskID), mustJSONIndent(rep), 0o644)
+	}
+	rep.EndedAt = time.Now()
+	if err := writeFileAtomic(checksPath(stateRoot, taskID), mustJSONIndent(rep), 0o644); err != nil {
+		return rep, err
+	}
+	_ = writeFileAtomic(filepath.Join(logDir, stamp+"_"+taskID+".json"), mustJSONIndent(rep), 0o644)
+	return rep, nil
+}
+
+// startTaskChecks runs the checks in the background under the repo lock; a
+// caller holding the lock (a run tick) gets them once it lets go.
+func startTaskChecks(stateRoot, taskID, trigger string) bool {
+	cfg, err := loadConfigOrDefault(stateRoot)
+	if err != nil || len(cfg.Checks) == 0 {
+		return false
+	}
+	goBackground(func() {
+		_ = withRepoLock(stateRoot, func() error {
+			_, err := runTaskChecks(context.Background(), stateRoot, taskID, trigger)
+			return err
+		})
+	})
+	return true
+}
+
+// checksPRBlock refuses Open PR under require_checks_for_pr unless the last
+// checks passed on the branch's current commit.
+func checksPRBlock(stateRoot, taskID string) error {
+	cfg, err := loadConfigOrDefault(stateRoot)
+	if err != nil || !cfg.RequireChecksForPR || len(cfg.Checks) == 0 {
+		return nil
+	}
+	rep, err := readChecksReport(stateRoot, taskID)
+	if err != nil {
+		return &transitionError{msg: "checks have not run yet; use Run Checks on the task page"}
+	}
+	switch rep.Status {
+	case checkRunning:
+		return &transitionError{msg: "checks are still running"}
+	case checksFailing:
+		return &transitionError{msg: "checks are failing; fix them and use Run Checks"}
+	}
+	if rep.Branch != "" && rep.Commit != "" {
+		repo := resolveRepoRoot(stateRoot)
+		if tip, err := runCmd(repo, nil, "git", "rev-parse", rep.Branch); err == nil && tip != rep.Commit {
+			return &transitionError{msg: "checks passed on an older commit; use Run Checks again"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunTaskChecksInBranchWorktree(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0040", Title: "checked", Status: StatusActive, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0040"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	cfgYAML := "version: 1\nchecks:\n  - test -f feature.txt\n  - name: fails\n    run: exit 3\nrequire_checks_for_pr: true\n"
+	if err := os.WriteFile(configPath(root), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfigOrDefault(root)
+	if err != nil || len(cfg.Checks) != 2 || cfg.Checks[0].Run != "test -f feature.txt" || cfg.Checks[1].Name != "fails" {
+		t.Fatalf("checks config = %+v, %v", cfg.Checks, err)
+	}
+
+	// The state root doubles as the repo; the feature file only exists on the task branch.
+	git := testGitRepo(t, root)
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("checkout", "-q", "-b", "task/hz-0040-checked")
+	if err := os.WriteFile(filepath.Join(root, "feature.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "feature.txt")
+	git("commit", "-q", "-m", "feature")
+	git("checkout", "-q", "main")
+	project := TrackedProject{Key: "app", RepoPath: root, StorageRoot: root}
+	if err := saveTaskGitMeta(project, "HZ-0040", func(g *taskGitMeta) {
+		g.Branch, g.Base = "task/hz-0040-checked", "main"
+	}); err != nil {
+		t.Fatalf("save git meta: %v", err)
+	}
+
+	if err := checksPRBlock(root, "HZ-0040"); err == nil {
+		t.Fatalf("PR allowed before checks ran")
+	}
+	rep, err := runTaskChecks(context.Background(), root, "HZ-0040", "manual")
+	if err != nil {
+		t.Fatalf("run checks: %v", err)
+	}
+	if rep.Status != checksFailing || rep.Results[0].Status != checkPass || rep.Results[1].Status != checkFail {
+		t.Fatalf("report = %+v", rep)
+	}
+	if rep.Results[1].ExitCode == nil || *rep.Results[1].ExitCode != 3 {
+		t.Fatalf("exit code = %v", rep.Results[1].ExitCode)
+	}
+	if _, err := os.Stat(rep.Results[0].LogPath); err != nil {
+		t.Fatalf("check log missing: %v", err)
+	}
+	if err := checksPRBlock(root, "HZ-0040"); !isTransitionError(err) {
+		t.Fatalf("PR allowed with failing checks: %v", err)
+	}
+	if got := checksStatus(root, "HZ-0040"); got != checksFailing {
+		t.Fatalf("badge = %q", got)
+	}
+	if out, _ := runCmd(root, nil, "git", "worktree", "list"); strings.Contains(out, "\n") {
+		t.Fatalf("worktree left behind: %s", out)
+	}
+}
+
+func TestRunTickRecordsReviewChecks(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0041", Title: "ran", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0041"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.AgentCommand = "true"
+	cfg.Preflight = preflightOff
+	cfg.Checks = []CheckConfig{{Run: "true"}}
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := RunTick(context.Background(), root, RunOptions{}); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	// The checks wait for the tick's repo lock, so they only finish here.
+	WaitBackground()
+	if task, _ := findTaskInBoard(root, "HZ-0041"); task.Status != StatusReview {
+		t.Fatalf("status = %s, want REVIEW", task.Status)
+	}
+	rep, err := readChecksReport(root, "HZ-0041")
+	if err != nil {
+		t.Fatalf("no checks recorded: %v", err)
+	}
+	if rep.Trigger != "review" || rep.Status != checksPassing || len(rep.Results) != 1 {
+		t.Fatalf("report = %+v", rep)
+	}
+}
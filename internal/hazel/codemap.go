@@ -0,0 +1,491 @@
+package hazel
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// codeMapFile is the generated structural overview of the tracked repo. It is
+// rewritten on every sync-wiki and depends only on the checked-out files, so
+// the same tree always produces the same page.
+const codeMapFile = "CODEMAP.md"
+
+const (
+	codeMapTreeDepth    = 2
+	codeMapLargest      = 10
+	codeMapMaxIdents    = 40
+	codeMapMaxReadBytes = 1 << 20 // files above this are sized but not read
+)
+
+var codeMapLanguages = map[string]string{
+	".go": "Go", ".rs": "Rust", ".py": "Python", ".rb": "Ruby", ".java": "Java", ".kt": "Kotlin",
+	".js": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript", ".jsx": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".swift": "Swift", ".c": "C", ".h": "C",
+	".cc": "C++", ".cpp": "C++", ".hpp": "C++", ".cs": "C#", ".php": "PHP", ".scala": "Scala",
+	".ex": "Elixir", ".exs": "Elixir", ".erl": "Erlang", ".hs": "Haskell", ".lua": "Lua",
+	".sh": "Shell", ".bash": "Shell", ".zsh": "Shell", ".sql": "SQL", ".html": "HTML",
+	".css": "CSS", ".scss": "CSS", ".vue": "Vue", ".svelte": "Svelte", ".md": "Markdown",
+	".yaml": "YAML", ".yml": "YAML", ".json": "JSON", ".toml": "TOML", ".proto": "Protobuf",
+}
+
+// codeMapBuildFiles are recognized by base name anywhere in the tree.
+var codeMapBuildFiles = map[string]bool{
+	"go.mod": true, "go.work": true, "Makefile": true, "GNUmakefile": true, "Justfile": true, "justfile": true,
+	"Dockerfile": true, "docker-compose.yml": true, "docker-compose.yaml": true, "compose.yaml": true,
+	"package.json": true, "Cargo.toml": true, "pyproject.toml": true, "setup.py": true, "requirements.txt": true,
+	"Gemfile": true, "pom.xml": true, "build.gradle": true, "build.gradle.kts": true, "CMakeLists.txt": true,
+	"BUILD": true, "BUILD.bazel": true, "WORKSPACE": true, "flake.nix": true, "Taskfile.yml": true,
+	".goreleaser.yml": true, ".goreleaser.yaml": true,
+}
+
+var codeMapEntryNames = map[string]bool{
+	"main.py": true, "__main__.py": true, "manage.py": true, "app.py": true, "main.rs": true,
+	"index.js": true, "index.ts": true, "main.js": true, "main.ts": true, "server.js": true, "server.ts": true,
+	"Main.java": true, "main.c": true, "main.cpp": true, "main.swift": true,
+}
+
+type codeMapFileInfo struct {
+	Path   string // slash-separated, relative to the repo
+	Size   int64
+	Lines  int
+	Binary bool
+}
+
+type codeMapGoPackage struct {
+	Dir        string
+	ImportPath string
+	Name       string
+	Doc        string
+	Files      int
+	Idents     []string
+}
+
+func buildCodeMap(repo string) (string, error) {
+	paths, err := listRepoFiles(repo)
+	if err != nil {
+		return "", err
+	}
+	files := make([]codeMapFileInfo, 0, len(paths))
+	for _, p := range paths {
+		files = append(files, statCodeMapFile(repo, p))
+	}
+	head, _ := runCmd(repo, nil, "git", "rev-parse", "--short", "HEAD")
+
+	var sb strings.Builder
+	sb.WriteString("---\ntype: architecture\ntags: [generated]\n---\n# Code Map\n\n")
+	sb.WriteString("Generated by `hazel sync-wiki` from the repository")
+	if head != "" {
+		sb.WriteString(" at `" + head + "`")
+	}
+	sb.WriteString(". Edits are overwritten on the next sync; keep notes on other pages.\n\n")
+	var totalLines int
+	var totalBytes int64
+	for _, f := range files {
+		totalLines += f.Lines
+		totalBytes += f.Size
+	}
+	sb.WriteString(fmt.Sprintf("- Files: %d (.gitignore respected)\n- Lines: %d\n- Size: %s\n", len(files), totalLines, codeMapSize(totalBytes)))
+
+	writeCodeMapLanguages(&sb, files)
+	writeCodeMapTree(&sb, files)
+	writeCodeMapEntryPoints(&sb, repo, files)
+	writeCodeMapBuildFiles(&sb, repo, files)
+	writeCodeMapLargest(&sb, files)
+	writeCodeMapGoPackages(&sb, repo, files)
+	return sb.String(), nil
+}
+
+func statCodeMapFile(repo, p string) codeMapFileInfo {
+	fi := codeMapFileInfo{Path: p}
+	full := filepath.Join(repo, filepath.FromSlash(p))
+	st, err := os.Stat(full)
+	if err != nil {
+		return fi
+	}
+	fi.Size = st.Size()
+	if fi.Size > codeMapMaxReadBytes {
+		fi.Binary = true
+		return fi
+	}
+	b, err := os.ReadFile(full)
+	if err != nil {
+		return fi
+	}
+	sniff := b
+	if len(sniff) > 8000 {
+		sniff = sniff[:8000]
+	}
+	if bytes.IndexByte(sniff, 0) >= 0 {
+		fi.Binary = true
+		return fi
+	}
+	fi.Lines = bytes.Count(b, []byte("\n"))
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		fi.Lines++
+	}
+	return fi
+}
+
+func codeMapSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func writeCodeMapLanguages(sb *strings.Builder, files []codeMapFileInfo) {
+	type lang struct {
+		Name         string
+		Files, Lines int
+	}
+	byName := map[string]*lang{}
+	for _, f := range files {
+		name, ok := codeMapLanguages[strings.ToLower(path.Ext(f.Path))]
+		if !ok || f.Binary {
+			continue
+		}
+		l := byName[name]
+		if l == nil {
+			l = &lang{Name: name}
+			byName[name] = l
+		}
+		l.Files++
+		l.Lines += f.Lines
+	}
+	if len(byName) == 0 {
+		return
+	}
+	langs := make([]*lang, 0, len(byName))
+	for _, l := range byName {
+		langs = append(langs, l)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].Lines != langs[j].Lines {
+			return langs[i].Lines > langs[j].Lines
+		}
+		return langs[i].Name < langs[j].Name
+	})
+	sb.WriteString("\n## Languages\n\n| Language | Files | Lines |\n| --- | ---: | ---: |\n")
+	for _, l := range langs {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d |\n", l.Name, l.Files, l.Lines))
+	}
+}
+
+// writeCodeMapTree lists directories down to codeMapTreeDepth with the number
+// of files below each.
+func writeCodeMapTree(sb *strings.Builder, files []codeMapFileInfo) {
+	counts := map[string]int{}
+	rootFiles := 0
+	for _, f := range files {
+		parts := strings.Split(path.Dir(f.Path), "/")
+		if parts[0] == "." {
+			rootFiles++
+			continue
+		}
+		for i := 1; i <= len(parts) && i <= codeMapTreeDepth; i++ {
+			counts[strings.Join(parts[:i], "/")]++
+		}
+	}
+	dirs := make([]string, 0, len(counts))
+	for d := range counts {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	sb.WriteString("\n## Directory Structure\n\n")
+	sb.WriteString(fmt.Sprintf("- `./` (%d files at the top level)\n", rootFiles))
+	for _, d := range dirs {
+		depth := strings.Count(d, "/")
+		sb.WriteString(fmt.Sprintf("%s- `%s/` (%d files)\n", strings.Repeat("  ", depth+1), d, counts[d]))
+	}
+}
+
+func writeCodeMapEntryPoints(sb *strings.Builder, repo string, files []codeMapFileInfo) {
+	var entries []string
+	mainDirs := map[string]bool{}
+	for _, f := range files {
+		base := path.Base(f.Path)
+		if strings.HasSuffix(base, ".go") && !strings.HasSuffix(base, "_test.go") {
+			dir := path.Dir(f.Path)
+			if mainDirs[dir] {
+				continue
+			}
+			if name, _ := codeMapGoPackageName(repo, f.Path); name == "main" {
+				mainDirs[dir] = true
+				entries = append(entries, fmt.Sprintf("- `%s/` (Go `package main`)", dir))
+			}
+			continue
+		}
+		if codeMapEntryNames[base] {
+			entries = append(entries, fmt.Sprintf("- `%s`", f.Path))
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+	sort.Strings(entries)
+	sb.WriteString("\n## Entry Points\n\n" + strings.Join(entries, "\n") + "\n")
+}
+
+func writeCodeMapBuildFiles(sb *strings.Builder, repo string, files []codeMapFileInfo) {
+	var lines []string
+	for _, f := range files {
+		base := path.Base(f.Path)
+		isWorkflow := strings.HasPrefix(f.Path, ".github/workflows/") || f.Path == ".gitlab-ci.yml"
+		if !codeMapBuildFiles[base] && !isWorkflow {
+			continue
+		}
+		line := "- `" + f.Path + "`"
+		if base == "go.mod" {
+			if mod, goVer := codeMapGoMod(repo, f.Path); mod != "" {
+				line += ": module `" + mod + "`"
+				if goVer != "" {
+					line += ", go " + goVer
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	sb.WriteString("\n## Build Files\n\n" + strings.Join(lines, "\n") + "\n")
+}
+
+func writeCodeMapLargest(sb *strings.Builder, files []codeMapFileInfo) {
+	sorted := append([]codeMapFileInfo(nil), files...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Size > sorted[j].Size })
+	if len(sorted) > codeMapLargest {
+		sorted = sorted[:codeMapLargest]
+	}
+	if len(sorted) == 0 {
+		return
+	}
+	sb.WriteString("\n## Largest Files\n\n| File | Size | Lines |\n| --- | ---: | ---: |\n")
+	for _, f := range sorted {
+		lines := fmt.Sprint(f.Lines)
+		if f.Binary {
+			lines = "binary"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", f.Path, codeMapSize(f.Size), lines))
+	}
+}
+
+// codeMapGoMod returns the module path and go version of a go.mod file.
+func codeMapGoMod(repo, rel string) (string, string) {
+	b, err := os.ReadFile(filepath.Join(repo, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", ""
+	}
+	var mod, goVer string
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			mod = strings.Trim(fields[1], `"`)
+		case "go":
+			goVer = fields[1]
+		}
+	}
+	return mod, goVer
+}
+
+func codeMapGoPackageName(repo, rel string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(repo, filepath.FromSlash(rel)), nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	return f.Name.Name, nil
+}
+
+func writeCodeMapGoPackages(sb *strings.Builder, repo string, files []codeMapFileInfo) {
+	// Module roots, longest first, so nested modules win.
+	var mods []string
+	modPath := map[string]string{}
+	for _, f := range files {
+		if path.Base(f.Path) != "go.mod" {
+			continue
+		}
+		if mod, _ := codeMapGoMod(repo, f.Path); mod != "" {
+			dir := path.Dir(f.Path)
+			mods = append(mods, dir)
+			modPath[dir] = mod
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool { return len(mods[i]) > len(mods[j]) })
+
+	pkgs := map[string]*codeMapGoPackage{}
+	fset := token.NewFileSet()
+	for _, f := range files {
+		if !strings.HasSuffix(f.Path, ".go") || strings.HasSuffix(f.Path, "_test.go") || f.Binary {
+			continue
+		}
+		if codeMapSkipGoDir(f.Path) {
+			continue
+		}
+		af, err := parser.ParseFile(fset, filepath.Join(repo, filepath.FromSlash(f.Path)), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(f.Path)
+		p := pkgs[dir]
+		if p == nil {
+			p = &codeMapGoPackage{Dir: dir, Name: af.Name.Name, ImportPath: codeMapImportPath(dir, mods, modPath)}
+			pkgs[dir] = p
+		}
+		p.Files++
+		if p.Doc == "" && af.Doc != nil {
+			p.Doc = codeMapSynopsis(af.Doc.Text())
+		}
+		p.Idents = append(p.Idents, codeMapExported(af)...)
+	}
+	if len(pkgs) == 0 {
+		return
+	}
+	dirs := make([]string, 0, len(pkgs))
+	for d := range pkgs {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	sb.WriteString("\n## Go Packages\n")
+	for _, d := range dirs {
+		p := pkgs[d]
+		name := p.ImportPath
+		if name == "" {
+			name = p.Dir
+		}
+		sb.WriteString(fmt.Sprintf("\n### `%s`\n\n", name))
+		sb.WriteString(fmt.Sprintf("- Package `%s` in `%s/`, %d files\n", p.Name, p.Dir, p.Files))
+		if p.Doc != "" {
+			sb.WriteString("- " + p.Doc + "\n")
+		}
+		idents := dedupeStrings(sortedCopy(p.Idents))
+		if len(idents) == 0 {
+			sb.WriteString("- Exported: none\n")
+			continue
+		}
+		more := ""
+		if len(idents) > codeMapMaxIdents {
+			more = fmt.Sprintf(" and %d more", len(idents)-codeMapMaxIdents)
+			idents = idents[:codeMapMaxIdents]
+		}
+		sb.WriteString("- Exported: `" + strings.Join(idents, "`, `") + "`" + more + "\n")
+	}
+}
+
+func sortedCopy(in []string) []string {
+	out := append([]string(nil), in...)
+	sort.Strings(out)
+	return out
+}
+
+// codeMapSkipGoDir skips vendored and testdata packages, like the go tool does.
+func codeMapSkipGoDir(rel string) bool {
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "vendor" || part == "testdata" || strings.HasPrefix(part, "_") || (strings.HasPrefix(part, ".") && part != ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func codeMapImportPath(dir string, mods []string, modPath map[string]string) string {
+	for _, m := range mods {
+		if m == "." {
+			if dir == "." {
+				return modPath[m]
+			}
+			return modPath[m] + "/" + dir
+		}
+		if dir == m {
+			return modPath[m]
+		}
+		if strings.HasPrefix(dir, m+"/") {
+			return modPath[m] + "/" + strings.TrimPrefix(dir, m+"/")
+		}
+	}
+	return ""
+}
+
+// codeMapSynopsis is the first sentence of a package comment.
+func codeMapSynopsis(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return clipped(doc, 200)
+}
+
+// codeMapExported returns the exported top-level names of a file; methods on
+// exported types are listed as Type.Method.
+func codeMapExported(f *ast.File) []string {
+	var out []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				out = append(out, d.Name.Name)
+				continue
+			}
+			if recv := codeMapRecvName(d.Recv.List[0].Type); recv != "" && ast.IsExported(recv) {
+				out = append(out, recv+"."+d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						out = append(out, s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							out = append(out, n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func codeMapRecvName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return codeMapRecvName(t.X)
+	case *ast.IndexExpr:
+		return codeMapRecvName(t.X)
+	case *ast.IndexListExpr:
+		return codeMapRecvName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// writeCodeMap regenerates wiki/CODEMAP.md for a tracked project.
+func writeCodeMap(p TrackedProject) error {
+	body, err := buildCodeMap(p.RepoPath)
+	if err != nil {
+		body = "# Code Map\n\nUnable to list repository files: " + err.Error() + "\n"
+	}
+	return writeFileAtomic(filepath.Join(p.StorageRoot, "wiki", codeMapFile), []byte(body), 0o644)
+}
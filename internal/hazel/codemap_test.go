@@ -0,0 +1,61 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildCodeMap(t *testing.T) {
+	repo := t.TempDir()
+	write := func(rel, body string) {
+		t.Helper()
+		p := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := ensureDir(filepath.Dir(p)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/shop\n\ngo 1.22\n")
+	write(".gitignore", "dist/\n")
+	write("dist/bundle.js", strings.Repeat("x\n", 5000))
+	write("Makefile", "build:\n\tgo build ./...\n")
+	write("cmd/shop/main.go", "package main\n\nfunc main() {}\n")
+	write("internal/cart/cart.go", "// Package cart keeps line items. It is not safe for concurrent use.\npackage cart\n\ntype Cart struct{}\n\nfunc (c *Cart) Add() {}\nfunc (c *Cart) reset() {}\n\nfunc New() *Cart { return nil }\n\nconst MaxItems = 50\n\nvar debug bool\n")
+	write("internal/cart/cart_test.go", "package cart\n\nfunc TestHidden() {}\n")
+	write("web/index.ts", "export {}\n")
+	testGitRepo(t, repo)
+
+	body, err := buildCodeMap(repo)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for _, want := range []string{
+		"type: architecture",
+		"- Files: 7 ",
+		"| Go | 3 |",
+		"- `cmd/` (1 files)\n    - `cmd/shop/` (1 files)",
+		"- `cmd/shop/` (Go `package main`)",
+		"- `web/index.ts`",
+		"- `go.mod`: module `example.com/shop`, go 1.22",
+		"- `Makefile`",
+		"### `example.com/shop/internal/cart`",
+		"- Package cart keeps line items.\n",
+		"- Exported: `Cart`, `Cart.Add`, `MaxItems`, `New`\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("code map missing %q:\n%s", want, body)
+		}
+	}
+	for _, bad := range []string{"dist/", "TestHidden", "reset", "debug"} {
+		if strings.Contains(body, bad) {
+			t.Fatalf("code map contains %q:\n%s", bad, body)
+		}
+	}
+	if again, _ := buildCodeMap(repo); again != body {
+		t.Fatal("code map is not deterministic")
+	}
+}
@@ -228,8 +228,8 @@ func sendCodexUserMessage(sessionID string, prompt string) (*CodexTurnResult, er
 
 	s.appendEvent(codexEvent{Type: "user_message", Text: prompt, ThreadID: threadID})
 	promptPayload := prompt
-	if ctx := strings.TrimSpace(buildCodexTaskContext(s.Root, s.TaskID)); ctx != "" {
-		promptPayload = "[Hazel task context]\n" + clipped(ctx, 2600) + "\n[/Hazel task context]\n\nUser message:\n" + prompt
+	if ctx := strings.TrimSpace(buildCodexTaskContext(s.Root, s.TaskID, prompt, codexTurnContextTokens)); ctx != "" {
+		promptPayload = "[Hazel task context]\n" + ctx + "\n[/Hazel task context]\n\nUser message:\n" + prompt
 	}
 	resp, err := s.sendRequest("turn/start", map[string]any{
 		"threadId": threadID,
@@ -368,7 +368,7 @@ func newCodexSession(root string, repoRoot string, taskID string, cmdLine string
 }
 
 func (s *codexSession) bootstrap(restart bool) error {
-	devInstructions := strings.TrimSpace(buildCodexTaskContext(s.Root, s.TaskID))
+	devInstructions := strings.TrimSpace(buildCodexTaskContext(s.Root, s.TaskID, "", 0))
 	_, err := s.sendRequest("initialize", map[string]any{
 		"clientInfo": map[string]any{
 			"name":    "hazel",
@@ -656,6 +656,7 @@ func (s *codexSession) handleServerRequest(method string, idRaw json.RawMessage,
 		s.pendingApprove[requestID] = a
 		s.mu.Unlock()
 		s.appendEvent(codexEvent{Type: "approval_requested", Text: method + ": " + strings.TrimSpace(p.Command), ItemID: requestID})
+		s.emitApprovalPending(a)
 		if strings.TrimSpace(p.Command) != "" {
 			s.appendEvent(codexEvent{Type: "tool_command", Text: strings.TrimSpace(p.Command), ThreadID: p.ThreadID, TurnID: p.TurnID, ItemID: p.ItemID})
 		}
@@ -669,6 +670,7 @@ func (s *codexSession) handleServerRequest(method string, idRaw json.RawMessage,
 		s.pendingApprove[requestID] = a
 		s.mu.Unlock()
 		s.appendEvent(codexEvent{Type: "approval_requested", Text: method})
+		s.emitApprovalPending(a)
 	case "item/tool/requestUserInput":
 		_ = s.sendResponse(requestID, map[string]any{"answers": map[string]any{}}, nil)
 		s.appendEvent(codexEvent{Type: "notice", Text: method + " auto-answered"})
@@ -678,6 +680,18 @@ func (s *codexSession) handleServerRequest(method string, idRaw json.RawMessage,
 	}
 }
 
+func (s *codexSession) emitApprovalPending(a codexApproval) {
+	_ = emitWebhookEvent(s.Root, webhookEventApprovalPending, map[string]any{
+		"task_id":    s.TaskID,
+		"session_id": s.ID,
+		"request_id": a.RequestID,
+		"method":     a.Method,
+		"reason":     a.Reason,
+		"command":    a.Command,
+		"cwd":        a.Cwd,
+	})
+}
+
 func (s *codexSession) sendRequest(method string, params any) (rpcReply, error) {
 	id := s.nextID.Add(1)
 	idKey := strconv.FormatInt(id, 10)
@@ -805,29 +819,21 @@ func (s *codexSession) stop() error {
 	return nil
 }
 
-func buildCodexTaskContext(root string, taskID string) string {
+// codexTurnContextTokens keeps the per-message context reminder short; the
+// thread's developer instructions carry the full budget.
+const codexTurnContextTokens = 650
+
+// buildCodexTaskContext assembles the task context for a Codex thread. query
+// (the user message, when set) steers the ranking; budget 0 uses the project
+// config.
+func buildCodexTaskContext(root string, taskID string, query string, budget int) string {
 	taskID = strings.TrimSpace(taskID)
 	repoRoot := resolveRepoRoot(root)
-	var taskTitle, taskStatus, taskMD string
+	var task *BoardTask
 	if taskID != "" {
-		var b Board
-		if err := readYAMLFile(boardPath(root), &b); err == nil {
-			for _, t := range b.Tasks {
-				if t.ID == taskID {
-					taskTitle = strings.TrimSpace(t.Title)
-					taskStatus = string(t.Status)
-					break
-				}
-			}
-		}
-		if mdb, err := os.ReadFile(taskFile(root, taskID, "task.md")); err == nil {
-			taskMD = string(mdb)
-		}
+		task, _ = findTaskInBoard(root, taskID)
 	}
-	wikiReadme, _ := os.ReadFile(filepath.Join(root, "wiki", "README.md"))
-	wikiFeatures, _ := os.ReadFile(filepath.Join(root, "wiki", "FEATURES_AND_USAGE.md"))
-	wikiChangelog, _ := os.ReadFile(filepath.Join(root, "wiki", "CHANGELOG.md"))
-	agentsMD, _ := os.ReadFile(filepath.Join(repoRoot, "AGENTS.md"))
+	pc := assemblePacketContext(root, contextRequest{Task: task, Query: query, Budget: budget})
 
 	var sb strings.Builder
 	sb.WriteString("Hazel Nexus task context. Prioritize this context while responding.\n\n")
@@ -836,26 +842,14 @@ func buildCodexTaskContext(root string, taskID string) string {
 	if taskID != "" {
 		sb.WriteString("Task ID: " + taskID + "\n")
 	}
-	if taskTitle != "" {
-		sb.WriteString("Task Title: " + taskTitle + "\n")
-	}
-	if taskStatus != "" {
-		sb.WriteString("Task Status: " + taskStatus + "\n")
-	}
-	if strings.TrimSpace(taskMD) != "" {
-		sb.WriteString("\nTask markdown:\n```markdown\n" + clipped(taskMD, 4000) + "\n```\n")
-	}
-	if strings.TrimSpace(string(agentsMD)) != "" {
-		sb.WriteString("\nAGENTS.md:\n```markdown\n" + clipped(string(agentsMD), 2500) + "\n```\n")
-	}
-	if strings.TrimSpace(string(wikiReadme)) != "" {
-		sb.WriteString("\nWiki README:\n```markdown\n" + clipped(string(wikiReadme), 1800) + "\n```\n")
-	}
-	if strings.TrimSpace(string(wikiFeatures)) != "" {
-		sb.WriteString("\nWiki FEATURES_AND_USAGE:\n```markdown\n" + clipped(string(wikiFeatures), 1800) + "\n```\n")
+	if task != nil {
+		sb.WriteString("Task Title: " + strings.TrimSpace(task.Title) + "\n")
+		sb.WriteString("Task Status: " + string(task.Status) + "\n")
 	}
-	if strings.TrimSpace(string(wikiChangelog)) != "" {
-		sb.WriteString("\nWiki CHANGELOG:\n```markdown\n" + clipped(string(wikiChangelog), 1200) + "\n```\n")
+	pc.writeMarkdown(&sb)
+	if budget == 0 {
+		// The sources table is for debugging the thread, not every message.
+		pc.writeSources(&sb)
 	}
 	return sb.String()
 }
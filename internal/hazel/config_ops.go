@@ -6,15 +6,72 @@ type ConfigUpdate struct {
 	GitHubToken      *string
 	GitBaseBranch    *string
 	ClearGitHubToken bool
+	// ForgeTokens sets tokens keyed by forge kind or host; ClearForgeTokens removes keys.
+	ForgeTokens      map[string]string
+	ClearForgeTokens []string
+	ForgeHosts       map[string]string
+}
+
+// migrateGitHubToken moves the legacy github_token into forge_tokens.
+func migrateGitHubToken(cfg *Config) {
+	tok := strings.TrimSpace(cfg.GitHubToken)
+	cfg.GitHubToken = ""
+	if tok == "" {
+		return
+	}
+	if cfg.ForgeTokens == nil {
+		cfg.ForgeTokens = map[string]string{}
+	}
+	if strings.TrimSpace(cfg.ForgeTokens[forgeGitHub]) == "" {
+		cfg.ForgeTokens[forgeGitHub] = tok
+	}
+}
+
+func setForgeToken(cfg *Config, key, token string) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	token = strings.TrimSpace(token)
+	if key == "" {
+		return
+	}
+	if token == "" {
+		delete(cfg.ForgeTokens, key)
+		return
+	}
+	if cfg.ForgeTokens == nil {
+		cfg.ForgeTokens = map[string]string{}
+	}
+	cfg.ForgeTokens[key] = token
 }
 
 func UpdateConfig(root string, upd ConfigUpdate) error {
 	cfg, _ := loadConfigOrDefault(root)
+	migrateGitHubToken(&cfg)
 	if upd.ClearGitHubToken {
-		cfg.GitHubToken = ""
+		setForgeToken(&cfg, forgeGitHub, "")
 	}
 	if upd.GitHubToken != nil {
-		cfg.GitHubToken = strings.TrimSpace(*upd.GitHubToken)
+		setForgeToken(&cfg, forgeGitHub, *upd.GitHubToken)
+	}
+	for _, k := range upd.ClearForgeTokens {
+		setForgeToken(&cfg, k, "")
+	}
+	for k, v := range upd.ForgeTokens {
+		setForgeToken(&cfg, k, v)
+	}
+	for host, kind := range upd.ForgeHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if host == "" {
+			continue
+		}
+		if kind == "" {
+			delete(cfg.ForgeHosts, host)
+			continue
+		}
+		if cfg.ForgeHosts == nil {
+			cfg.ForgeHosts = map[string]string{}
+		}
+		cfg.ForgeHosts[host] = kind
 	}
 	if upd.GitBaseBranch != nil {
 		cfg.GitBaseBranch = strings.TrimSpace(*upd.GitBaseBranch)
@@ -0,0 +1,295 @@
+package hazel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Acceptance criteria are the checkbox items under "## Acceptance Criteria" in
+// task.md. A criterion may declare a verification command and be marked optional:
+//
+//	- [ ] Login rejects bad passwords verify: `go test ./auth/...`
+//	- [ ] Docs mention the new flag (optional)
+//
+// Criteria are numbered AC-1, AC-2, ... in document order; impl.md checklist
+// items reference them by that ID.
+
+const criteriaFile = "criteria.json"
+
+const (
+	criterionPass    = "pass"
+	criterionFail    = "fail"
+	criterionManual  = "manual"
+	criterionPending = "pending"
+)
+
+type acceptanceCriterion struct {
+	ID       string `json:"id"`
+	Text     string `json:"text"`
+	Checked  bool   `json:"checked"`
+	Verify   string `json:"verify,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type criterionResult struct {
+	acceptanceCriterion
+	Status     string `json:"status"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	LogPath    string `json:"log_path,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type criteriaReport struct {
+	TaskID    string            `json:"task_id"`
+	CheckedAt time.Time         `json:"checked_at"`
+	RunLog    string            `json:"run_log,omitempty"`
+	Results   []criterionResult `json:"results"`
+}
+
+var (
+	checkboxRe = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	verifyRe   = regexp.MustCompile("(?i)\\bverify:\\s*`([^`]+)`")
+	optionalRe = regexp.MustCompile(`(?i)\(optional\)`)
+	criteriaID = regexp.MustCompile(`\bAC-(\d+)\b`)
+)
+
+func criteriaPath(root, taskID string) string {
+	return taskFile(root, taskID, criteriaFile)
+}
+
+// markdownSection returns the lines under the first "## <title>" heading up to
+// the next heading of the same or higher level.
+func markdownSection(md string, title string) []string {
+	var out []string
+	in := false
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") {
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if in && level <= 2 {
+				break
+			}
+			if level == 2 && strings.EqualFold(strings.TrimSpace(trimmed[level:]), title) {
+				in = true
+				continue
+			}
+		}
+		if in {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func parseAcceptanceCriteria(taskMD string) []acceptanceCriterion {
+	if _, _, without, err := parseHazelConfigBlock(taskMD); err == nil {
+		taskMD = without
+	}
+	var out []acceptanceCriterion
+	for _, line := range markdownSection(taskMD, "Acceptance Criteria") {
+		m := checkboxRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		c := acceptanceCriterion{
+			ID:      fmt.Sprintf("AC-%d", len(out)+1),
+			Checked: m[1] != " ",
+		}
+		text := m[2]
+		if vm := verifyRe.FindStringSubmatch(text); vm != nil {
+			c.Verify = strings.TrimSpace(vm[1])
+			text = verifyRe.ReplaceAllString(text, "")
+		}
+		if optionalRe.MatchString(text) {
+			c.Optional = true
+			text = optionalRe.ReplaceAllString(text, "")
+		}
+		c.Text = strings.TrimSpace(strings.Join(strings.Fields(text), " "))
+		out = append(out, c)
+	}
+	return out
+}
+
+type implChecklistItem struct {
+	Text    string
+	Checked bool
+	Refs    []string
+}
+
+// parseImplChecklist reads the impl.md checklist and the AC-n IDs each item references.
+func parseImplChecklist(implMD string) []implChecklistItem {
+	var out []implChecklistItem
+	for _, line := range markdownSection(implMD, "Checklist (Mapped To Acceptance Criteria)") {
+		m := checkboxRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		item := implChecklistItem{Text: strings.TrimSpace(m[2]), Checked: m[1] != " "}
+		for _, ref := range criteriaID.FindAllStringSubmatch(m[2], -1) {
+			item.Refs = append(item.Refs, "AC-"+ref[1])
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func readCriteriaReport(root, taskID string) (*criteriaReport, error) {
+	b, err := os.ReadFile(criteriaPath(root, taskID))
+	if err != nil {
+		return nil, err
+	}
+	var rep criteriaReport
+	if err := json.Unmarshal(b, &rep); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", criteriaFile, err)
+	}
+	return &rep, nil
+}
+
+// verifyTaskCriteria runs every criterion's verify command in the project repo
+// and writes criteria.json next to impl.md. Criteria without a command are
+// recorded as manual.
+func verifyTaskCriteria(ctx context.Context, root string, taskID string, runLog string) (*criteriaReport, error) {
+	md, err := readTaskMD(root, taskID)
+	if err != nil {
+		return nil, err
+	}
+	rep := &criteriaReport{TaskID: taskID, CheckedAt: time.Now(), RunLog: runLog, Results: []criterionResult{}}
+	logDir := filepath.Join(runsDir(root), "criteria")
+	stamp := rep.CheckedAt.Format("20060102T150405")
+	for _, c := range parseAcceptanceCriteria(md) {
+		res := criterionResult{acceptanceCriterion: c, Status: criterionManual}
+		if c.Verify != "" {
+			if err := ensureDir(logDir); err != nil {
+				return nil, err
+			}
+			res.LogPath = filepath.Join(logDir, fmt.Sprintf("%s_%s_%s.log", stamp, taskID, c.ID))
+			env := append(agentEnv(root, taskID, "verify"),
+				"HAZEL_CRITERION_ID="+c.ID,
+				"HAZEL_CRITERION_TEXT="+c.Text,
+			)
+			start := time.Now()
+			exit, rerr := runShellLogged(ctx, resolveRepoRoot(root), env, c.Verify, res.LogPath)
+			res.DurationMS = time.Since(start).Milliseconds()
+			switch {
+			case rerr != nil:
+				res.Status = criterionFail
+				res.Error = rerr.Error()
+			case exit != 0:
+				res.Status = criterionFail
+				res.ExitCode = &exit
+			default:
+				res.Status = criterionPass
+				res.ExitCode = &exit
+			}
+		}
+		rep.Results = append(rep.Results, res)
+	}
+	if err := writeFileAtomic(criteriaPath(root, taskID), mustJSONIndent(rep), 0o644); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// failedRequiredCriteria lists required criteria whose last verification failed
+// and whose definition (text + command) still matches task.md.
+func failedRequiredCriteria(root, taskID string) []criterionResult {
+	rep, err := readCriteriaReport(root, taskID)
+	if err != nil {
+		return nil
+	}
+	md, _ := readTaskMD(root, taskID)
+	current := map[string]acceptanceCriterion{}
+	for _, c := range parseAcceptanceCriteria(md) {
+		current[c.ID] = c
+	}
+	var out []criterionResult
+	for _, r := range rep.Results {
+		c, ok := current[r.ID]
+		if !ok || c.Text != r.Text || c.Verify != r.Verify || c.Optional {
+			continue
+		}
+		if r.Status == criterionFail {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// criteriaReviewBlock returns a transition error when required criteria failed.
+func criteriaReviewBlock(root, taskID string) error {
+	failed := failedRequiredCriteria(root, taskID)
+	if len(failed) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(failed))
+	for _, f := range failed {
+		ids = append(ids, f.ID)
+	}
+	return &transitionError{msg: "cannot move to REVIEW: required acceptance criteria failing (" + strings.Join(ids, ", ") + "); fix and re-run Verify"}
+}
+
+// criteriaMatrixRow is one line of the task page traceability matrix.
+type criteriaMatrixRow struct {
+	ID       string
+	Text     string
+	Checked  bool
+	Verify   string
+	Optional bool
+	Status   string
+	Detail   string
+	LogPath  string
+	Impl     []implChecklistItem
+	Stale    bool
+}
+
+func buildCriteriaMatrix(root, taskID, taskMD, implMD string) []criteriaMatrixRow {
+	criteria := parseAcceptanceCriteria(taskMD)
+	if len(criteria) == 0 {
+		return nil
+	}
+	results := map[string]criterionResult{}
+	if rep, err := readCriteriaReport(root, taskID); err == nil {
+		for _, r := range rep.Results {
+			results[r.ID] = r
+		}
+	}
+	impl := parseImplChecklist(implMD)
+	rows := make([]criteriaMatrixRow, 0, len(criteria))
+	for _, c := range criteria {
+		row := criteriaMatrixRow{ID: c.ID, Text: c.Text, Checked: c.Checked, Verify: c.Verify, Optional: c.Optional, Status: criterionPending}
+		if c.Verify == "" {
+			row.Status = criterionManual
+		}
+		if r, ok := results[c.ID]; ok {
+			row.Stale = r.Text != c.Text || r.Verify != c.Verify
+			if !row.Stale {
+				row.Status = r.Status
+				row.LogPath = rel(root, r.LogPath)
+				if r.ExitCode != nil && *r.ExitCode != 0 {
+					row.Detail = "exit " + strconv.Itoa(*r.ExitCode)
+				}
+				if r.Error != "" {
+					row.Detail = r.Error
+				}
+			}
+		}
+		for _, it := range impl {
+			for _, ref := range it.Refs {
+				if ref == c.ID {
+					row.Impl = append(row.Impl, it)
+					break
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,109 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAcceptanceCriteria(t *testing.T) {
+	md := "# Task\n\n## Summary\n\n- [ ] not a criterion\n\n## Acceptance Criteria\n\n" +
+		"- [ ] Login rejects bad passwords verify: `go test ./auth/...`\n" +
+		"- [x] Docs mention the flag (optional)\n" +
+		"  * [ ] Nested bullet verify: `make lint` (Optional)\n" +
+		"plain text line\n\n## Non-Goals\n\n- [ ] out of scope\n"
+	got := parseAcceptanceCriteria(md)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 criteria, got %d: %#v", len(got), got)
+	}
+	want := []acceptanceCriterion{
+		{ID: "AC-1", Text: "Login rejects bad passwords", Verify: "go test ./auth/..."},
+		{ID: "AC-2", Text: "Docs mention the flag", Checked: true, Optional: true},
+		{ID: "AC-3", Text: "Nested bullet", Verify: "make lint", Optional: true},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("criterion %d: got %#v want %#v", i, got[i], want[i])
+		}
+	}
+
+	impl := "# Implementation\n\n## Checklist (Mapped To Acceptance Criteria)\n\n- [x] AC-1: hash compare\n- [ ] AC-2, AC-3 docs\n"
+	items := parseImplChecklist(impl)
+	if len(items) != 2 || !items[0].Checked || len(items[1].Refs) != 2 || items[1].Refs[1] != "AC-3" {
+		t.Fatalf("unexpected impl checklist: %#v", items)
+	}
+}
+
+func TestFailingCriteriaKeepTaskOutOfReview(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0004", Title: "verified", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0004"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	md := "# HZ-0004\n\n## Acceptance Criteria\n\n- [ ] Output exists verify: `test -f out.txt`\n- [ ] Extra polish (optional) verify: `false`\n"
+	md, err := setTaskGitInMD(md, func(g *taskGitMeta) { g.PRURL = "https://github.com/acme/app/pull/4" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTaskMD(root, "HZ-0004", md); err != nil {
+		t.Fatal(err)
+	}
+	setAgent := func(cmd string) {
+		cfg, _ := loadConfigOrDefault(root)
+		cfg.AgentCommand, cfg.Preflight = cmd, preflightOff
+		if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	status := func() Status {
+		task, _ := findTaskInBoard(root, "HZ-0004")
+		return task.Status
+	}
+
+	// The agent does nothing, so AC-1 fails and the run leaves the task ACTIVE.
+	setAgent("true")
+	if _, err := RunTick(context.Background(), root, RunOptions{}); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	WaitBackground()
+	if got := status(); got != StatusActive {
+		t.Fatalf("status after failing criteria = %s, want ACTIVE", got)
+	}
+	_, err = transitionTaskStatus(root, "HZ-0004", StatusReview)
+	if !isTransitionError(err) || !strings.Contains(err.Error(), "AC-1") || strings.Contains(err.Error(), "AC-2") {
+		t.Fatalf("manual REVIEW with failing AC-1: %v", err)
+	}
+	if got := status(); got != StatusActive {
+		t.Fatalf("status after rejected move = %s", got)
+	}
+
+	// Once the required criterion passes (the optional one still fails), the
+	// next run ends in REVIEW.
+	if _, err := transitionTaskStatus(root, "HZ-0004", StatusReady); err != nil {
+		t.Fatal(err)
+	}
+	setAgent("touch out.txt")
+	if _, err := RunTick(context.Background(), root, RunOptions{}); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	WaitBackground()
+	if !exists(filepath.Join(resolveRepoRoot(root), "out.txt")) {
+		t.Fatal("agent did not run in the repo")
+	}
+	if got := status(); got != StatusReview {
+		t.Fatalf("status after passing criteria = %s, want REVIEW", got)
+	}
+	if _, err := os.Stat(criteriaPath(root, "HZ-0004")); err != nil {
+		t.Fatalf("criteria report missing: %v", err)
+	}
+}
@@ -0,0 +1,588 @@
+package hazel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Distill turns a finished task into a wiki page proposal (project config
+// `distill:`). With extract the page is built from task.md, impl.md, the run
+// history and the task chat; with agent the plan backend writes it (falling
+// back to extract). The proposal waits in the task dir as distill.md until it
+// is accepted into the wiki or declined, like plan.md.
+
+const (
+	distillProposalFile = "distill.md"
+	distillMetaFile     = "distill.json"
+)
+
+// Distill modes (project config `distill`). off is the default: nothing runs
+// on DONE, but Distill on the task page still works.
+const (
+	distillOff     = "off"
+	distillExtract = "extract"
+	distillAgent   = "agent"
+)
+
+// distillTag marks wiki pages written from a task distill.
+const distillTag = "distilled"
+
+// distillProposal describes the pending distill.md.
+type distillProposal struct {
+	TaskID    string    `json:"task_id"`
+	Page      string    `json:"page"`   // wiki-relative path the proposal is saved to
+	Method    string    `json:"method"` // extract or agent
+	CreatedAt time.Time `json:"created_at"`
+	RunLog    string    `json:"run_log,omitempty"`
+	Note      string    `json:"note,omitempty"` // why an agent distill fell back to extract
+}
+
+type DistillOptions struct {
+	// Project selects a tracked project when root is the nexus root.
+	Project string
+	// Agent asks the plan backend to write the page instead of extracting it.
+	Agent bool
+}
+
+type DistillResult struct {
+	TaskID        string
+	Page          string
+	Method        string
+	Path          string
+	Note          string
+	AgentExitCode *int
+	RunLogPath    string
+}
+
+func distillMode(cfg Config) string {
+	switch m := strings.ToLower(strings.TrimSpace(cfg.Distill)); m {
+	case distillExtract, distillAgent:
+		return m
+	}
+	return distillOff
+}
+
+func distillProposalPath(root, taskID string) string {
+	return taskFile(root, taskID, distillProposalFile)
+}
+
+// readDistillProposal returns the pending proposal and its page content.
+func readDistillProposal(root, taskID string) (*distillProposal, string, error) {
+	body, err := os.ReadFile(distillProposalPath(root, taskID))
+	if err != nil {
+		return nil, "", err
+	}
+	p := &distillProposal{TaskID: taskID, Method: distillAgent}
+	if b, err := os.ReadFile(taskFile(root, taskID, distillMetaFile)); err == nil {
+		if err := json.Unmarshal(b, p); err != nil {
+			return nil, "", fmt.Errorf("parse %s: %w", distillMetaFile, err)
+		}
+	}
+	if p.Page == "" {
+		task, _ := findTaskInBoard(root, taskID)
+		title := ""
+		if task != nil {
+			title = task.Title
+		}
+		p.Page = distillTargetPage(root, taskID, title)
+	}
+	return p, string(body), nil
+}
+
+func hasDistillProposal(root, taskID string) bool {
+	return exists(distillProposalPath(root, taskID))
+}
+
+func clearDistillProposal(root, taskID string) error {
+	for _, name := range []string{distillProposalFile, distillMetaFile} {
+		if err := os.Remove(taskFile(root, taskID, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// acceptDistillProposal saves the proposal into the wiki and clears it. It
+// returns the page path. Callers hold wikiMu.
+func acceptDistillProposal(root, taskID string) (string, error) {
+	p, body, err := readDistillProposal(root, taskID)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("distill proposal not found")
+		}
+		return "", err
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", fmt.Errorf("distill proposal is empty")
+	}
+	rel, err := cleanWikiPagePath(p.Page)
+	if err != nil {
+		return "", err
+	}
+	create := !exists(filepath.Join(projectWikiDir(root), filepath.FromSlash(rel)))
+	if _, err := saveWikiPage(root, rel, []byte(body), create, "", wikiOpDistill); err != nil {
+		return "", err
+	}
+	if err := writeWikiIndex(root); err != nil {
+		return "", err
+	}
+	return rel, clearDistillProposal(root, taskID)
+}
+
+// distillTargetPage is the page a task distills into: the earlier distilled
+// page for the task if there is one, otherwise tasks/<id>-<slug>.md.
+func distillTargetPage(root, taskID, title string) string {
+	for _, p := range loadWikiPages(root) {
+		if p.Meta.SourceTask == taskID && slices.Contains(p.Meta.Tags, distillTag) {
+			return p.Rel
+		}
+	}
+	return "tasks/" + strings.TrimPrefix(taskBranchName(taskID, title), "task/") + ".md"
+}
+
+// startTaskDistill distills a task in the background when the project config
+// enables it. It reports whether a distill was started.
+func startTaskDistill(stateRoot, taskID string) bool {
+	cfg, err := loadConfigOrDefault(stateRoot)
+	if err != nil {
+		return false
+	}
+	mode := distillMode(cfg)
+	if mode == distillOff {
+		return false
+	}
+	goBackground(func() {
+		_, _ = Distill(context.Background(), stateRoot, taskID, DistillOptions{Agent: mode == distillAgent})
+	})
+	return true
+}
+
+// Distill writes a wiki page proposal for a task to its distill.md.
+func Distill(ctx context.Context, root, taskID string, opt DistillOptions) (*DistillResult, error) {
+	if key := strings.TrimSpace(opt.Project); key != "" {
+		nx, err := LoadNexus(root)
+		if err != nil {
+			return nil, err
+		}
+		if nx == nil {
+			return nil, fmt.Errorf("projects_root_dir is not configured")
+		}
+		project, ok := nx.ProjectByKey(key)
+		if !ok {
+			return nil, fmt.Errorf("project not found: %s", key)
+		}
+		root = project.StorageRoot
+	}
+	if err := ensureDir(runsDir(root)); err != nil {
+		return nil, err
+	}
+	var res *DistillResult
+	err := withFileLock(distillLockPath(root, taskID), func() error {
+		ctx, done := trackDistill(ctx, root, taskID)
+		defer done()
+		var derr error
+		res, derr = distillTask(ctx, root, taskID, opt)
+		return derr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// distillLockPath is held for a whole distill so archiving can wait for it,
+// from this process or another one.
+func distillLockPath(root, taskID string) string {
+	return filepath.Join(runsDir(root), "distill-"+taskID+".lock")
+}
+
+// distillCancels holds the cancel funcs of this process's running distills.
+var distillCancels = struct {
+	sync.Mutex
+	m map[string]context.CancelFunc
+}{m: map[string]context.CancelFunc{}}
+
+func trackDistill(ctx context.Context, root, taskID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	key := distillLockPath(root, taskID)
+	distillCancels.Lock()
+	distillCancels.m[key] = cancel
+	distillCancels.Unlock()
+	return ctx, func() {
+		distillCancels.Lock()
+		delete(distillCancels.m, key)
+		distillCancels.Unlock()
+		cancel()
+	}
+}
+
+// waitTaskDistill blocks until a running distill of the task has finished.
+// With cancel, a distill running in this process is stopped first and writes
+// no proposal. Callers must not hold the repo lock.
+func waitTaskDistill(root, taskID string, cancel bool) {
+	key := distillLockPath(root, taskID)
+	if cancel {
+		distillCancels.Lock()
+		if c := distillCancels.m[key]; c != nil {
+			c()
+		}
+		distillCancels.Unlock()
+	}
+	if exists(key) {
+		_ = withFileLock(key, func() error { return nil })
+	}
+}
+
+func distillTask(ctx context.Context, root, taskID string, opt DistillOptions) (*DistillResult, error) {
+	cfg, _ := loadConfigOrDefault(root)
+	agent := opt.Agent && agentCommandForMode(cfg, "distill") != ""
+	now := time.Now()
+	// Only reading and writing the task files holds the repo lock; the agent
+	// runs without it, so a long distill does not stall runs and status changes.
+	var t *BoardTask
+	err := withRepoLock(root, func() error {
+		var err error
+		if t, err = findTaskInBoard(root, taskID); err != nil {
+			return err
+		}
+		if !exists(taskDir(root, taskID)) {
+			return fmt.Errorf("task directory missing for %s", taskID)
+		}
+		_ = clearDistillProposal(root, taskID)
+		if agent {
+			_, err = writePromptPacket(root, t, "distill", now)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &DistillResult{TaskID: taskID}
+	prop := distillProposal{TaskID: taskID, Method: distillExtract, CreatedAt: now}
+	if agent {
+		exit, logPath, err := runDistillAgent(ctx, root, cfg, t, now)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("distill of %s stopped: %w", taskID, ctx.Err())
+		}
+		res.AgentExitCode, res.RunLogPath = &exit, logPath
+		prop.RunLog = logPath
+	} else if opt.Agent {
+		prop.Note = "no agent command configured"
+	}
+	if err := withRepoLock(root, func() error { return writeDistillProposal(root, t, &prop, res) }); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// writeDistillProposal saves the agent's distill.md, or the extracted page
+// when there is none, with its distill.json. Callers hold the repo lock.
+func writeDistillProposal(root string, t *BoardTask, prop *distillProposal, res *DistillResult) error {
+	if !exists(taskDir(root, t.ID)) {
+		return fmt.Errorf("task directory missing for %s", t.ID)
+	}
+	prop.Page = distillTargetPage(root, t.ID, t.Title)
+	var body string
+	if res.AgentExitCode != nil {
+		b, _ := os.ReadFile(distillProposalPath(root, t.ID))
+		switch {
+		case *res.AgentExitCode != 0:
+			prop.Note = fmt.Sprintf("agent exited %d", *res.AgentExitCode)
+		case strings.TrimSpace(string(b)) == "":
+			prop.Note = "agent did not write " + distillProposalFile
+		default:
+			prop.Method = distillAgent
+			body = withDistillFrontmatter(string(b), t.ID)
+		}
+	}
+	if prop.Method == distillExtract {
+		body = extractDistillPage(root, t, prop.CreatedAt)
+	}
+	if err := writeFileAtomic(distillProposalPath(root, t.ID), []byte(body), 0o644); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(taskFile(root, t.ID, distillMetaFile), mustJSONIndent(prop), 0o644); err != nil {
+		return err
+	}
+	res.Page, res.Method, res.Note = prop.Page, prop.Method, prop.Note
+	res.Path = distillProposalPath(root, t.ID)
+	return nil
+}
+
+// runDistillAgent runs the plan backend in distill mode; the agent writes the
+// page to distill.md. Like plan runs it is gated by pre_run, fires post_run
+// and emits the run webhooks.
+func runDistillAgent(ctx context.Context, root string, cfg Config, t *BoardTask, now time.Time) (int, string, error) {
+	if err := runPreHook(ctx, root, hookPreRun, t.ID, map[string]string{"HAZEL_RUN_MODE": "distill"}); err != nil {
+		return 0, "", fmt.Errorf("%s: %w", t.ID, err)
+	}
+	lp, _ := computeRunLogPath(root, cfg, now, t.ID)
+	_ = writeRunState(root, &RunState{Running: true, TaskID: t.ID, Mode: "distill", LogPath: lp, StartedAt: now})
+	_ = emitWebhookEvent(root, webhookEventRunStarted, map[string]any{
+		"task_id":    t.ID,
+		"mode":       "distill",
+		"started_at": now,
+		"log_path":   lp,
+	})
+	exit, logPath, err := runAgentCommandMode(ctx, root, cfg, t.ID, now, "distill", lp)
+	if err != nil {
+		_ = writeRunState(root, &RunState{TaskID: t.ID, Mode: "distill", LogPath: lp, StartedAt: now, EndedAt: time.Now()})
+		return 0, "", err
+	}
+	if logPath != "" {
+		_ = writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{
+			"task_id":      t.ID,
+			"mode":         "distill",
+			"started_at":   now,
+			"ended_at":     time.Now(),
+			"exit_code":    exit,
+			"log_path":     logPath,
+			"json_summary": summarizeJSONEventsFromLog(logPath),
+		}), 0o644)
+	}
+	_ = writeRunState(root, &RunState{TaskID: t.ID, Mode: "distill", LogPath: logPath, StartedAt: now, EndedAt: time.Now(), ExitCode: &exit})
+	_ = emitWebhookEvent(root, webhookEventRunFinished, map[string]any{
+		"task_id":    t.ID,
+		"mode":       "distill",
+		"started_at": now,
+		"ended_at":   time.Now(),
+		"exit_code":  exit,
+		"log_path":   logPath,
+	})
+	fireHook(root, hookPostRun, t.ID, runHookEnv("distill", exit, logPath))
+	return exit, logPath, nil
+}
+
+// withDistillFrontmatter adds the distilled-note frontmatter to agent output
+// that has none, so the page stays linked to its task.
+func withDistillFrontmatter(body, taskID string) string {
+	body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n")) + "\n"
+	if strings.HasPrefix(body, "---\n") {
+		return body
+	}
+	return distillFrontmatter(taskID) + body
+}
+
+func distillFrontmatter(taskID string) string {
+	return fmt.Sprintf("---\ntype: note\ntags: [%s]\nsource_task: %s\n---\n", distillTag, taskID)
+}
+
+var (
+	distillGotchaRe = regexp.MustCompile(`(?i)\b(gotcha|pitfall|caveat|careful|watch out|beware|workaround|surprising|turns out|do not|don't|must not)\b`)
+	distillPathRe   = regexp.MustCompile("`([^`\\s]+\\.[A-Za-z0-9]+|[^`\\s]+/)`")
+)
+
+// extractDistillPage builds the deterministic page: summary, decisions, gotchas,
+// touched areas and the final run, each section only when there is content.
+func extractDistillPage(root string, t *BoardTask, now time.Time) string {
+	taskMD, _ := readTaskMD(root, t.ID)
+	// The git meta lives in the HAZEL-CONFIG block, so read it before the
+	// block is stripped from the text that gets distilled.
+	gitMeta, _ := getTaskGitFromMD(taskMD)
+	if _, _, without, err := parseHazelConfigBlock(taskMD); err == nil {
+		taskMD = without
+	}
+	implB, _ := os.ReadFile(taskFile(root, t.ID, "impl.md"))
+	implMD := string(implB)
+	runs, _ := listRunRecords(root, t.ID)
+
+	var sb strings.Builder
+	sb.WriteString(distillFrontmatter(t.ID))
+	fmt.Fprintf(&sb, "# %s: %s\n\n", t.ID, t.Title)
+	fmt.Fprintf(&sb, "Distilled from [[%s]] on %s.", t.ID, now.Format("2006-01-02"))
+	if gitMeta.PRURL != "" {
+		fmt.Fprintf(&sb, " PR: %s.", gitMeta.PRURL)
+	}
+	if gitMeta.MergeSHA != "" {
+		fmt.Fprintf(&sb, " Merged as `%.12s`.", gitMeta.MergeSHA)
+	}
+	sb.WriteString("\n")
+
+	section := func(title, body string) {
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(&sb, "\n## %s\n\n%s\n", title, body)
+		}
+	}
+	section("Summary", markdownSectionText(taskMD, "Summary"))
+
+	decisions := distillSections(implMD, "tradeoff", "decision", "constraint")
+	decisions = append(decisions, distillSections(taskMD, "decision")...)
+	if ng := markdownSectionText(taskMD, "Non-Goals"); ng != "" {
+		decisions = append(decisions, "Out of scope:\n\n"+ng)
+	}
+	section("Decisions", strings.Join(decisions, "\n\n"))
+
+	gotchas := distillSections(implMD, "gotcha", "pitfall", "caveat", "risk", "lesson", "known issue")
+	var lines []string
+	lines = append(lines, distillGotchaLines(implMD)...)
+	for _, r := range runs {
+		if s, _ := r.JSONSummary["last_text"].(string); s != "" {
+			lines = append(lines, distillGotchaLines(s)...)
+		}
+	}
+	lines = append(lines, distillGotchaLines(strings.Join(distillChatReplies(root, t.ID, 0), "\n"))...)
+	if lines = dedupeStrings(lines); len(lines) > 12 {
+		lines = lines[:12]
+	}
+	for _, ln := range lines {
+		gotchas = append(gotchas, "- "+clipped(ln, 300))
+	}
+	section("Gotchas", strings.Join(gotchas, "\n"))
+
+	section("Touched Areas", distillTouchedAreas(implMD, runs))
+
+	for _, r := range runs {
+		if r.Mode == "distill" {
+			continue
+		}
+		var fr strings.Builder
+		fmt.Fprintf(&fr, "- Run `%s`", r.Name)
+		if r.Mode != "" {
+			fmt.Fprintf(&fr, " (%s", r.Mode)
+			if r.ExitCode != nil {
+				fmt.Fprintf(&fr, ", exit %d", *r.ExitCode)
+			}
+			fr.WriteString(")")
+		}
+		fr.WriteString("\n")
+		if s, _ := r.JSONSummary["last_text"].(string); strings.TrimSpace(s) != "" {
+			fr.WriteString("\n> " + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n> ") + "\n")
+		}
+		section("Final Run", fr.String())
+		break
+	}
+	return sb.String()
+}
+
+// distillSections returns the level-2 sections of md whose title contains
+// one of words, each with its heading as a bold lead-in.
+func distillSections(md string, words ...string) []string {
+	var out []string
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "## ") {
+			continue
+		}
+		title := strings.TrimSpace(trimmed[3:])
+		lower := strings.ToLower(title)
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				if body := markdownSectionText(md, title); body != "" {
+					out = append(out, "**"+title+"**\n\n"+body)
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// distillGotchaLines picks the lines that read like warnings.
+func distillGotchaLines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*>#"))
+		if len(line) < 12 || !distillGotchaRe.MatchString(line) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// distillChatReplies returns the assistant messages of the task's latest chat
+// session, the last max of them when max > 0.
+func distillChatReplies(root, taskID string, max int) []string {
+	ss, ok := latestChatSessionForTask(root, taskID)
+	if !ok {
+		return nil
+	}
+	evs, err := loadChatSessionEvents(ss.Path)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, ev := range evs {
+		if ev.Type == "assistant_message" && strings.TrimSpace(ev.Text) != "" {
+			out = append(out, strings.TrimSpace(ev.Text))
+		}
+	}
+	if max > 0 && len(out) > max {
+		out = out[len(out)-max:]
+	}
+	return out
+}
+
+// distillTouchedAreas lists the files the task changed, grouped by directory:
+// the run change records plus paths named in impl.md's File Touch List.
+func distillTouchedAreas(implMD string, runs []runRecord) string {
+	files := map[string]bool{}
+	for _, r := range runs {
+		if r.Changes == nil {
+			continue
+		}
+		for _, f := range r.Changes.Files {
+			files[f.Path] = true
+		}
+	}
+	for _, line := range markdownSection(implMD, "File Touch List") {
+		for _, m := range distillPathRe.FindAllStringSubmatch(line, -1) {
+			files[strings.TrimPrefix(m[1], "./")] = true
+		}
+		if p := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*")); p != "" && !strings.ContainsAny(p, " `") && strings.Contains(p, ".") {
+			files[strings.TrimPrefix(p, "./")] = true
+		}
+	}
+	byDir := map[string][]string{}
+	for f := range files {
+		if strings.HasSuffix(f, "/") {
+			byDir[f] = append(byDir[f], "")
+			continue
+		}
+		dir := path.Dir(f)
+		if dir == "." {
+			dir = ""
+		} else {
+			dir += "/"
+		}
+		byDir[dir] = append(byDir[dir], path.Base(f))
+	}
+	dirs := make([]string, 0, len(byDir))
+	for d := range byDir {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	var sb strings.Builder
+	for _, d := range dirs {
+		names := dedupeStrings(byDir[d])
+		sort.Strings(names)
+		var quoted []string
+		for _, n := range names {
+			if n != "" {
+				quoted = append(quoted, "`"+n+"`")
+			}
+		}
+		switch {
+		case d == "":
+			fmt.Fprintf(&sb, "- %s\n", strings.Join(quoted, ", "))
+		case len(quoted) == 0:
+			fmt.Fprintf(&sb, "- `%s`\n", d)
+		default:
+			fmt.Fprintf(&sb, "- `%s`: %s\n", d, strings.Join(quoted, ", "))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,301 @@
+package hazel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDistillExtractAcceptAndArchive(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0050", Title: "Cache the board", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0050"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	md, err := setTaskGitInMD("# Task\n\n## Summary\n\nCache board.yaml reads.\n\n## Non-Goals\n\n- Cross-process caching\n", func(g *taskGitMeta) {
+		g.PRURL, g.MergeSHA = "https://github.com/acme/app/pull/7", "0123456789abcdef"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTaskMD(root, "HZ-0050", md); err != nil {
+		t.Fatal(err)
+	}
+	impl := "# Implementation\n\n## Plan\n\n1. Add cache\n\n## Constraints / Tradeoffs\n\n- Invalidate on mtime, not inotify.\n\n## File Touch List\n\n- `internal/hazel/board_cache.go` (new)\n- internal/hazel/up.go\n\n## Notes\n\n- Careful: writeYAMLFile must bump the cache or the UI shows stale cards.\n"
+	if err := os.WriteFile(taskFile(root, "HZ-0050", "impl.md"), []byte(impl), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(runsDir(root), "20260101T000000_HZ-0050.log")
+	if err := ensureDir(runsDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(logPath, []byte("done\n"), 0o644)
+	_ = writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{
+		"task_id":      "HZ-0050",
+		"mode":         "implement",
+		"exit_code":    0,
+		"log_path":     logPath,
+		"json_summary": map[string]any{"last_text": "Cache added; all tests pass."},
+		"changes":      runChanges{Files: []runChangedFile{{Path: "internal/hazel/board.go"}}},
+	}), 0o644)
+
+	res, err := Distill(context.Background(), root, "HZ-0050", DistillOptions{})
+	if err != nil {
+		t.Fatalf("distill: %v", err)
+	}
+	if res.Method != distillExtract || res.Page != "tasks/hz-0050-cache-the-board.md" {
+		t.Fatalf("result = %+v", res)
+	}
+	b, _ := os.ReadFile(distillProposalPath(root, "HZ-0050"))
+	page := string(b)
+	for _, want := range []string{
+		"source_task: HZ-0050",
+		"# HZ-0050: Cache the board",
+		"Distilled from [[HZ-0050]]",
+		"PR: https://github.com/acme/app/pull/7.",
+		"Merged as `0123456789ab`.",
+		"## Summary\n\nCache board.yaml reads.",
+		"Invalidate on mtime",
+		"Out of scope:\n\n- Cross-process caching",
+		"## Gotchas\n\n- Careful: writeYAMLFile must bump the cache",
+		"- `internal/hazel/`: `board.go`, `board_cache.go`, `up.go`",
+		"> Cache added; all tests pass.",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("proposal missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "HAZEL-CONFIG") {
+		t.Fatalf("config block distilled:\n%s", page)
+	}
+
+	// A pending proposal keeps the task on the board.
+	ar, err := ArchiveDone(context.Background(), root, ArchiveOptions{})
+	if err != nil || len(ar.ArchivedIDs) != 0 || len(ar.DistillPendingIDs) != 1 {
+		t.Fatalf("archive = %+v, %v", ar, err)
+	}
+
+	rel, err := acceptDistillProposal(root, "HZ-0050")
+	if err != nil || rel != res.Page || hasDistillProposal(root, "HZ-0050") {
+		t.Fatalf("accept = %q, %v", rel, err)
+	}
+	if revs, _ := wikiPageHistory(root, rel); len(revs) != 1 || revs[0].Op != wikiOpDistill {
+		t.Fatalf("revs = %+v", revs)
+	}
+	// The next distill updates the same page, even after a retitle.
+	if got := distillTargetPage(root, "HZ-0050", "Renamed"); got != rel {
+		t.Fatalf("target = %q", got)
+	}
+	if ar, err := ArchiveDone(context.Background(), root, ArchiveOptions{}); err != nil || len(ar.ArchivedIDs) != 1 {
+		t.Fatalf("archive after accept = %+v, %v", ar, err)
+	}
+}
+
+func TestDistillAgent(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0051", Title: "Agent notes", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0051"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	setCmd := func(cmd string) {
+		t.Helper()
+		cfg := "version: 1\ndistill: agent\nagent_command: exit 9\nagent_plan_command: '" + cmd + "'\n"
+		if err := os.WriteFile(configPath(root), []byte(cfg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	setCmd(`test "$HAZEL_MODE" = distill && printf "# Learned\n\nSee [[HZ-0051]].\n" > "$HAZEL_TASK_DIR/distill.md"`)
+	res, err := Distill(context.Background(), root, "HZ-0051", DistillOptions{Agent: true})
+	if err != nil || res.Method != distillAgent || res.AgentExitCode == nil || *res.AgentExitCode != 0 {
+		t.Fatalf("agent distill = %+v, %v", res, err)
+	}
+	p, body, err := readDistillProposal(root, "HZ-0051")
+	if err != nil || p.Method != distillAgent || !strings.HasPrefix(body, "---\ntype: note\ntags: [distilled]\nsource_task: HZ-0051\n---\n# Learned") {
+		t.Fatalf("proposal = %+v %q, %v", p, body, err)
+	}
+	if pkt, _ := os.ReadFile(taskFile(root, "HZ-0051", "prompt_packet.md")); !strings.Contains(string(pkt), "distill.md") || !strings.Contains(string(pkt), "## Extracted Draft") {
+		t.Fatalf("packet:\n%s", pkt)
+	}
+
+	// A failing agent falls back to extraction.
+	setCmd("exit 3")
+	res, err = Distill(context.Background(), root, "HZ-0051", DistillOptions{Agent: true})
+	if err != nil || res.Method != distillExtract || res.Note != "agent exited 3" {
+		t.Fatalf("fallback = %+v, %v", res, err)
+	}
+}
+
+func TestDistillAgentRunsWithoutRepoLock(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0052", Title: "Slow notes", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0052"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	// The agent waits until the test has taken the repo lock.
+	agent := `touch "$HAZEL_TASK_DIR/started"; while [ ! -f "$HAZEL_TASK_DIR/go" ]; do sleep 0.05; done; echo "# Slow" > "$HAZEL_TASK_DIR/distill.md"`
+	if err := os.WriteFile(configPath(root), []byte("version: 1\nagent_command: '"+agent+"'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := Distill(context.Background(), root, "HZ-0052", DistillOptions{Agent: true})
+		done <- err
+	}()
+	for deadline := time.Now().Add(5 * time.Second); !exists(taskFile(root, "HZ-0052", "started")); time.Sleep(20 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("agent did not start")
+		}
+	}
+	locked := make(chan error, 1)
+	go func() {
+		locked <- withRepoLock(root, func() error {
+			return os.WriteFile(taskFile(root, "HZ-0052", "go"), nil, 0o644)
+		})
+	}()
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("repo lock held while the distill agent runs")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("distill: %v", err)
+	}
+	if p, _, err := readDistillProposal(root, "HZ-0052"); err != nil || p.Method != distillAgent {
+		t.Fatalf("proposal = %+v, %v", p, err)
+	}
+}
+
+func TestDistillAgentRunsHooksAndWebhooks(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0053", Title: "Hooked notes", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0053"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	marker := filepath.Join(t.TempDir(), "post_run")
+	cfg := defaultConfig()
+	cfg.AgentCommand = `echo "# Hooked" > "$HAZEL_TASK_DIR/distill.md"`
+	cfg.Webhooks = []WebhookConfig{{URL: "http://127.0.0.1:1/hook", Events: []string{"run.*"}}}
+	cfg.Hooks = map[string]string{hookPostRun: `echo "$HAZEL_RUN_MODE" > ` + marker}
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Distill(context.Background(), root, "HZ-0053", DistillOptions{Agent: true}); err != nil {
+		t.Fatalf("distill: %v", err)
+	}
+	WaitBackground()
+	if b, _ := os.ReadFile(marker); strings.TrimSpace(string(b)) != "distill" {
+		t.Fatalf("post_run saw mode %q", b)
+	}
+	q, _ := readWebhookQueue(root)
+	if len(q.Deliveries) != 2 || q.Deliveries[0].Event != webhookEventRunStarted || q.Deliveries[1].Event != webhookEventRunFinished {
+		t.Fatalf("deliveries = %+v", q.Deliveries)
+	}
+
+	// pre_run vetoes the agent like any other run.
+	cfg.Hooks[hookPreRun] = `test "$HAZEL_RUN_MODE" != distill`
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	var veto *hookVetoError
+	if _, err := Distill(context.Background(), root, "HZ-0053", DistillOptions{Agent: true}); !errors.As(err, &veto) {
+		t.Fatalf("distill past a pre_run veto: %v", err)
+	}
+}
+
+func TestArchiveWaitsForRunningDistill(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0054", Title: "Racing notes", Status: StatusDone, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0054"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	agent := `touch "$HAZEL_TASK_DIR/started"; while [ ! -f "$HAZEL_TASK_DIR/go" ]; do sleep 0.05; done; cat "$HAZEL_TASK_DIR/task.md" > "$HAZEL_TASK_DIR/distill.md"`
+	if err := os.WriteFile(configPath(root), []byte("version: 1\nagent_command: '"+agent+"'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	startDistill := func() chan error {
+		t.Helper()
+		_ = os.Remove(taskFile(root, "HZ-0054", "started"))
+		_ = os.Remove(taskFile(root, "HZ-0054", "go"))
+		done := make(chan error, 1)
+		go func() {
+			_, err := Distill(context.Background(), root, "HZ-0054", DistillOptions{Agent: true})
+			done <- err
+		}()
+		for deadline := time.Now().Add(5 * time.Second); !exists(taskFile(root, "HZ-0054", "started")); time.Sleep(20 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("agent did not start")
+			}
+		}
+		return done
+	}
+
+	// Archive waits for the distill, whose proposal then keeps the task.
+	done := startDistill()
+	archived := make(chan *ArchiveResult, 1)
+	go func() {
+		ar, _ := ArchiveDone(context.Background(), root, ArchiveOptions{})
+		archived <- ar
+	}()
+	select {
+	case ar := <-archived:
+		t.Fatalf("archive did not wait for the distill: %+v", ar)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if err := os.WriteFile(taskFile(root, "HZ-0054", "go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("distill: %v", err)
+	}
+	if ar := <-archived; ar == nil || len(ar.ArchivedIDs) != 0 || len(ar.DistillPendingIDs) != 1 {
+		t.Fatalf("archive = %+v", ar)
+	}
+
+	// A cancelled distill stops its agent and proposes nothing.
+	done = startDistill()
+	waitTaskDistill(root, "HZ-0054", true)
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "stopped") {
+		t.Fatalf("cancelled distill: %v", err)
+	}
+	if hasDistillProposal(root, "HZ-0054") {
+		t.Fatal("cancelled distill left a proposal")
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 )
 
 type DoctorReport struct {
@@ -31,6 +34,17 @@ func Doctor(ctx context.Context, root string) (*DoctorReport, error) {
 		return r, nil
 	}
 
+	forgeHosts := make([]string, 0, len(cfg.ForgeHosts))
+	for host := range cfg.ForgeHosts {
+		forgeHosts = append(forgeHosts, host)
+	}
+	sort.Strings(forgeHosts)
+	for _, host := range forgeHosts {
+		if !slices.Contains(forgeKinds, strings.ToLower(strings.TrimSpace(cfg.ForgeHosts[host]))) {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("forge_hosts: %s has unknown forge %q (want one of %s)", host, cfg.ForgeHosts[host], strings.Join(forgeKinds, ", ")))
+		}
+	}
+
 	// Nexus mode validates each tracked project workspace.
 	if strings.TrimSpace(cfg.ProjectsRootDir) != "" {
 		nx, err := LoadNexus(root)
@@ -44,6 +58,51 @@ func Doctor(ctx context.Context, root string) (*DoctorReport, error) {
 					r.Problems = append(r.Problems, fmt.Sprintf("%s missing %s", p.Key, hp))
 				}
 			}
+			for _, msg := range slices.Concat(brokenWikiLinks(p.StorageRoot), wikiPageWarnings(p.StorageRoot, time.Now()), wikiMirrorWarnings(p.StorageRoot)) {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", p.Key, msg))
+			}
+			var pb Board
+			if err := readYAMLFile(boardPath(p.StorageRoot), &pb); err == nil {
+				for _, t := range pb.Tasks {
+					if _, err := linkedProjects(nx, t); err != nil {
+						r.Warnings = append(r.Warnings, fmt.Sprintf("%s %s: %v", p.Key, t.ID, err))
+					}
+				}
+			}
+			problems, warnings := validatePacketTemplates(p.StorageRoot)
+			for _, msg := range problems {
+				r.Problems = append(r.Problems, fmt.Sprintf("%s: %s", p.Key, msg))
+			}
+			for _, msg := range warnings {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: %s", p.Key, msg))
+			}
+			if !exists(configPath(p.StorageRoot)) {
+				continue
+			}
+			var pcfg Config
+			if err := readYAMLFile(configPath(p.StorageRoot), &pcfg); err != nil {
+				r.Problems = append(r.Problems, fmt.Sprintf("%s: %v", p.Key, err))
+				continue
+			}
+			if m := strings.ToLower(strings.TrimSpace(pcfg.Preflight)); m != "" && !slices.Contains([]string{preflightStrict, preflightWarn, preflightOff}, m) {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: preflight %q is not strict, warn or off; using warn", p.Key, m))
+			}
+			if m := strings.ToLower(strings.TrimSpace(pcfg.Distill)); m != "" && !slices.Contains([]string{distillOff, distillExtract, distillAgent}, m) {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: distill %q is not off, extract or agent; using off", p.Key, m))
+			}
+			if pcfg.ContextBudget < 0 {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: context_budget must be >= 0; using %d", p.Key, defaultContextBudget))
+			}
+			hookKeys := make([]string, 0, len(pcfg.Hooks))
+			for key := range pcfg.Hooks {
+				hookKeys = append(hookKeys, key)
+			}
+			sort.Strings(hookKeys)
+			for _, key := range hookKeys {
+				if !validHookKey(key) {
+					r.Warnings = append(r.Warnings, fmt.Sprintf("%s: unknown hook %q", p.Key, key))
+				}
+			}
 		}
 		return r, nil
 	}
@@ -87,6 +146,11 @@ func Doctor(ctx context.Context, root string) (*DoctorReport, error) {
 			}
 		}
 	}
+	r.Warnings = append(r.Warnings, brokenWikiLinks(root)...)
+	r.Warnings = append(r.Warnings, wikiPageWarnings(root, time.Now())...)
+	problems, warnings := validatePacketTemplates(root)
+	r.Problems = append(r.Problems, problems...)
+	r.Warnings = append(r.Warnings, warnings...)
 
 	return r, nil
 }
@@ -62,7 +62,8 @@ func exportChatGPTProject(stateRoot string, title string, repoPath string, outDi
 	_ = copyIf(filepath.Join(stateRoot, "wiki", "README.md"), filepath.Join(outDir, "WIKI_README.md"))
 	_ = copyIf(filepath.Join(stateRoot, "wiki", "FEATURES_AND_USAGE.md"), filepath.Join(outDir, "WIKI_FEATURES_AND_USAGE.md"))
 	_ = copyIf(filepath.Join(stateRoot, "wiki", "CHANGELOG.md"), filepath.Join(outDir, "WIKI_CHANGELOG.md"))
-	_ = copyIf(filepath.Join(stateRoot, "wiki", "SOURCE_README.md"), filepath.Join(outDir, "SOURCE_README.md"))
+	_ = copyIf(filepath.Join(stateRoot, "wiki", sourceReadmeFile), filepath.Join(outDir, "SOURCE_README.md"))
+	_ = copyIf(filepath.Join(stateRoot, "wiki", codeMapFile), filepath.Join(outDir, "WIKI_CODEMAP.md"))
 	_ = copyIf(filepath.Join(resolveRepoRoot(stateRoot), "AGENTS.md"), filepath.Join(outDir, "AGENTS.md"))
 
 	return nil
@@ -0,0 +1,522 @@
+package hazel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Forge kinds accepted in forge_hosts / forge_tokens.
+const (
+	forgeGitHub = "github"
+	forgeGitLab = "gitlab"
+	forgeGitea  = "gitea"
+	forgeNone   = "none"
+)
+
+var forgeKinds = []string{forgeGitHub, forgeGitLab, forgeGitea, forgeNone}
+
+// remoteInfo is a parsed git remote URL. Path is the repo path without ".git"
+// (owner/repo, or group/subgroup/repo on GitLab).
+type remoteInfo struct {
+	Scheme string
+	Host   string
+	Path   string
+}
+
+func (ri remoteInfo) webURL() string {
+	if ri.Host == "" || ri.Path == "" {
+		return ""
+	}
+	return ri.Scheme + "://" + ri.Host + "/" + ri.Path
+}
+
+// parseRemoteURL understands https://host/owner/repo(.git), ssh://git@host[:port]/owner/repo
+// and scp-like git@host:owner/repo. Local paths yield an empty Host.
+func parseRemoteURL(raw string) remoteInfo {
+	raw = strings.TrimSpace(raw)
+	ri := remoteInfo{Scheme: "https"}
+	if raw == "" {
+		return ri
+	}
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ri
+		}
+		if u.Scheme == "http" {
+			ri.Scheme = "http"
+		}
+		if u.Scheme == "file" {
+			return ri
+		}
+		ri.Host = u.Hostname()
+		// Keep non-default ports for http(s) remotes; ssh ports never map to the web UI.
+		if p := u.Port(); p != "" && (u.Scheme == "http" || u.Scheme == "https") {
+			ri.Host += ":" + p
+		}
+		ri.Path = u.Path
+	} else if at := strings.Index(raw, "@"); at >= 0 && strings.Contains(raw[at:], ":") {
+		rest := raw[at+1:]
+		i := strings.Index(rest, ":")
+		ri.Host = rest[:i]
+		ri.Path = rest[i+1:]
+	} else {
+		return ri
+	}
+	ri.Path = strings.TrimSuffix(strings.Trim(ri.Path, "/"), ".git")
+	return ri
+}
+
+func readOriginURL(repo string) string {
+	out, err := runCmd(repo, nil, "git", "config", "--get", "remote.origin.url")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// forgeKindForHost picks the forge for a remote host: explicit forge_hosts
+// entries first, then well-known host names. Unknown hosts get "none".
+func forgeKindForHost(cfg Config, host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if k := strings.ToLower(strings.TrimSpace(cfg.ForgeHosts[host])); k != "" {
+		return k
+	}
+	name := host
+	if i := strings.Index(name, ":"); i >= 0 {
+		name = name[:i]
+	}
+	if k := strings.ToLower(strings.TrimSpace(cfg.ForgeHosts[name])); k != "" {
+		return k
+	}
+	switch {
+	case name == "github.com" || strings.HasPrefix(name, "github."):
+		return forgeGitHub
+	case strings.Contains(name, "gitlab"):
+		return forgeGitLab
+	case strings.Contains(name, "gitea"), strings.Contains(name, "forgejo"), name == "codeberg.org":
+		return forgeGitea
+	}
+	return forgeNone
+}
+
+// forgeToken resolves the token for a forge: forge_tokens by host, then by
+// kind; the legacy github_token still applies to GitHub.
+func forgeToken(cfg Config, kind, host string) string {
+	if t := strings.TrimSpace(cfg.ForgeTokens[strings.ToLower(host)]); t != "" {
+		return t
+	}
+	if t := strings.TrimSpace(cfg.ForgeTokens[kind]); t != "" {
+		return t
+	}
+	if kind == forgeGitHub {
+		return strings.TrimSpace(cfg.GitHubToken)
+	}
+	return ""
+}
+
+type prRequest struct {
+	Base  string
+	Head  string
+	Title string
+	Body  string
+}
+
+// forge opens and inspects pull/merge requests for one repo remote.
+type forge interface {
+	Kind() string
+	// OpenPR creates the PR/MR for an already pushed branch and returns its web
+	// URL; noneForge returns "" when origin has no web URL.
+	OpenPR(req prRequest) (string, error)
+	PRStatus(prURL string) (prStatus, error)
+	CompareURL(base, head string) string
+	// FileURL links a file at ref on the forge's web UI; raw serves its bytes.
+	FileURL(ref, file string, raw bool) string
+}
+
+// projectForge builds the forge for a tracked project from its origin remote.
+func projectForge(project TrackedProject, cfg Config) forge {
+	ri := parseRemoteURL(readOriginURL(project.RepoPath))
+	kind := forgeKindForHost(cfg, ri.Host)
+	base := forgeBase{repo: project.RepoPath, remote: ri, token: forgeToken(cfg, kind, ri.Host), slug: strings.TrimSpace(project.RepoSlug)}
+	switch kind {
+	case forgeGitHub:
+		return githubForge{base}
+	case forgeGitLab:
+		return gitlabForge{base}
+	case forgeGitea:
+		return giteaForge{base}
+	}
+	return noneForge{base}
+}
+
+type forgeBase struct {
+	repo   string
+	remote remoteInfo
+	token  string
+	slug   string
+}
+
+func (f forgeBase) CompareURL(base, head string) string {
+	web := f.remote.webURL()
+	if web == "" {
+		return ""
+	}
+	return web + "/compare/" + base + "..." + head
+}
+
+func (f forgeBase) FileURL(ref, file string, raw bool) string {
+	web := f.remote.webURL()
+	if web == "" {
+		return ""
+	}
+	if raw {
+		return web + "/raw/" + ref + "/" + file
+	}
+	return web + "/blob/" + ref + "/" + file
+}
+
+var forgeHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// apiJSON performs a forge REST call and decodes the JSON response into out.
+func (f forgeBase) apiJSON(method, endpoint string, header http.Header, in any, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := forgeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(b))
+		if len(msg) > 300 {
+			msg = msg[:300]
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, endpoint, resp.Status, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("parse %s response: %w", endpoint, err)
+	}
+	return nil
+}
+
+// prNumberFromURL returns the trailing number of a PR/MR web URL.
+func prNumberFromURL(prURL string) (int, error) {
+	u, err := url.Parse(strings.TrimSpace(prURL))
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(path.Base(strings.TrimRight(u.Path, "/")))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("no PR number in %q", prURL)
+	}
+	return n, nil
+}
+
+type githubForge struct{ forgeBase }
+
+func (githubForge) Kind() string { return forgeGitHub }
+
+func (f githubForge) env() []string {
+	if f.token == "" {
+		return nil
+	}
+	return []string{"GH_TOKEN=" + f.token}
+}
+
+func (f githubForge) OpenPR(req prRequest) (string, error) {
+	args := []string{"pr", "create", "--base", req.Base, "--head", req.Head, "--title", req.Title, "--body", req.Body}
+	if f.slug != "" {
+		args = append(args, "--repo", f.slug)
+	}
+	out, err := runCmd(f.repo, f.env(), "gh", args...)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 || (!strings.HasPrefix(fields[0], "http://") && !strings.HasPrefix(fields[0], "https://")) {
+		return "", fmt.Errorf("unable to parse PR URL from gh output: %q", out)
+	}
+	return fields[0], nil
+}
+
+func (f githubForge) PRStatus(prURL string) (prStatus, error) {
+	out, err := runCmd(f.repo, f.env(), "gh", "pr", "view", prURL, "--json", "state,mergedAt,mergeCommit,reviewDecision,statusCheckRollup")
+	if err != nil {
+		return prStatus{}, err
+	}
+	var v ghPRView
+	if err := json.Unmarshal([]byte(out), &v); err != nil {
+		return prStatus{}, fmt.Errorf("parse gh pr view output: %w", err)
+	}
+	st := prStatus{
+		State:          strings.ToUpper(strings.TrimSpace(v.State)),
+		ReviewDecision: strings.ToUpper(strings.TrimSpace(v.ReviewDecision)),
+		Checks:         summarizePRChecks(v),
+		MergedAt:       strings.TrimSpace(v.MergedAt),
+	}
+	if v.MergeCommit != nil {
+		st.MergeSHA = strings.TrimSpace(v.MergeCommit.OID)
+	}
+	return st, nil
+}
+
+// gitlabForge talks to the GitLab v4 REST API when a token is configured and
+// falls back to the glab CLI (which carries its own auth) otherwise.
+type gitlabForge struct{ forgeBase }
+
+func (gitlabForge) Kind() string { return forgeGitLab }
+
+func (f gitlabForge) projectAPI() string {
+	return f.remote.Scheme + "://" + f.remote.Host + "/api/v4/projects/" + url.PathEscape(f.remote.Path)
+}
+
+func (f gitlabForge) header() http.Header {
+	h := http.Header{}
+	if f.token != "" {
+		h.Set("Private-Token", f.token)
+	}
+	return h
+}
+
+// useGlab reports whether calls go through glab because no token is
+// configured, and fails when glab is not installed either.
+func (f gitlabForge) useGlab() (bool, error) {
+	if f.token != "" {
+		return false, nil
+	}
+	if _, err := exec.LookPath("glab"); err != nil {
+		return true, fmt.Errorf("no gitlab token configured (forge_tokens) and glab is not installed")
+	}
+	return true, nil
+}
+
+func (f gitlabForge) CompareURL(base, head string) string {
+	web := f.remote.webURL()
+	if web == "" {
+		return ""
+	}
+	return web + "/-/compare/" + base + "..." + head
+}
+
+func (f gitlabForge) FileURL(ref, file string, raw bool) string {
+	web := f.remote.webURL()
+	if web == "" {
+		return ""
+	}
+	if raw {
+		return web + "/-/raw/" + ref + "/" + file
+	}
+	return web + "/-/blob/" + ref + "/" + file
+}
+
+func (f gitlabForge) OpenPR(req prRequest) (string, error) {
+	glab, err := f.useGlab()
+	if err != nil {
+		return "", err
+	}
+	if glab {
+		out, err := runCmd(f.repo, nil, "glab", "mr", "create", "--source-branch", req.Head, "--target-branch", req.Base, "--title", req.Title, "--description", req.Body, "--yes")
+		if err != nil {
+			return "", err
+		}
+		for _, field := range strings.Fields(out) {
+			if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+				return field, nil
+			}
+		}
+		return "", fmt.Errorf("unable to parse MR URL from glab output: %q", out)
+	}
+	var mr struct {
+		WebURL string `json:"web_url"`
+	}
+	err = f.apiJSON(http.MethodPost, f.projectAPI()+"/merge_requests", f.header(), map[string]any{
+		"source_branch": req.Head,
+		"target_branch": req.Base,
+		"title":         req.Title,
+		"description":   req.Body,
+	}, &mr)
+	if err != nil {
+		return "", err
+	}
+	if mr.WebURL == "" {
+		return "", fmt.Errorf("gitlab did not return a merge request URL")
+	}
+	return mr.WebURL, nil
+}
+
+func (f gitlabForge) PRStatus(prURL string) (prStatus, error) {
+	n, err := prNumberFromURL(prURL)
+	if err != nil {
+		return prStatus{}, err
+	}
+	var mr struct {
+		State          string `json:"state"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		SquashSHA      string `json:"squash_commit_sha"`
+		MergedAt       string `json:"merged_at"`
+		Pipeline       *struct {
+			Status string `json:"status"`
+		} `json:"head_pipeline"`
+	}
+	glab, err := f.useGlab()
+	if err != nil {
+		return prStatus{}, err
+	}
+	if glab {
+		// glab api returns the same REST document with glab's own auth.
+		out, err := runCmd(f.repo, nil, "glab", "api", "projects/"+url.PathEscape(f.remote.Path)+"/merge_requests/"+strconv.Itoa(n))
+		if err != nil {
+			return prStatus{}, err
+		}
+		if err := json.Unmarshal([]byte(out), &mr); err != nil {
+			return prStatus{}, fmt.Errorf("parse glab api output: %w", err)
+		}
+	} else if err := f.apiJSON(http.MethodGet, f.projectAPI()+"/merge_requests/"+strconv.Itoa(n), f.header(), nil, &mr); err != nil {
+		return prStatus{}, err
+	}
+	st := prStatus{MergedAt: mr.MergedAt, MergeSHA: mr.MergeCommitSHA}
+	if st.MergeSHA == "" {
+		st.MergeSHA = mr.SquashSHA
+	}
+	switch mr.State {
+	case "merged":
+		st.State = prStateMerged
+	case "closed":
+		st.State = prStateClosed
+	default:
+		st.State = prStateOpen
+	}
+	if mr.Pipeline != nil {
+		switch mr.Pipeline.Status {
+		case "success":
+			st.Checks = "passing"
+		case "failed", "canceled":
+			st.Checks = "failing"
+		default:
+			st.Checks = "pending"
+		}
+	}
+	return st, nil
+}
+
+// giteaForge covers Gitea and Forgejo, which share the /api/v1 pulls API.
+type giteaForge struct{ forgeBase }
+
+func (giteaForge) Kind() string { return forgeGitea }
+
+func (f giteaForge) repoAPI() string {
+	return f.remote.Scheme + "://" + f.remote.Host + "/api/v1/repos/" + f.remote.Path
+}
+
+func (f giteaForge) header() http.Header {
+	h := http.Header{}
+	if f.token != "" {
+		h.Set("Authorization", "token "+f.token)
+	}
+	return h
+}
+
+func (f giteaForge) FileURL(ref, file string, raw bool) string {
+	web := f.remote.webURL()
+	if web == "" {
+		return ""
+	}
+	if raw {
+		return web + "/raw/branch/" + ref + "/" + file
+	}
+	return web + "/src/branch/" + ref + "/" + file
+}
+
+func (f giteaForge) OpenPR(req prRequest) (string, error) {
+	if f.token == "" {
+		return "", fmt.Errorf("no gitea token configured (forge_tokens)")
+	}
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+	}
+	err := f.apiJSON(http.MethodPost, f.repoAPI()+"/pulls", f.header(), map[string]any{
+		"base":  req.Base,
+		"head":  req.Head,
+		"title": req.Title,
+		"body":  req.Body,
+	}, &pr)
+	if err != nil {
+		return "", err
+	}
+	if pr.HTMLURL == "" {
+		return "", fmt.Errorf("gitea did not return a pull request URL")
+	}
+	return pr.HTMLURL, nil
+}
+
+func (f giteaForge) PRStatus(prURL string) (prStatus, error) {
+	n, err := prNumberFromURL(prURL)
+	if err != nil {
+		return prStatus{}, err
+	}
+	var pr struct {
+		State          string  `json:"state"`
+		Merged         bool    `json:"merged"`
+		MergeCommitSHA string  `json:"merge_commit_sha"`
+		MergedAt       *string `json:"merged_at"`
+	}
+	if err := f.apiJSON(http.MethodGet, f.repoAPI()+"/pulls/"+strconv.Itoa(n), f.header(), nil, &pr); err != nil {
+		return prStatus{}, err
+	}
+	st := prStatus{State: prStateOpen, MergeSHA: pr.MergeCommitSHA}
+	switch {
+	case pr.Merged:
+		st.State = prStateMerged
+		if pr.MergedAt != nil {
+			st.MergedAt = *pr.MergedAt
+		}
+	case pr.State == "closed":
+		st.State = prStateClosed
+	}
+	return st, nil
+}
+
+// noneForge only pushes: the "PR URL" is the compare page, or empty when the
+// remote has no web URL, and merge detection relies on local git.
+type noneForge struct{ forgeBase }
+
+func (noneForge) Kind() string { return forgeNone }
+
+func (f noneForge) OpenPR(req prRequest) (string, error) {
+	return f.CompareURL(req.Base, req.Head), nil
+}
+
+func (noneForge) PRStatus(string) (prStatus, error) {
+	return prStatus{}, fmt.Errorf("no forge configured for this remote")
+}
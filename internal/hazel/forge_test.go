@@ -0,0 +1,151 @@
+package hazel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want remoteInfo
+	}{
+		{"https://github.com/flip-z/hazel.git", remoteInfo{"https", "github.com", "flip-z/hazel"}},
+		{"git@gitlab.example.com:group/sub/app.git", remoteInfo{"https", "gitlab.example.com", "group/sub/app"}},
+		{"ssh://git@codeberg.org:2222/me/tool.git", remoteInfo{"https", "codeberg.org", "me/tool"}},
+		{"http://127.0.0.1:3000/me/tool", remoteInfo{"http", "127.0.0.1:3000", "me/tool"}},
+		{"/srv/git/tool.git", remoteInfo{Scheme: "https"}},
+	}
+	for _, c := range cases {
+		if got := parseRemoteURL(c.raw); got != c.want {
+			t.Fatalf("parseRemoteURL(%q) = %+v, want %+v", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestForgeKindForHost(t *testing.T) {
+	cfg := Config{ForgeHosts: map[string]string{"git.corp.example": "gitlab"}}
+	cases := map[string]string{
+		"github.com":       forgeGitHub,
+		"gitlab.com":       forgeGitLab,
+		"codeberg.org":     forgeGitea,
+		"git.corp.example": forgeGitLab,
+		"example.org":      forgeNone,
+		"":                 forgeNone,
+	}
+	for host, want := range cases {
+		if got := forgeKindForHost(cfg, host); got != want {
+			t.Fatalf("forgeKindForHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestGitLabForgeREST(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Private-Token") != "glpat" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.EscapedPath() == "/api/v4/projects/grp%2Fapp/merge_requests":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["source_branch"] != "task/hz-0001-x" || body["target_branch"] != "main" {
+				http.Error(w, "bad body", http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"web_url":"http://` + r.Host + `/grp/app/-/merge_requests/4"}`))
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/api/v4/projects/grp%2Fapp/merge_requests/4":
+			_, _ = w.Write([]byte(`{"state":"merged","merge_commit_sha":"def456","merged_at":"2026-01-02T03:04:05Z","head_pipeline":{"status":"success"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := gitlabForge{forgeBase{remote: parseRemoteURL(srv.URL + "/grp/app.git"), token: "glpat"}}
+	prURL, err := f.OpenPR(prRequest{Base: "main", Head: "task/hz-0001-x", Title: "HZ-0001: x"})
+	if err != nil {
+		t.Fatalf("open MR: %v", err)
+	}
+	if !strings.HasSuffix(prURL, "/grp/app/-/merge_requests/4") {
+		t.Fatalf("MR url = %q", prURL)
+	}
+	st, err := f.PRStatus(prURL)
+	if err != nil {
+		t.Fatalf("MR status: %v", err)
+	}
+	if st.State != prStateMerged || st.MergeSHA != "def456" || st.Checks != "passing" {
+		t.Fatalf("MR status = %+v", st)
+	}
+}
+
+func TestNoneForgeReturnsCompareURL(t *testing.T) {
+	f := noneForge{forgeBase{remote: parseRemoteURL("git@git.example.org:me/tool.git")}}
+	got, err := f.OpenPR(prRequest{Base: "main", Head: "task/hz-0002-y"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if want := "https://git.example.org/me/tool/compare/main...task/hz-0002-y"; got != want {
+		t.Fatalf("compare url = %q, want %q", got, want)
+	}
+	if got, err := (noneForge{forgeBase{}}).OpenPR(prRequest{Base: "main", Head: "b"}); err != nil || got != "" {
+		t.Fatalf("remote without web URL: %q, %v", got, err)
+	}
+}
+
+func TestOpenTaskPRWithoutWebURLRecordsBranch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	task := &BoardTask{ID: "HZ-0032", Title: "plain", Status: StatusActive, CreatedAt: now, UpdatedAt: now}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{task}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, task.ID); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	origin, repo := t.TempDir(), t.TempDir()
+	git := testGitRepo(t, repo)
+	git("init", "-q", "--bare", "-b", "main", origin)
+	git("remote", "add", "origin", origin)
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("checkout", "-q", "-b", "task/hz-0032-plain")
+
+	project := TrackedProject{Key: "app", RepoPath: repo, StorageRoot: root}
+	prURL, err := openTaskPR(project, task, Config{}, taskGitMeta{}, &prDraft{Title: "plain"})
+	if err != nil || prURL != "" {
+		t.Fatalf("open PR: %q, %v", prURL, err)
+	}
+	md, _ := readTaskMD(root, task.ID)
+	if g, _ := getTaskGitFromMD(md); g.Branch != "task/hz-0032-plain" || g.Base != "main" {
+		t.Fatalf("git meta = %+v", g)
+	}
+	git("ls-remote", "--exit-code", "--heads", "origin", "task/hz-0032-plain")
+}
+
+func TestGitLabForgeFallsBackToGlab(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1 $2\" = \"api projects/grp%2Fapp/merge_requests/4\" ] || exit 1\necho '{\"state\":\"merged\",\"merge_commit_sha\":\"def456\"}'\n"
+	if err := os.WriteFile(filepath.Join(bin, "glab"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	f := gitlabForge{forgeBase{repo: t.TempDir(), remote: parseRemoteURL("git@gitlab.example.com:grp/app.git")}}
+	st, err := f.PRStatus("https://gitlab.example.com/grp/app/-/merge_requests/4")
+	if err != nil || st.State != prStateMerged || st.MergeSHA != "def456" {
+		t.Fatalf("MR status = %+v, %v", st, err)
+	}
+	if h := f.header(); h.Get("Private-Token") != "" {
+		t.Fatalf("empty token sent: %v", h)
+	}
+}
@@ -0,0 +1,31 @@
+package hazel
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// testGitRepo makes dir a git repo on branch main with a test identity and
+// returns a helper that runs git in it and returns the trimmed output. The
+// test is skipped when git is not installed or cannot create the repo.
+func testGitRepo(t *testing.T, dir string) func(args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := runCmd(dir, nil, "git", "init", "-q", "-b", "main"); err != nil {
+		t.Skipf("git init: %v", err)
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := runCmd(dir, nil, "git", args...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return out
+	}
+	git("config", "user.email", "t@example.com")
+	git("config", "user.name", "t")
+	git("config", "commit.gpgsign", "false")
+	return git
+}
@@ -0,0 +1,145 @@
+package hazel
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook keys accepted in the project config `hooks:` map. Status hooks are
+// suffixed with the target status, e.g. `on_status:REVIEW`.
+const (
+	hookPreStatus    = "pre_status:"
+	hookOnStatus     = "on_status:"
+	hookPreRun       = "pre_run"
+	hookPostRun      = "post_run"
+	hookOnPlanAccept = "on_plan_accept"
+	hookOnMerge      = "on_merge"
+)
+
+// defaultPreHookTimeout bounds pre_* hooks, which block the status change or
+// agent run they gate; pre_hook_timeout_seconds overrides it.
+const defaultPreHookTimeout = 2 * time.Minute
+
+func hooksLogDir(root string) string { return filepath.Join(runsDir(root), "hooks") }
+
+func preHookTimeout(cfg Config) time.Duration {
+	if cfg.PreHookTimeoutSeconds > 0 {
+		return time.Duration(cfg.PreHookTimeoutSeconds) * time.Second
+	}
+	return defaultPreHookTimeout
+}
+
+// hookVetoError is returned when a pre_* hook exits non-zero.
+type hookVetoError struct {
+	Hook    string
+	Exit    int
+	LogPath string
+}
+
+func (e *hookVetoError) Error() string {
+	msg := fmt.Sprintf("%s hook exited %d", e.Hook, e.Exit)
+	if e.LogPath != "" {
+		msg += " (see " + e.LogPath + ")"
+	}
+	return msg
+}
+
+func validHookKey(key string) bool {
+	switch key {
+	case hookPreRun, hookPostRun, hookOnPlanAccept, hookOnMerge:
+		return true
+	}
+	for _, prefix := range []string{hookPreStatus, hookOnStatus} {
+		if strings.HasPrefix(key, prefix) {
+			return Status(strings.TrimPrefix(key, prefix)).Valid()
+		}
+	}
+	return false
+}
+
+func hookCommand(cfg Config, key string) string {
+	if cfg.Hooks == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Hooks[key])
+}
+
+// runHook executes the configured hook for key with the agent HAZEL_* env plus
+// HAZEL_HOOK and any extra variables. Output goes to .hazel/runs/hooks/.
+// ran is false when no hook is configured for key.
+func runHook(ctx context.Context, root string, key string, taskID string, extra map[string]string) (ran bool, exit int, logPath string, err error) {
+	cfg, _ := loadConfigOrDefault(root)
+	cmdLine := hookCommand(cfg, key)
+	if cmdLine == "" {
+		return false, 0, "", nil
+	}
+	if err := ensureDir(hooksLogDir(root)); err != nil {
+		return true, 0, "", err
+	}
+	name := strings.NewReplacer(":", "-", "/", "-").Replace(key)
+	task := taskID
+	if task == "" {
+		task = "none"
+	}
+	logPath = filepath.Join(hooksLogDir(root), fmt.Sprintf("%s_%s_%s.log", time.Now().Format("20060102T150405"), task, name))
+
+	env := append(agentEnv(root, taskID, "hook"), "HAZEL_HOOK="+key)
+	for k, v := range extra {
+		env = append(env, k+"="+v)
+	}
+	exit, err = runShellLogged(ctx, resolveRepoRoot(root), env, cmdLine, logPath)
+	return true, exit, logPath, err
+}
+
+// runPreHook runs a pre_* hook and turns a non-zero exit into a hookVetoError.
+// A hook that outlives the timeout is killed and also vetoes.
+func runPreHook(ctx context.Context, root string, key string, taskID string, extra map[string]string) error {
+	cfg, _ := loadConfigOrDefault(root)
+	timeout := preHookTimeout(cfg)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ran, exit, logPath, err := runHook(ctx, root, key, taskID, extra)
+	if !ran {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s hook timed out after %s (see %s)", key, timeout, logPath)
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", key, err)
+	}
+	if exit != 0 {
+		return &hookVetoError{Hook: key, Exit: exit, LogPath: logPath}
+	}
+	return nil
+}
+
+// fireHook runs an on_*/post_* hook in the background; its result only lands in the hook log.
+func fireHook(root string, key string, taskID string, extra map[string]string) {
+	cfg, _ := loadConfigOrDefault(root)
+	if hookCommand(cfg, key) == "" {
+		return
+	}
+	goBackground(func() {
+		_, _, _, _ = runHook(context.Background(), root, key, taskID, extra)
+	})
+}
+
+func statusHookEnv(from, to Status) map[string]string {
+	return map[string]string{
+		"HAZEL_STATUS_FROM": string(from),
+		"HAZEL_STATUS_TO":   string(to),
+	}
+}
+
+func runHookEnv(mode string, exit int, logPath string) map[string]string {
+	return map[string]string{
+		"HAZEL_RUN_MODE":      mode,
+		"HAZEL_RUN_EXIT_CODE": strconv.Itoa(exit),
+		"HAZEL_RUN_LOG":       logPath,
+	}
+}
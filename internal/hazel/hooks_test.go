@@ -0,0 +1,143 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreStatusHookVetoesTransition(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0001", Title: "hooked", Status: StatusBacklog, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0001"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	setHook := func(cmd string) {
+		cfg, _ := loadConfigOrDefault(root)
+		cfg.Hooks = map[string]string{"pre_status:READY": cmd}
+		if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+
+	setHook(`echo "$HAZEL_HOOK $HAZEL_TASK_ID $HAZEL_STATUS_FROM->$HAZEL_STATUS_TO"; exit 3`)
+	_, err := transitionTaskStatus(root, "HZ-0001", StatusReady)
+	if err == nil || !isTransitionError(err) {
+		t.Fatalf("expected veto transition error, got %v", err)
+	}
+	task, _ := findTaskInBoard(root, "HZ-0001")
+	if task.Status != StatusBacklog {
+		t.Fatalf("vetoed transition changed status to %s", task.Status)
+	}
+	ents, err := os.ReadDir(hooksLogDir(root))
+	if err != nil || len(ents) != 1 {
+		t.Fatalf("expected one hook log, got %d (%v)", len(ents), err)
+	}
+	out, _ := os.ReadFile(filepath.Join(hooksLogDir(root), ents[0].Name()))
+	if !strings.Contains(string(out), "pre_status:READY HZ-0001 BACKLOG->READY") {
+		t.Fatalf("unexpected hook output: %q", out)
+	}
+
+	setHook("true")
+	if _, err := transitionTaskStatus(root, "HZ-0001", StatusReady); err != nil {
+		t.Fatalf("transition after passing hook: %v", err)
+	}
+	task, _ = findTaskInBoard(root, "HZ-0001")
+	if task.Status != StatusReady {
+		t.Fatalf("status = %s, want READY", task.Status)
+	}
+}
+
+func TestStatusChangesFromGitActionsRunHooks(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0002", Title: "gated", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0002"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	setHooks := func(timeout int, hooks map[string]string) {
+		cfg, _ := loadConfigOrDefault(root)
+		cfg.Hooks, cfg.PreHookTimeoutSeconds = hooks, timeout
+		if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	status := func() Status {
+		task, _ := findTaskInBoard(root, "HZ-0002")
+		return task.Status
+	}
+
+	setHooks(0, map[string]string{"pre_status:ACTIVE": "exit 1"})
+	if err := bumpBoardTaskStatus(root, "HZ-0002", StatusActive); !isTransitionError(err) || status() != StatusReady {
+		t.Fatalf("veto ignored: err=%v status=%s", err, status())
+	}
+
+	setHooks(1, map[string]string{"pre_status:ACTIVE": "sleep 5"})
+	start := time.Now()
+	err := bumpBoardTaskStatus(root, "HZ-0002", StatusActive)
+	if err == nil || !strings.Contains(err.Error(), "timed out") || status() != StatusReady {
+		t.Fatalf("slow pre hook: err=%v status=%s", err, status())
+	}
+	if time.Since(start) > 4*time.Second {
+		t.Fatalf("pre hook was not killed at its deadline")
+	}
+
+	marker := filepath.Join(t.TempDir(), "on_status")
+	setHooks(0, map[string]string{"pre_status:ACTIVE": "true", "on_status:ACTIVE": "sleep 0.2; echo done > " + marker})
+	if err := bumpBoardTaskStatus(root, "HZ-0002", StatusActive); err != nil || status() != StatusActive {
+		t.Fatalf("bump: err=%v status=%s", err, status())
+	}
+	WaitBackground()
+	if !exists(marker) {
+		t.Fatal("on_status hook did not finish before WaitBackground returned")
+	}
+}
+
+func TestRunTickHonorsPreStatusReview(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0003", Title: "ran", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0003"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.AgentCommand = "true"
+	cfg.Preflight = preflightOff
+	cfg.Hooks = map[string]string{"pre_status:REVIEW": "exit 1"}
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	res, err := RunTick(context.Background(), root, RunOptions{})
+	if err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	WaitBackground()
+	if task, _ := findTaskInBoard(root, "HZ-0003"); task.Status != StatusActive {
+		t.Fatalf("status = %s, want ACTIVE after the REVIEW veto", task.Status)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "pre_status:REVIEW") {
+		t.Fatalf("warnings = %v", res.Warnings)
+	}
+}
@@ -0,0 +1,369 @@
+package hazel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GitHub import creates board tasks from a project's GitHub issues and links
+// existing task branches' PRs. Imported tasks record the issue in task.md
+// (hazel.issue), which keeps re-runs idempotent.
+
+// taskIssueMeta is the source issue of an imported task.
+type taskIssueMeta struct {
+	Number int    `yaml:"number,omitempty" json:"number,omitempty"`
+	URL    string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+type GitHubImportOptions struct {
+	Project string
+	Label   string
+	State   string // open (default), closed or all
+}
+
+type GitHubImportResult struct {
+	Created   []ImportedIssue
+	Skipped   int // issues already on the board
+	LinkedPRs []ImportedPR
+}
+
+type ImportedIssue struct {
+	TaskID string
+	Number int
+	Title  string
+	Status Status
+}
+
+type ImportedPR struct {
+	TaskID string
+	URL    string
+	Branch string
+}
+
+type ghIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url"`
+	State  string `json:"state"`
+}
+
+type ghPRListItem struct {
+	URL         string `json:"url"`
+	HeadRefName string `json:"headRefName"`
+	State       string `json:"state"`
+}
+
+// taskBranchIDRe matches the task/<id>-<slug> names built by taskBranchName.
+var taskBranchIDRe = regexp.MustCompile(`^task/(hz-\d{4,})-`)
+
+// taskIDFromBranch returns the task ID encoded in a task branch name.
+func taskIDFromBranch(branch string) (string, bool) {
+	m := taskBranchIDRe.FindStringSubmatch(strings.TrimSpace(branch))
+	if m == nil {
+		return "", false
+	}
+	return strings.ToUpper(m[1]), true
+}
+
+// ImportGitHub imports the project's GitHub issues as tasks and links open or
+// merged PRs whose head branch names a task on the board.
+func ImportGitHub(ctx context.Context, root string, opts GitHubImportOptions) (*GitHubImportResult, error) {
+	nx, err := LoadNexus(root)
+	if err != nil {
+		return nil, err
+	}
+	if nx == nil {
+		return nil, fmt.Errorf("projects_root_dir is not configured")
+	}
+	project, ok := nx.ProjectByKey(strings.TrimSpace(opts.Project))
+	if !ok {
+		return nil, fmt.Errorf("project not found: %s", opts.Project)
+	}
+	state := strings.ToLower(strings.TrimSpace(opts.State))
+	switch state {
+	case "":
+		state = "open"
+	case "open", "closed", "all":
+	default:
+		return nil, fmt.Errorf("invalid --state %q (open, closed or all)", opts.State)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	gh, ok := projectForge(project, cfg).(githubForge)
+	if !ok {
+		return nil, fmt.Errorf("project %s: origin is not a GitHub remote", project.Key)
+	}
+	var res *GitHubImportResult
+	err = withRepoLock(project.StorageRoot, func() error {
+		var ierr error
+		res, ierr = importGitHubProject(ctx, project, gh, opts.Label, state)
+		return ierr
+	})
+	return res, err
+}
+
+func (f githubForge) repoArgs() []string {
+	if f.slug != "" {
+		return []string{"--repo", f.slug}
+	}
+	if f.remote.Path != "" {
+		return []string{"--repo", f.remote.Path}
+	}
+	return nil
+}
+
+func importGitHubProject(ctx context.Context, project TrackedProject, gh githubForge, label, state string) (*GitHubImportResult, error) {
+	args := []string{"issue", "list", "--state", state, "--limit", "1000", "--json", "number,title,body,url,state"}
+	if label = strings.TrimSpace(label); label != "" {
+		args = append(args, "--label", label)
+	}
+	out, err := runCmdContext(ctx, project.RepoPath, gh.env(), "gh", append(args, gh.repoArgs()...)...)
+	if err != nil {
+		return nil, err
+	}
+	var issues []ghIssue
+	if err := json.Unmarshal([]byte(out), &issues); err != nil {
+		return nil, fmt.Errorf("parse gh issue list output: %w", err)
+	}
+	known := importedIssueURLs(project.StorageRoot)
+	res := &GitHubImportResult{}
+	// gh lists newest first; import oldest first so task IDs follow issue order.
+	for i := len(issues) - 1; i >= 0; i-- {
+		is := issues[i]
+		if known[is.URL] {
+			res.Skipped++
+			continue
+		}
+		task, err := createNewTask(project.StorageRoot, is.Title)
+		if err != nil {
+			return res, err
+		}
+		md, err := readTaskMD(project.StorageRoot, task.ID)
+		if err != nil {
+			return res, err
+		}
+		md, err = issueTaskMDWithConfig(md, is)
+		if err != nil {
+			return res, err
+		}
+		if err := writeTaskMD(project.StorageRoot, task.ID, md); err != nil {
+			return res, err
+		}
+		status := StatusBacklog
+		if strings.EqualFold(is.State, "closed") {
+			// Closed issues land in DONE without firing status hooks; this is history, not a transition.
+			status = StatusDone
+			if err := setImportedTaskStatus(project.StorageRoot, task.ID, status); err != nil {
+				return res, err
+			}
+		}
+		known[is.URL] = true
+		res.Created = append(res.Created, ImportedIssue{TaskID: task.ID, Number: is.Number, Title: is.Title, Status: status})
+	}
+
+	prs, err := linkGitHubPRs(ctx, project, gh)
+	res.LinkedPRs = prs
+	return res, err
+}
+
+// linkGitHubPRs records PRs whose head branch is a task branch on tasks that
+// have no PR yet.
+func linkGitHubPRs(ctx context.Context, project TrackedProject, gh githubForge) ([]ImportedPR, error) {
+	args := append([]string{"pr", "list", "--state", "all", "--limit", "1000", "--json", "url,headRefName,state"}, gh.repoArgs()...)
+	out, err := runCmdContext(ctx, project.RepoPath, gh.env(), "gh", args...)
+	if err != nil {
+		return nil, err
+	}
+	var prs []ghPRListItem
+	if err := json.Unmarshal([]byte(out), &prs); err != nil {
+		return nil, fmt.Errorf("parse gh pr list output: %w", err)
+	}
+	var b Board
+	if err := readYAMLFile(boardPath(project.StorageRoot), &b); err != nil {
+		return nil, err
+	}
+	onBoard := map[string]bool{}
+	for _, t := range b.Tasks {
+		onBoard[t.ID] = true
+	}
+	var linked []ImportedPR
+	for _, pr := range prs {
+		id, ok := taskIDFromBranch(pr.HeadRefName)
+		if !ok || !onBoard[id] {
+			continue
+		}
+		md, err := readTaskMD(project.StorageRoot, id)
+		if err != nil {
+			return linked, err
+		}
+		if g, _ := getTaskGitFromMD(md); strings.TrimSpace(g.PRURL) != "" {
+			continue
+		}
+		if err := saveTaskGitMeta(project, id, func(g *taskGitMeta) {
+			g.PRURL = pr.URL
+			g.PRState = strings.ToUpper(strings.TrimSpace(pr.State))
+			if strings.TrimSpace(g.Branch) == "" {
+				g.Branch = pr.HeadRefName
+			}
+		}); err != nil {
+			return linked, err
+		}
+		linked = append(linked, ImportedPR{TaskID: id, URL: pr.URL, Branch: pr.HeadRefName})
+	}
+	return linked, nil
+}
+
+// importedIssueURLs collects the issue URLs of board and archived tasks.
+func importedIssueURLs(root string) map[string]bool {
+	known := map[string]bool{}
+	for _, dir := range []string{tasksDir(root), archiveDir(root)} {
+		ents, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range ents {
+			if !e.IsDir() {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(dir, e.Name(), "task.md"))
+			if err != nil {
+				continue
+			}
+			if cfg, ok := getTaskConfig(string(b)); ok && cfg.Issue != nil && cfg.Issue.URL != "" {
+				known[cfg.Issue.URL] = true
+			}
+		}
+	}
+	return known
+}
+
+// issueTaskMDWithConfig replaces the scaffolded task.md body with the issue
+// sections, keeping the config block (color) and recording the issue.
+func issueTaskMDWithConfig(md string, is ghIssue) (string, error) {
+	fm, _, _, err := parseHazelConfigBlock(md)
+	if err != nil {
+		return "", err
+	}
+	fm.Hazel.Issue = &taskIssueMeta{Number: is.Number, URL: is.URL}
+	block, err := formatHazelConfigBlock(fm)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(issueTaskMD(is), " \t\r\n") + "\n" + block, nil
+}
+
+// setImportedTaskStatus sets the initial status of a task that was just
+// created from an issue. It is part of creating the task, not a status change,
+// so no hooks, checks or webhooks run.
+func setImportedTaskStatus(root, taskID string, status Status) error {
+	var b Board
+	if err := readYAMLFile(boardPath(root), &b); err != nil {
+		return err
+	}
+	for _, t := range b.Tasks {
+		if t.ID == taskID {
+			t.Status = status
+		}
+	}
+	return writeYAMLFile(boardPath(root), &b)
+}
+
+// issueSections maps issue body headings onto task.md sections.
+var issueSections = map[string]string{
+	"summary":             "Summary",
+	"description":         "Summary",
+	"overview":            "Summary",
+	"context":             "Context / Why",
+	"why":                 "Context / Why",
+	"background":          "Context / Why",
+	"motivation":          "Context / Why",
+	"problem":             "Context / Why",
+	"acceptance criteria": "Acceptance Criteria",
+	"acceptance":          "Acceptance Criteria",
+	"requirements":        "Acceptance Criteria",
+	"definition of done":  "Acceptance Criteria",
+	"non-goals":           "Non-Goals",
+	"non goals":           "Non-Goals",
+	"out of scope":        "Non-Goals",
+	"links":               "Links",
+	"references":          "Links",
+}
+
+var taskMDSections = []string{"Summary", "Context / Why", "Acceptance Criteria", "Non-Goals", "Links"}
+
+// issueHeadingRe matches an ATX heading; "#12 is related" is not one.
+var issueHeadingRe = regexp.MustCompile(`^#{1,6}(?:\s+(.*))?$`)
+
+// issueVerifyRe matches the "verify:" of a criterion command. Anyone who can
+// open an issue controls its body, so imported commands are rewritten to plain
+// text; a maintainer re-enables one by editing it back to "verify:".
+var issueVerifyRe = regexp.MustCompile(`(?i)\b(verify):`)
+
+func disarmIssueVerify(line string) string {
+	if !verifyRe.MatchString(line) {
+		return line
+	}
+	return issueVerifyRe.ReplaceAllString(line, "$1 (from issue, not run):")
+}
+
+// issueTaskMD renders the task.md sections from an issue body. Headed parts
+// go to the matching section; unheaded text is the summary, except that task
+// list items become acceptance criteria. Unknown headings stay under Context
+// as bold lines, so they cannot start sections of their own in task.md. Code
+// blocks are copied as they are. Verify commands are disarmed (see
+// disarmIssueVerify).
+func issueTaskMD(is ghIssue) string {
+	parts := map[string][]string{}
+	section := "Summary"
+	fence := ""
+	for _, line := range strings.Split(strings.ReplaceAll(is.Body, "\r\n", "\n"), "\n") {
+		line = disarmIssueVerify(line)
+		t := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(t, fence) {
+				fence = ""
+			}
+			parts[section] = append(parts[section], line)
+			continue
+		}
+		if strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			fence = t[:3]
+			parts[section] = append(parts[section], line)
+			continue
+		}
+		if m := issueHeadingRe.FindStringSubmatch(t); m != nil {
+			head := strings.TrimSpace(strings.TrimRight(m[1], "# "))
+			if s, ok := issueSections[strings.TrimSuffix(strings.ToLower(head), ":")]; ok {
+				section = s
+				continue
+			}
+			section = "Context / Why"
+			if head != "" {
+				parts[section] = append(parts[section], "**"+head+"**")
+			}
+			continue
+		}
+		target := section
+		if section == "Summary" && (strings.HasPrefix(t, "- [ ]") || strings.HasPrefix(t, "- [x]") || strings.HasPrefix(t, "* [ ]")) {
+			target = "Acceptance Criteria"
+		}
+		parts[target] = append(parts[target], line)
+	}
+	parts["Links"] = append(parts["Links"], "- GitHub issue #"+strconv.Itoa(is.Number)+": "+is.URL)
+
+	var b strings.Builder
+	b.WriteString("# Task\n")
+	for _, s := range taskMDSections {
+		b.WriteString("\n## " + s + "\n")
+		if body := strings.Trim(strings.Join(parts[s], "\n"), "\n"); strings.TrimSpace(body) != "" {
+			b.WriteString("\n" + body + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportGitHubProject(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1\" in\nissue) cat \"$FAKE_GH_ISSUES\" ;;\npr) cat \"$FAKE_GH_PRS\" ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "gh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	issues := `[
+  {"number":12,"title":"Second issue","body":"Fix the thing.\n\n## Out of scope\nRewrites.","url":"https://github.com/acme/app/issues/12","state":"CLOSED"},
+  {"number":11,"title":"First issue","body":"Add export.\n- [ ] CSV works\n\n## Background\nUsers asked.","url":"https://github.com/acme/app/issues/11","state":"OPEN"}
+]`
+	for name, body := range map[string]string{"issues.json": issues, "prs.json": "[]"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_GH_ISSUES", filepath.Join(bin, "issues.json"))
+	t.Setenv("FAKE_GH_PRS", filepath.Join(bin, "prs.json"))
+
+	project := TrackedProject{Key: "app", RepoPath: root, StorageRoot: root}
+	gh := githubForge{forgeBase{repo: root, slug: "acme/app"}}
+	res, err := importGitHubProject(context.Background(), project, gh, "", "all")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if len(res.Created) != 2 || res.Created[0].Number != 11 || res.Created[1].Status != StatusDone {
+		t.Fatalf("created = %+v", res.Created)
+	}
+	first := res.Created[0].TaskID
+	md, err := readTaskMD(root, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Summary\n\nAdd export.\n",
+		"## Context / Why\n\nUsers asked.\n",
+		"## Acceptance Criteria\n\n- [ ] CSV works\n",
+		"- GitHub issue #11: https://github.com/acme/app/issues/11",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("task.md missing %q:\n%s", want, md)
+		}
+	}
+	if cfg, ok := getTaskConfig(md); !ok || cfg.Issue == nil || cfg.Issue.Number != 11 || cfg.Color == "" {
+		t.Fatalf("config = %+v", cfg)
+	}
+
+	// Re-running is idempotent and links PRs on task branches.
+	prs := `[{"url":"https://github.com/acme/app/pull/3","headRefName":"` + taskBranchName(first, "First issue") + `","state":"OPEN"},
+  {"url":"https://github.com/acme/app/pull/4","headRefName":"feature/other","state":"OPEN"}]`
+	if err := os.WriteFile(filepath.Join(bin, "prs.json"), []byte(prs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err = importGitHubProject(context.Background(), project, gh, "", "all")
+	if err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if len(res.Created) != 0 || res.Skipped != 2 || len(res.LinkedPRs) != 1 || res.LinkedPRs[0].TaskID != first {
+		t.Fatalf("re-import = %+v", res)
+	}
+	md, _ = readTaskMD(root, first)
+	if g, _ := getTaskGitFromMD(md); g.PRURL != "https://github.com/acme/app/pull/3" || g.PRState != prStateOpen {
+		t.Fatalf("git meta = %+v", g)
+	}
+}
+
+func TestIssueTaskMDKeepsCodeAndDemotesHeadings(t *testing.T) {
+	body := "Crash on save.\n\n```sh\n# not a heading\n- [ ] not a criterion\n```\n\n## Steps to reproduce\n1. Save\n\n### Acceptance criteria:\n- [ ] Saves\n\n#12 is related"
+	md := issueTaskMD(ghIssue{Number: 7, URL: "https://github.com/acme/app/issues/7", Body: body})
+	for _, want := range []string{
+		"## Summary\n\nCrash on save.\n\n```sh\n# not a heading\n- [ ] not a criterion\n```\n",
+		"## Context / Why\n\n**Steps to reproduce**\n1. Save\n",
+		"## Acceptance Criteria\n\n- [ ] Saves\n\n#12 is related\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("task.md missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Count(md, "\n## ") != len(taskMDSections) {
+		t.Fatalf("issue headings became sections:\n%s", md)
+	}
+}
+
+func TestImportedVerifyCommandsNeverRun(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	marker := filepath.Join(t.TempDir(), "pwned")
+	body := "- [ ] Exports CSV verify: `touch " + marker + "`\n\n## Acceptance Criteria\n```\n- [ ] Fenced VERIFY: `touch " + marker + "`\n```"
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0001", Title: "issue", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0001"); err != nil {
+		t.Fatal(err)
+	}
+	md := issueTaskMD(ghIssue{Number: 9, URL: "https://github.com/acme/app/issues/9", Body: body})
+	if err := writeTaskMD(root, "HZ-0001", md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md, "- [ ] Exports CSV verify (from issue, not run): `touch ") {
+		t.Fatalf("verify kept armed:\n%s", md)
+	}
+	crit := parseAcceptanceCriteria(md)
+	if len(crit) != 2 || crit[0].Verify != "" || crit[1].Verify != "" {
+		t.Fatalf("criteria = %+v", crit)
+	}
+	rep, err := verifyTaskCriteria(context.Background(), root, "HZ-0001", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("imported verify command ran")
+	}
+	for _, r := range rep.Results {
+		if r.Status != criterionManual {
+			t.Fatalf("result = %+v", r)
+		}
+	}
+}
@@ -71,6 +71,13 @@ func InitRepo(ctx context.Context, root string, opt InitOptions) error {
 	templates := map[string]string{
 		filepath.Join(hazelDir(root), "templates", "task.md"): templateTaskMD,
 		filepath.Join(hazelDir(root), "templates", "impl.md"): templateImplMD,
+		prTemplatePath(root): templatePRMD,
+	}
+	for _, mode := range packetModes {
+		templates[packetTemplatePath(root, mode)] = packetTemplates[mode] + "\n"
+	}
+	if err := ensureDir(packetTemplatesDir(root)); err != nil {
+		return err
 	}
 	for p, body := range templates {
 		if !exists(p) {
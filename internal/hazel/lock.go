@@ -1,6 +1,7 @@
 package hazel
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,7 +11,12 @@ import (
 // withRepoLock enforces single-flight behavior for agent/tick operations.
 // This keeps board.yaml and the working tree from being modified concurrently.
 func withRepoLock(root string, fn func() error) error {
-	p := filepath.Join(hazelDir(root), "lock")
+	return withFileLock(filepath.Join(hazelDir(root), "lock"), fn)
+}
+
+// withFileLock runs fn while holding an exclusive flock on p, which also
+// excludes other hazel processes.
+func withFileLock(p string, fn func() error) error {
 	f, err := os.OpenFile(p, os.O_CREATE|os.O_RDWR, 0o644)
 	if err != nil {
 		return err
@@ -22,3 +28,21 @@ func withRepoLock(root string, fn func() error) error {
 	defer func() { _ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN) }()
 	return fn()
 }
+
+// tryFileLock is withFileLock without waiting: it reports false, and does not
+// run fn, when another holder has the lock.
+func tryFileLock(p string, fn func() error) (bool, error) {
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, fmt.Errorf("lock %s: %w", p, err)
+	}
+	defer func() { _ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN) }()
+	return true, fn()
+}
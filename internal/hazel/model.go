@@ -39,6 +39,8 @@ type BoardTask struct {
 	CreatedAt time.Time `yaml:"created_at"`
 	UpdatedAt time.Time `yaml:"updated_at"`
 	Deps      []string  `yaml:"deps,omitempty"`
+	// Projects lists the other nexus projects this task spans (see task_multi.go).
+	Projects []string `yaml:"projects,omitempty"`
 }
 
 func (t *BoardTask) Validate() error {
@@ -92,10 +94,43 @@ type Config struct {
 	CodexApprovalPolicy   string `yaml:"codex_approval_policy,omitempty"`
 	GitHubToken           string `yaml:"github_token,omitempty"`
 	GitBaseBranch         string `yaml:"git_base_branch,omitempty"`
+	CommitMessageTemplate string `yaml:"commit_message_template,omitempty"`
+	PreCommitCommand      string `yaml:"pre_commit_command,omitempty"`
 	EnableEnrichment      bool   `yaml:"enable_enrichment"`
 	EnableRuns            bool   `yaml:"enable_runs"`
 	UIHideDoneByDefault   bool   `yaml:"ui_hide_done_by_default"`
 	ProjectsRootDir       string `yaml:"projects_root_dir,omitempty"`
+	PRSyncIntervalSeconds int    `yaml:"pr_sync_interval_seconds,omitempty"`
+
+	// ForgeTokens maps a forge kind (github, gitlab, gitea) or host to an API
+	// token; github_token is the legacy GitHub entry and is migrated on save.
+	ForgeTokens map[string]string `yaml:"forge_tokens,omitempty"`
+	// ForgeHosts maps self-hosted remote hosts to a forge kind.
+	ForgeHosts map[string]string `yaml:"forge_hosts,omitempty"`
+
+	// Webhooks are read from a project's own .hazel/config.yaml.
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	// DeleteMergedBranches (project config) removes a task's branch locally and
+	// on origin once the task reaches DONE or is archived.
+	DeleteMergedBranches bool `yaml:"delete_merged_branches,omitempty"`
+	// Hooks maps lifecycle keys (pre_run, on_status:REVIEW, ...) to shell commands.
+	Hooks map[string]string `yaml:"hooks,omitempty"`
+	// PreHookTimeoutSeconds (project config) kills pre_* hooks after this long
+	// (default 120); see hooks.go.
+	PreHookTimeoutSeconds int `yaml:"pre_hook_timeout_seconds,omitempty"`
+	// Preflight (project config) is strict, warn (default) or off; see preflight.go.
+	Preflight string `yaml:"preflight,omitempty"`
+	// Checks (project config) are local CI commands run in the task branch; see checks.go.
+	Checks []CheckConfig `yaml:"checks,omitempty"`
+	// RequireChecksForPR refuses Open PR until the checks pass on the branch tip.
+	RequireChecksForPR bool `yaml:"require_checks_for_pr,omitempty"`
+	// Distill (project config) is off (default), extract or agent; see distill.go.
+	Distill string `yaml:"distill,omitempty"`
+	// ContextBudget (project config) caps prompt packet context in tokens; see packet_context.go.
+	ContextBudget int `yaml:"context_budget,omitempty"`
+	// WikiMirrors (project config, or nexus config for every project) copy repo
+	// docs into the wiki on sync-wiki; see wiki_mirror.go.
+	WikiMirrors []WikiMirrorRule `yaml:"wiki_mirrors,omitempty"`
 }
 
 func defaultConfig() Config {
@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -168,6 +167,7 @@ func initProjectStorageRoot(root string) error {
 	}
 	if !exists(configPath(root)) {
 		cfg := defaultConfig()
+		cfg.GitBaseBranch = "" // inherit the nexus base branch unless set here
 		if err := writeYAMLFile(configPath(root), &cfg); err != nil {
 			return err
 		}
@@ -182,7 +182,12 @@ func initProjectStorageRoot(root string) error {
 			return err
 		}
 	}
-	return nil
+	if !exists(prTemplatePath(root)) {
+		if err := writeFileAtomic(prTemplatePath(root), []byte(templatePRMD), 0o644); err != nil {
+			return err
+		}
+	}
+	return ensurePacketTemplates(root)
 }
 
 func ensureProjectWiki(p TrackedProject) error {
@@ -191,8 +196,13 @@ func ensureProjectWiki(p TrackedProject) error {
 		return err
 	}
 
+	if err := ensureWikiTemplates(p.StorageRoot); err != nil {
+		return err
+	}
+
 	readmePath := filepath.Join(wikiDir, "README.md")
-	if !exists(readmePath) {
+	fresh := !exists(readmePath)
+	if fresh {
 		readmeBody := "# " + p.Name + "\n\n"
 		readmeBody += "Local project wiki generated by Hazel Nexus.\n\n"
 		readmeBody += "- Repo path: `" + p.RepoPath + "`\n"
@@ -207,22 +217,29 @@ func ensureProjectWiki(p TrackedProject) error {
 		}
 	}
 
-	featuresBody := "# Features and Usage\n\n"
-	featuresBody += "This page is a workspace-level summary scaffold. Fill in key flows as you discover them.\n\n"
-	featuresBody += "## Core Features\n\n"
-	featuresBody += "- Document the main capabilities of this repo here.\n\n"
-	featuresBody += "## Usage Guides\n\n"
-	featuresBody += "- Add setup commands\n"
-	featuresBody += "- Add local development workflow\n"
-	featuresBody += "- Add deployment/release workflow\n"
-	featuresPath := filepath.Join(wikiDir, "FEATURES_AND_USAGE.md")
-	if !exists(featuresPath) {
-		if err := writeFileAtomic(featuresPath, []byte(featuresBody), 0o644); err != nil {
-			return err
+	// New wikis start with typed pages from the templates; existing wikis keep
+	// their pages as they are.
+	if fresh {
+		seeds := map[string]string{
+			"ARCHITECTURE.md":               strings.Replace(mustWikiTemplate(p.StorageRoot, "architecture"), "TITLE", p.Name, 1),
+			"runbooks/local-development.md": strings.Replace(mustWikiTemplate(p.StorageRoot, "runbook"), "TITLE", "Local Development", 1),
+			"GLOSSARY.md":                   mustWikiTemplate(p.StorageRoot, "glossary"),
+		}
+		for rel, body := range seeds {
+			path := filepath.Join(wikiDir, filepath.FromSlash(rel))
+			if exists(path) {
+				continue
+			}
+			if err := ensureDir(filepath.Dir(path)); err != nil {
+				return err
+			}
+			if err := writeFileAtomic(path, []byte(body), 0o644); err != nil {
+				return err
+			}
 		}
 	}
 
-	sourceReadmePath := filepath.Join(wikiDir, "SOURCE_README.md")
+	sourceReadmePath := filepath.Join(wikiDir, sourceReadmeFile)
 	sourceReadme := filepath.Join(p.RepoPath, "README.md")
 	sourceReadmeBody := "# Source README\n\n"
 	sourceReadmeBody += "Synced from `" + sourceReadme + "`.\n\n"
@@ -231,34 +248,18 @@ func ensureProjectWiki(p TrackedProject) error {
 	} else {
 		sourceReadmeBody += "No top-level README.md was found in the repository.\n"
 	}
-	if err := writeFileAtomic(sourceReadmePath, []byte(sourceReadmeBody), 0o644); err != nil {
-		return err
+	// Rewriting an unchanged copy would bump its mtime on every load, moving
+	// it to the top of the recent pages and forcing a search reindex.
+	if cur, err := os.ReadFile(sourceReadmePath); err != nil || string(cur) != sourceReadmeBody {
+		if err := writeFileAtomic(sourceReadmePath, []byte(sourceReadmeBody), 0o644); err != nil {
+			return err
+		}
 	}
 
-	changelogBody := "# Changelog\n\n"
-	changelogBody += "Recent commits (newest first).\n\n"
-	if lines, err := gitCommitChangelog(p.RepoPath, 80); err == nil {
-		changelogBody += strings.Join(lines, "\n") + "\n"
-	} else {
-		changelogBody += "Unable to read git history: " + err.Error() + "\n"
-	}
-	if err := writeFileAtomic(filepath.Join(wikiDir, "CHANGELOG.md"), []byte(changelogBody), 0o644); err != nil {
+	if err := updateChangelog(p, false); err != nil {
 		return err
 	}
-	return nil
-}
-
-func gitCommitChangelog(repo string, limit int) ([]string, error) {
-	cmd := exec.Command("git", "-C", repo, "log", fmt.Sprintf("-n%d", limit), "--date=short", "--pretty=format:* %ad | `%h` | %s (%an)")
-	b, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-	raw := strings.TrimSpace(string(b))
-	if raw == "" {
-		return []string{"- No commits found."}, nil
-	}
-	return strings.Split(raw, "\n"), nil
+	return writeWikiIndex(p.StorageRoot)
 }
 
 func (n *Nexus) ProjectByKey(key string) (TrackedProject, bool) {
@@ -270,6 +271,33 @@ func (n *Nexus) ProjectByKey(key string) (TrackedProject, bool) {
 	return TrackedProject{}, false
 }
 
+// syncProjectWiki refreshes a project's wiki, including the mirrored docs and
+// the generated pages that are too slow to rebuild on every nexus load. cfg is
+// the nexus config.
+func syncProjectWiki(p TrackedProject, cfg Config) error {
+	if err := ensureProjectWiki(p); err != nil {
+		return err
+	}
+	pcfg, _ := loadConfigOrDefault(p.StorageRoot)
+	rules := pcfg.WikiMirrors
+	if len(rules) == 0 {
+		rules = cfg.WikiMirrors
+	}
+	fg, ref := projectForge(p, cfg), projectBaseBranch(cfg, p)
+	fileURL := func(file string, raw bool) string { return fg.FileURL(ref, file, raw) }
+	if err := syncWikiMirrors(p, rules, ref, fileURL); err != nil {
+		return err
+	}
+	if err := writeCodeMap(p); err != nil {
+		return err
+	}
+	// Rerender so task titles and merge SHAs recorded since the last commit show up.
+	if err := updateChangelog(p, true); err != nil {
+		return err
+	}
+	return writeWikiIndex(p.StorageRoot)
+}
+
 func SyncWiki(root string, projectKey string) (int, error) {
 	nx, err := LoadNexus(root)
 	if err != nil {
@@ -278,12 +306,13 @@ func SyncWiki(root string, projectKey string) (int, error) {
 	if nx == nil {
 		return 0, fmt.Errorf("projects_root_dir is not configured")
 	}
+	cfg, _ := loadConfigOrDefault(root)
 	count := 0
 	for _, p := range nx.Projects {
 		if projectKey != "" && p.Key != projectKey {
 			continue
 		}
-		if err := ensureProjectWiki(p); err != nil {
+		if err := syncProjectWiki(p, cfg); err != nil {
 			return count, err
 		}
 		count++
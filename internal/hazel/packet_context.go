@@ -0,0 +1,405 @@
+package hazel
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The context assembler picks what goes into a prompt packet beyond the
+// packet's own header: the focus task's task.md and impl.md and AGENTS.md are
+// pinned, then wiki pages, related tasks and recent runs are ranked against
+// the task text (BM25 plus link/dependency boosts) and added until the token
+// budget (project config `context_budget`) is used up. The packet ends with a
+// table of the sources considered so a run can be debugged from its packet.
+
+const defaultContextBudget = 8000 // tokens
+
+// minContextScore drops ranked sources that barely match and have no boost.
+const minContextScore = 1.0
+
+// Context source kinds.
+const (
+	contextTask    = "task"
+	contextImpl    = "impl"
+	contextAgents  = "agents"
+	contextWiki    = "wiki"
+	contextRelTask = "related_task"
+	contextRun     = "run"
+)
+
+// contextSource is one candidate for a packet.
+type contextSource struct {
+	Kind      string  `json:"kind"`
+	Ref       string  `json:"ref"` // path relative to the state root, task ID or run name
+	Title     string  `json:"title"`
+	Reason    string  `json:"reason"` // pinned, linked, dependency, match, ...
+	Score     float64 `json:"score"`
+	Tokens    int     `json:"tokens"`
+	Included  bool    `json:"included"`
+	Truncated bool    `json:"truncated,omitempty"`
+	Text      string  `json:"-"`
+	boost     float64
+	rankText  string // ranked instead of Title+Text when set
+}
+
+// contextRequest describes what the packet is about. Task may be nil (chat
+// without a focus task); Query adds text such as a chat prompt to rank by.
+type contextRequest struct {
+	Task   *BoardTask
+	Query  string
+	Budget int // tokens; 0 uses the project config
+}
+
+// packetContext is the assembled selection.
+type packetContext struct {
+	Budget  int
+	Used    int
+	Sources []contextSource // pinned first, then by rank
+}
+
+// estimateTokens approximates a tokenizer at four bytes per token.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// contextBudget returns the configured budget for root.
+func contextBudget(root string) int {
+	cfg, _ := loadConfigOrDefault(root)
+	if cfg.ContextBudget > 0 {
+		return cfg.ContextBudget
+	}
+	return defaultContextBudget
+}
+
+// assemblePacketContext selects the sources for a packet.
+func assemblePacketContext(root string, req contextRequest) *packetContext {
+	pc := &packetContext{Budget: req.Budget}
+	if pc.Budget <= 0 {
+		pc.Budget = contextBudget(root)
+	}
+	repoRoot := resolveRepoRoot(root)
+
+	var pinned []contextSource
+	query := req.Query
+	if t := req.Task; t != nil {
+		taskMD, _ := readTaskMD(root, t.ID)
+		if stripped, err := stripTaskConfigForRender(taskMD); err == nil {
+			taskMD = stripped
+		}
+		implMD, _ := os.ReadFile(taskFile(root, t.ID, "impl.md"))
+		pinned = append(pinned,
+			contextSource{Kind: contextTask, Ref: rel(root, taskFile(root, t.ID, "task.md")), Title: "Task", Text: taskMD},
+			contextSource{Kind: contextImpl, Ref: rel(root, taskFile(root, t.ID, "impl.md")), Title: "Implementation Notes", Text: string(implMD)},
+		)
+		query = t.Title + "\n" + withoutHeadings(taskMD) + "\n" + query
+	}
+	if b, err := os.ReadFile(filepath.Join(repoRoot, "AGENTS.md")); err == nil {
+		pinned = append(pinned, contextSource{Kind: contextAgents, Ref: "AGENTS.md", Title: "AGENTS.md", Text: string(b)})
+	}
+	// A pinned source may use at most half the budget, so a large AGENTS.md
+	// cannot crowd out the wiki pages and runs ranked below.
+	maxPinned := pc.Budget / 2
+	for _, s := range pinned {
+		if strings.TrimSpace(s.Text) == "" {
+			continue
+		}
+		s.Reason = "pinned"
+		pc.add(s, min(pc.Budget-pc.Used, maxPinned))
+	}
+
+	cands := contextCandidates(root, req.Task)
+	rankContextSources(cands, query)
+	// A single ranked source may use at most a third of the budget.
+	maxEach := pc.Budget / 3
+	skipped := 0
+	for _, s := range cands {
+		if s.Score < minContextScore {
+			continue
+		}
+		left := pc.Budget - pc.Used
+		if left < 200 {
+			// Only the best few left-outs are worth listing.
+			if skipped++; skipped <= 10 {
+				s.Reason += "; over budget"
+				pc.Sources = append(pc.Sources, s)
+			}
+			continue
+		}
+		pc.add(s, min(left, maxEach))
+	}
+	return pc
+}
+
+// add includes s, truncated to at most limit tokens.
+func (pc *packetContext) add(s contextSource, limit int) {
+	s.Text = strings.TrimSpace(s.Text)
+	if limit <= 0 {
+		s.Reason += "; over budget"
+		pc.Sources = append(pc.Sources, s)
+		return
+	}
+	if estimateTokens(s.Text) > limit {
+		s.Text, s.Truncated = truncateToTokens(s.Text, limit), true
+	}
+	s.Tokens = estimateTokens(s.Text)
+	s.Included = true
+	pc.Used += s.Tokens
+	pc.Sources = append(pc.Sources, s)
+}
+
+// truncateToTokens cuts s at a line boundary so it fits in n tokens.
+func truncateToTokens(s string, n int) string {
+	const marker = "\n\n[...truncated by Hazel...]"
+	max := n*4 - len(marker)
+	if max <= 0 {
+		return ""
+	}
+	if len(s) <= max {
+		return s
+	}
+	cut := s[:max]
+	if i := strings.LastIndexByte(cut, '\n'); i > max/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \t\n") + marker
+}
+
+// contextCandidates lists the rankable sources with their boosts: wiki pages,
+// related board tasks and recent runs.
+func contextCandidates(root string, focus *BoardTask) []contextSource {
+	var out []contextSource
+	linkedPages := map[string]bool{}
+	linkedTasks := map[string]bool{}
+	focusID := ""
+	if focus != nil {
+		focusID = focus.ID
+		md, _ := readTaskMD(root, focus.ID)
+		impl, _ := os.ReadFile(taskFile(root, focus.ID, "impl.md"))
+		wl := loadWikiLinks(root, "")
+		for _, target := range markdownWikiLinks([]byte(md + "\n" + string(impl))) {
+			if lt, ok := wl.resolve(target); ok {
+				if lt.Kind == wikiLinkTask {
+					linkedTasks[lt.TaskID] = true
+				} else {
+					linkedPages[lt.Rel] = true
+				}
+			}
+		}
+	}
+
+	for _, p := range loadWikiPages(root) {
+		b, err := os.ReadFile(filepath.Join(projectWikiDir(root), filepath.FromSlash(p.Rel)))
+		if err != nil || strings.TrimSpace(string(b)) == "" {
+			continue
+		}
+		s := contextSource{Kind: contextWiki, Ref: "wiki/" + p.Rel, Title: "Wiki: " + p.Title, Reason: "match", Text: string(b)}
+		switch {
+		case linkedPages[p.Rel]:
+			s.Reason, s.boost = "linked from task", 10
+		case focusID != "" && p.Meta.SourceTask == focusID:
+			s.Reason, s.boost = "source_task", 6
+		case p.Rel == "README.md":
+			s.Reason, s.boost = "overview", 0.5
+		}
+		out = append(out, s)
+	}
+
+	var b Board
+	if err := readYAMLFile(boardPath(root), &b); err == nil {
+		var done []*BoardTask
+		for _, t := range b.Tasks {
+			if t.Status == StatusDone && t.ID != focusID {
+				done = append(done, t)
+			}
+		}
+		sort.Slice(done, func(i, j int) bool { return done[i].UpdatedAt.After(done[j].UpdatedAt) })
+		recent := map[string]bool{}
+		for i, t := range done {
+			if i == 5 {
+				break
+			}
+			recent[t.ID] = true
+		}
+		for _, t := range b.Tasks {
+			if t.ID == focusID {
+				continue
+			}
+			s := contextSource{Kind: contextRelTask, Ref: t.ID, Title: fmt.Sprintf("Task %s: %s (%s)", t.ID, t.Title, t.Status), Reason: "match"}
+			switch {
+			case focus != nil && containsFold(focus.Deps, t.ID):
+				s.Reason, s.boost = "dependency", 8
+			case linkedTasks[t.ID]:
+				s.Reason, s.boost = "linked from task", 8
+			case focusID != "" && containsFold(t.Deps, focusID):
+				s.Reason, s.boost = "dependent", 4
+			case recent[t.ID]:
+				s.Reason, s.boost = "recent DONE", 1
+			}
+			md, _ := readTaskMD(root, t.ID)
+			if stripped, err := stripTaskConfigForRender(md); err == nil {
+				md = stripped
+			}
+			s.Text = md
+			s.rankText = t.Title + "\n" + withoutHeadings(md)
+			out = append(out, s)
+		}
+	}
+
+	if runs, err := listRunRecords(root, ""); err == nil {
+		for i, r := range runs {
+			if i == 20 {
+				break
+			}
+			last, _ := r.JSONSummary["last_text"].(string)
+			if strings.TrimSpace(last) == "" {
+				continue
+			}
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Task %s, mode %s", r.TaskID, r.Mode)
+			if r.ExitCode != nil {
+				fmt.Fprintf(&sb, ", exit %d", *r.ExitCode)
+			}
+			sb.WriteString("\n\n" + last + "\n")
+			if r.Changes != nil && r.Changes.Stat != "" {
+				sb.WriteString("\n" + r.Changes.Stat + "\n")
+			}
+			s := contextSource{Kind: contextRun, Ref: r.Name, Title: "Run " + r.Name, Reason: "match", Text: sb.String(), rankText: last}
+			if focusID != "" && r.TaskID == focusID {
+				s.Reason, s.boost = "this task", 5
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// withoutHeadings drops markdown heading lines, which in task.md are the
+// shared template sections rather than content.
+func withoutHeadings(md string) string {
+	var out []string
+	for _, line := range strings.Split(md, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+var contextStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "has": true, "have": true, "how": true,
+	"in": true, "is": true, "it": true, "of": true, "on": true, "or": true, "see": true, "should": true,
+	"that": true, "the": true, "this": true, "to": true, "we": true, "what": true, "when": true,
+	"which": true, "with": true, "you": true,
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(strings.TrimSpace(x), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rankContextSources scores cands against query with BM25 over the candidate
+// set, scaled to 0-10 so the link and dependency boosts stay comparable, and
+// sorts them best first. Terms in most candidates (template headings) are
+// ignored.
+func rankContextSources(cands []contextSource, query string) {
+	var terms []string
+	for _, t := range dedupeStrings(searchTokens(query)) {
+		if !contextStopwords[t] {
+			terms = append(terms, t)
+		}
+	}
+	const k1, b = 1.2, 0.75
+	tfs := make([]map[string]int, len(cands))
+	lens := make([]int, len(cands))
+	df := map[string]int{}
+	avgLen := 0.0
+	for i, c := range cands {
+		tfs[i] = map[string]int{}
+		src := c.rankText
+		if src == "" {
+			src = c.Title + "\n" + c.Text
+		}
+		toks := searchTokens(src)
+		lens[i] = len(toks)
+		avgLen += float64(len(toks))
+		for _, t := range toks {
+			if tfs[i][t] == 0 {
+				df[t]++
+			}
+			tfs[i][t]++
+		}
+	}
+	n := float64(len(cands))
+	if n > 0 {
+		avgLen /= n
+	}
+	raw := make([]float64, len(cands))
+	top := 0.0
+	for i := range cands {
+		for _, t := range terms {
+			f := float64(tfs[i][t])
+			if f == 0 {
+				continue
+			}
+			d := float64(df[t])
+			if n >= 4 && d > n/2 {
+				continue
+			}
+			idf := math.Log(1 + (n-d+0.5)/(d+0.5))
+			raw[i] += idf * f * (k1 + 1) / (f + k1*(1-b+b*float64(lens[i])/math.Max(avgLen, 1)))
+		}
+		top = math.Max(top, raw[i])
+	}
+	for i := range cands {
+		norm := 0.0
+		if top > 0 {
+			norm = 10 * raw[i] / top
+		}
+		cands[i].Score = math.Round((norm+cands[i].boost)*100) / 100
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].Score > cands[j].Score })
+}
+
+// writeMarkdown renders the included sources, each as a fenced section.
+func (pc *packetContext) writeMarkdown(sb *strings.Builder) {
+	for _, s := range pc.Sources {
+		if !s.Included {
+			continue
+		}
+		fence := "```markdown"
+		if s.Kind == contextRun {
+			fence = "```text"
+		}
+		fmt.Fprintf(sb, "\n## %s\n\n%s\n%s\n```\n", s.Title, fence, s.Text)
+	}
+}
+
+// writeSources renders the table of sources considered for the packet.
+func (pc *packetContext) writeSources(sb *strings.Builder) {
+	fmt.Fprintf(sb, "\n## Context Sources\n\nBudget: %d of %d tokens.\n\n", pc.Used, pc.Budget)
+	sb.WriteString("| Source | Kind | Reason | Score | Tokens |\n| --- | --- | --- | --- | --- |\n")
+	for _, s := range pc.Sources {
+		ref := "`" + s.Ref + "`"
+		tokens := "-"
+		if s.Included {
+			tokens = fmt.Sprint(s.Tokens)
+			if s.Truncated {
+				tokens += " (truncated)"
+			}
+		}
+		score := "-"
+		if s.Reason != "pinned" {
+			score = fmt.Sprintf("%.2f", s.Score)
+		}
+		fmt.Fprintf(sb, "| %s | %s | %s | %s | %s |\n", ref, s.Kind, s.Reason, score, tokens)
+	}
+}
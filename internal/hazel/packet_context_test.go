@@ -0,0 +1,129 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssemblePacketContext(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	focus := &BoardTask{ID: "HZ-0010", Title: "Rate limit webhook deliveries", Status: StatusActive, CreatedAt: now, UpdatedAt: now, Deps: []string{"HZ-0005"}}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{
+		focus,
+		{ID: "HZ-0005", Title: "Queue outgoing events", Status: StatusDone, CreatedAt: now, UpdatedAt: now},
+		{ID: "HZ-0007", Title: "Dark mode palette", Status: StatusBacklog, CreatedAt: now, UpdatedAt: now},
+	}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	write := func(path, body string) {
+		t.Helper()
+		if err := ensureDir(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(taskFile(root, "HZ-0010", "task.md"), "# Task\n\n## Summary\n\nThrottle webhook deliveries per endpoint with a token bucket. See [[Glossary]].\n")
+	write(taskFile(root, "HZ-0005", "task.md"), "# Task\n\n## Summary\n\nPersist events before sending.\n")
+	write(taskFile(root, "HZ-0007", "task.md"), "# Task\n\n## Summary\n\nNew palette for the board.\n")
+	wiki := projectWikiDir(root)
+	write(filepath.Join(wiki, "webhooks.md"), "# Webhooks\n\nWebhook deliveries retry with backoff; each endpoint gets its own token bucket.\n")
+	write(filepath.Join(wiki, "palette.md"), "# Palette\n\nPastel card colors for the board.\n")
+	write(filepath.Join(wiki, "glossary.md"), "# Glossary\n\nTerms.\n")
+	write(filepath.Join(wiki, "big.md"), "# Webhook Internals\n\n"+strings.Repeat("webhook delivery endpoint throttle\n", 400))
+	logPath := filepath.Join(runsDir(root), "20260101T000000_HZ-0010.log")
+	write(logPath, "log\n")
+	_ = writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{
+		"task_id": "HZ-0010", "mode": "implement", "exit_code": 1, "log_path": logPath,
+		"json_summary": map[string]any{"last_text": "Bucket refill races with the retry timer."},
+	}), 0o644)
+
+	pc := assemblePacketContext(root, contextRequest{Task: focus, Budget: 2000})
+	got := map[string]contextSource{}
+	for _, s := range pc.Sources {
+		got[s.Ref] = s
+	}
+	for ref, reason := range map[string]string{
+		".hazel/tasks/HZ-0010/task.md": "pinned",
+		"wiki/webhooks.md":             "match",
+		"wiki/glossary.md":             "linked from task",
+		"HZ-0005":                      "dependency",
+		"20260101T000000_HZ-0010":      "this task",
+	} {
+		if s := got[ref]; !s.Included || s.Reason != reason {
+			t.Fatalf("%s = %+v", ref, s)
+		}
+	}
+	if _, ok := got["wiki/palette.md"]; ok {
+		t.Fatalf("unrelated page considered: %+v", got["wiki/palette.md"])
+	}
+	if _, ok := got["HZ-0007"]; ok {
+		t.Fatalf("unrelated task considered: %+v", got["HZ-0007"])
+	}
+	if big := got["wiki/big.md"]; !big.Included || !big.Truncated || big.Tokens > pc.Budget/3 {
+		t.Fatalf("big page = %+v", big)
+	}
+	if pc.Used > pc.Budget {
+		t.Fatalf("used %d of %d", pc.Used, pc.Budget)
+	}
+
+	body, err := buildPromptPacket(root, focus, "implement", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Wiki: Webhooks", "## Task HZ-0005: Queue outgoing events (DONE)", "Bucket refill races", "## Context Sources", "| `wiki/glossary.md` | wiki | linked from task |"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("packet missing %q:\n%s", want, body)
+		}
+	}
+	if chat := buildChatPromptPacket(root, "", "what colors does the board palette use?", now); !strings.Contains(chat, "## Wiki: Palette") {
+		t.Fatalf("chat packet did not rank by prompt:\n%s", chat)
+	}
+}
+
+func TestPinnedContextLeavesRoomForRankedSources(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	focus := &BoardTask{ID: "HZ-0011", Title: "Rotate webhook secrets", Status: StatusActive, CreatedAt: now, UpdatedAt: now}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{focus}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, focus.ID); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	if err := ensureDir(projectWikiDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	for path, body := range map[string]string{
+		filepath.Join(root, "AGENTS.md"):                  "# Agents\n\n" + strings.Repeat("Always run the full test suite before committing.\n", 2000),
+		filepath.Join(projectWikiDir(root), "secrets.md"): "# Webhook Secrets\n\nRotate webhook secrets by adding the new one first.\n",
+		taskFile(root, focus.ID, "task.md"):               "# Task\n\n## Summary\n\nRotate webhook secrets without dropping deliveries.\n",
+	} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pc := assemblePacketContext(root, contextRequest{Task: focus, Budget: 1000})
+	got := map[string]contextSource{}
+	for _, s := range pc.Sources {
+		got[s.Ref] = s
+	}
+	if a := got["AGENTS.md"]; !a.Included || !a.Truncated || a.Tokens > pc.Budget/2 {
+		t.Fatalf("AGENTS.md = %+v", a)
+	}
+	if w := got["wiki/secrets.md"]; !w.Included {
+		t.Fatalf("wiki page crowded out: %+v", w)
+	}
+}
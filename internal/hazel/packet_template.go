@@ -0,0 +1,297 @@
+package hazel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Prompt packets are rendered from Go text/template files in
+// .hazel/templates/packets/<mode>.md (plan, implement, chat, review, distill).
+// Projects without a file use the built-in default for that mode.
+
+var packetModes = []string{"plan", "implement", "chat", "review", "distill"}
+
+func packetTemplatesDir(root string) string {
+	return filepath.Join(hazelDir(root), "templates", "packets")
+}
+
+func packetTemplatePath(root, mode string) string {
+	return filepath.Join(packetTemplatesDir(root), mode+".md")
+}
+
+// packetTemplateData is what packet templates can reference.
+type packetTemplateData struct {
+	Mode      string
+	Generated string // RFC3339
+	StateRoot string
+	RepoRoot  string
+	Task      *BoardTask // nil for chat without a focus task
+	TaskMD    string     // task.md without the HAZEL-CONFIG block
+	ImplMD    string
+	Git       taskGitMeta
+	Board     []*BoardTask
+	Wiki      []wikiPage  // every wiki page with .Rel, .Title and .Meta
+	Runs      []runRecord // the task's agent runs, newest first
+	Prompt    string      // the chat message
+	// Context is the ranked context as markdown sections, Sources the table
+	// of what was considered and ContextSources the same as data.
+	Context        string
+	Sources        string
+	ContextSources []contextSource
+	Extra          string // mode-specific material (distill: final run, chat, existing page, draft)
+}
+
+var packetTemplateFuncs = template.FuncMap{
+	"code": func(v any) string { return "`" + fmt.Sprint(v) + "`" },
+	"fence": func(lang string, s string) string {
+		return "```" + lang + "\n" + strings.TrimSpace(s) + "\n```"
+	},
+	"clip":    clipped,
+	"section": markdownSectionText,
+}
+
+const packetTemplateHeader = `# Hazel Prompt Packet
+
+- Mode: {{code .Mode}}
+- Generated: {{code .Generated}}
+- Task: {{code .Task.ID}}
+- Title: {{.Task.Title}}
+- Status: {{code .Task.Status}}
+- State root: {{code .StateRoot}}
+- Repo root: {{code .RepoRoot}}
+
+## Instructions
+
+- Follow AGENTS.md constraints.
+- Do not edit human intent in task.md unless explicitly requested.
+`
+
+var packetTemplates = map[string]string{
+	"implement": packetTemplateHeader + `- Keep implementation notes in impl.md.
+{{.Context}}{{.Sources}}`,
+	"plan": packetTemplateHeader + `- In plan mode, write the proposal to plan.md (temporary, pending accept/decline).
+{{.Context}}{{.Sources}}`,
+	"review": packetTemplateHeader + `- In review mode, do not change code. Review the changes on {{if .Git.Branch}}{{code .Git.Branch}}{{else}}the task branch{{end}}{{with .Git.Base}} against {{code .}}{{end}} for the acceptance criteria in task.md.
+- Report findings ordered by severity, each with the file and line it concerns.
+{{- with .Git.PRURL}}
+- Pull request: {{.}}
+{{- end}}
+{{- if .Runs}}
+
+## Agent Runs
+{{range .Runs}}
+- {{code .Name}} {{.Mode}}{{if .ExitCode}} (exit {{.ExitCode}}){{end}}{{with .Changes}}{{with .Files}}: {{len .}} files changed{{end}}{{end}}{{end}}
+{{- end}}
+{{.Context}}{{.Sources}}`,
+	"distill": packetTemplateHeader + `- In distill mode, do not change code. Write a wiki page for this finished task to distill.md in the task directory (temporary, pending accept/decline).
+- Cover the decisions made and why, gotchas worth remembering, and the touched areas of the codebase. Link the task as [[{{.Task.ID}}]].
+- Start the page with frontmatter: {{code "type: note"}}, {{code "tags: [distilled]"}}, {{code (printf "source_task: %s" .Task.ID)}}.
+{{.Context}}{{.Extra}}{{.Sources}}`,
+	"chat": `# Hazel Chat Packet
+
+- Generated: {{code .Generated}}
+- State root: {{code .StateRoot}}
+- Repo root: {{code .RepoRoot}}
+{{- with .Task}}
+- Focus task: {{code .ID}}
+- Title: {{.Title}}
+- Status: {{code .Status}}
+{{- end}}
+
+## User Prompt
+
+{{fence "text" (clip .Prompt 8000)}}
+{{.Context}}{{.Sources}}`,
+}
+
+// ensurePacketTemplates writes the built-in packet templates that are missing.
+func ensurePacketTemplates(root string) error {
+	if err := ensureDir(packetTemplatesDir(root)); err != nil {
+		return err
+	}
+	for _, mode := range packetModes {
+		p := packetTemplatePath(root, mode)
+		if exists(p) {
+			continue
+		}
+		if err := writeFileAtomic(p, []byte(packetTemplates[mode]+"\n"), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildPacketTemplateData gathers the template data for a packet; t may be
+// nil for chat.
+func buildPacketTemplateData(root string, t *BoardTask, mode, prompt string, now time.Time) packetTemplateData {
+	d := packetTemplateData{
+		Mode:      mode,
+		Generated: now.Format(time.RFC3339),
+		StateRoot: root,
+		RepoRoot:  resolveRepoRoot(root),
+		Task:      t,
+		Prompt:    prompt,
+		Wiki:      loadWikiPages(root),
+	}
+	var b Board
+	if err := readYAMLFile(boardPath(root), &b); err == nil {
+		d.Board = b.Tasks
+	}
+	if t != nil {
+		md, _ := readTaskMD(root, t.ID)
+		d.Git, _ = getTaskGitFromMD(md)
+		if stripped, err := stripTaskConfigForRender(md); err == nil {
+			md = stripped
+		}
+		d.TaskMD = md
+		impl, _ := os.ReadFile(taskFile(root, t.ID, "impl.md"))
+		d.ImplMD = string(impl)
+		d.Runs, _ = listRunRecords(root, t.ID)
+	}
+	pc := assemblePacketContext(root, contextRequest{Task: t, Query: prompt})
+	var ctx, src strings.Builder
+	pc.writeMarkdown(&ctx)
+	pc.writeSources(&src)
+	d.Context, d.Sources, d.ContextSources = ctx.String(), src.String(), pc.Sources
+	if mode == "distill" && t != nil {
+		d.Extra = distillPacketSources(root, t, now)
+	}
+	return d
+}
+
+// packetTemplateSource returns the project's template for mode, falling back
+// to the built-in one.
+func packetTemplateSource(root, mode string) (string, error) {
+	src, ok := packetTemplates[mode]
+	if !ok {
+		return "", fmt.Errorf("unknown packet mode %q (want one of %s)", mode, strings.Join(packetModes, ", "))
+	}
+	if b, err := os.ReadFile(packetTemplatePath(root, mode)); err == nil && strings.TrimSpace(string(b)) != "" {
+		src = string(b)
+	}
+	return src, nil
+}
+
+func executePacketTemplate(mode, src string, d packetTemplateData) (string, error) {
+	name := "templates/packets/" + mode + ".md"
+	tpl, err := template.New(name).Funcs(packetTemplateFuncs).Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimRight(buf.String(), " \t\n") + "\n", nil
+}
+
+// renderPacket renders the packet for mode with the project's template.
+func renderPacket(root, mode string, d packetTemplateData) (string, error) {
+	src, err := packetTemplateSource(root, mode)
+	if err != nil {
+		return "", err
+	}
+	return executePacketTemplate(mode, src, d)
+}
+
+// PacketOptions selects what `hazel packet render` previews.
+type PacketOptions struct {
+	Project string
+	TaskID  string // optional for chat
+	Mode    string
+	Prompt  string // chat message
+}
+
+// RenderPacket renders a packet without writing it or running anything.
+func RenderPacket(root string, opt PacketOptions) (string, error) {
+	if key := strings.TrimSpace(opt.Project); key != "" {
+		nx, err := LoadNexus(root)
+		if err != nil {
+			return "", err
+		}
+		if nx == nil {
+			return "", fmt.Errorf("projects_root_dir is not configured")
+		}
+		project, ok := nx.ProjectByKey(key)
+		if !ok {
+			return "", fmt.Errorf("project not found: %s", key)
+		}
+		root = project.StorageRoot
+	}
+	mode := strings.ToLower(strings.TrimSpace(opt.Mode))
+	if mode == "" {
+		mode = "implement"
+	}
+	if _, err := packetTemplateSource(root, mode); err != nil {
+		return "", err
+	}
+	var t *BoardTask
+	if id := strings.TrimSpace(opt.TaskID); id != "" {
+		var err error
+		if t, err = findTaskInBoard(root, id); err != nil {
+			return "", err
+		}
+	} else if mode != "chat" {
+		return "", fmt.Errorf("a task is required for %s packets", mode)
+	}
+	if mode == "chat" {
+		id := ""
+		if t != nil {
+			id = t.ID
+		}
+		return buildChatPromptPacket(root, id, opt.Prompt, time.Now()), nil
+	}
+	return buildPromptPacket(root, t, mode, time.Now())
+}
+
+// validatePacketTemplates parses each template in templates/packets and
+// renders it against sample data. Files for unknown modes are warnings.
+func validatePacketTemplates(root string) (problems, warnings []string) {
+	ents, err := os.ReadDir(packetTemplatesDir(root))
+	if err != nil {
+		return nil, nil
+	}
+	now := time.Now()
+	sample := packetTemplateData{
+		Generated: now.Format(time.RFC3339),
+		StateRoot: root,
+		RepoRoot:  resolveRepoRoot(root),
+		Task:      &BoardTask{ID: "HZ-0000", Title: "Example task", Status: StatusActive, CreatedAt: now, UpdatedAt: now},
+		TaskMD:    "# Task\n\n## Summary\n\nExample.\n",
+		Git:       taskGitMeta{Branch: "task/hz-0000-example-task", Base: "main"},
+		Prompt:    "Example prompt",
+	}
+	sample.Board = []*BoardTask{sample.Task}
+	zero := 0
+	sample.Runs = []runRecord{{Name: "20060102T150405_HZ-0000", TaskID: "HZ-0000", Mode: "implement", ExitCode: &zero, Changes: &runChanges{Files: []runChangedFile{{Path: "README.md"}}}}}
+	var names []string
+	for _, e := range ents {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		mode := strings.TrimSuffix(name, ".md")
+		if _, ok := packetTemplates[mode]; !ok {
+			warnings = append(warnings, fmt.Sprintf("templates/packets/%s: unknown packet mode (want one of %s)", name, strings.Join(packetModes, ", ")))
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(packetTemplatesDir(root), name))
+		if err != nil || strings.TrimSpace(string(b)) == "" {
+			continue
+		}
+		d := sample
+		d.Mode = mode
+		if _, err := executePacketTemplate(mode, string(b), d); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems, warnings
+}
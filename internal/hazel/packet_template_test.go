@@ -0,0 +1,72 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPacketTemplates(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	for _, mode := range packetModes {
+		if !exists(packetTemplatePath(root, mode)) {
+			t.Fatalf("init did not write templates/packets/%s.md", mode)
+		}
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0020", Title: "Paginate search", Status: StatusActive, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0020"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	if err := writeTaskMD(root, "HZ-0020", "# Task\n\n## Summary\n\nAdd cursors to search results.\n\n## Acceptance Criteria\n\n- Next page link\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The built-in review template renders without a project override.
+	_ = os.Remove(packetTemplatePath(root, "review"))
+	body, err := RenderPacket(root, PacketOptions{TaskID: "HZ-0020", Mode: "review"})
+	if err != nil || !strings.Contains(body, "- Mode: `review`") || !strings.Contains(body, "do not change code") {
+		t.Fatalf("review = %q, %v", body, err)
+	}
+
+	custom := "# {{.Task.ID}} for {{.Mode}}\n\n{{section .TaskMD \"Acceptance Criteria\"}}\n{{len .Board}} tasks on the board\n"
+	if err := os.WriteFile(packetTemplatePath(root, "implement"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	body, err = RenderPacket(root, PacketOptions{TaskID: "HZ-0020"})
+	if err != nil || body != "# HZ-0020 for implement\n\n- Next page link\n1 tasks on the board\n" {
+		t.Fatalf("implement = %q, %v", body, err)
+	}
+	if _, err := RenderPacket(root, PacketOptions{TaskID: "HZ-0020", Mode: "deploy"}); err == nil {
+		t.Fatal("unknown mode rendered")
+	}
+
+	// Doctor reports broken templates; chat falls back to the built-in one.
+	if err := os.WriteFile(packetTemplatePath(root, "chat"), []byte("{{.Nope}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(packetTemplatePath(root, "notes"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Doctor(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Problems) != 1 || !strings.Contains(r.Problems[0], "templates/packets/chat.md") {
+		t.Fatalf("problems = %v", r.Problems)
+	}
+	if !slices.ContainsFunc(r.Warnings, func(w string) bool { return strings.HasPrefix(w, "templates/packets/notes.md: unknown packet mode") }) {
+		t.Fatalf("warnings = %v", r.Warnings)
+	}
+	if chat := buildChatPromptPacket(root, "HZ-0020", "hello", now); !strings.Contains(chat, "# Hazel Chat Packet") || !strings.Contains(chat, "- Focus task: `HZ-0020`") {
+		t.Fatalf("chat fallback:\n%s", chat)
+	}
+}
@@ -3,6 +3,7 @@ package hazel
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -62,6 +63,10 @@ func planLocked(ctx context.Context, root string, taskID string) (*RunResult, er
 		return nil, err
 	}
 
+	if err := runPreHook(ctx, root, hookPreRun, taskID, map[string]string{"HAZEL_RUN_MODE": "plan"}); err != nil {
+		return nil, fmt.Errorf("%s: %w", taskID, err)
+	}
+
 	lp, _ := computeRunLogPath(root, cfg, now, taskID)
 	_ = writeRunState(root, &RunState{
 		Running:   true,
@@ -70,14 +75,26 @@ func planLocked(ctx context.Context, root string, taskID string) (*RunResult, er
 		LogPath:   lp,
 		StartedAt: now,
 	})
+	_ = emitWebhookEvent(root, webhookEventRunStarted, map[string]any{
+		"task_id":    taskID,
+		"mode":       "plan",
+		"started_at": now,
+		"log_path":   lp,
+	})
 
+	before, track := beginRunChanges(root)
 	exit, logPath, err := runAgentCommandMode(ctx, root, cfg, taskID, now, "plan", lp)
 	if err != nil {
 		return nil, err
 	}
+	var changes *runChanges
+	if track {
+		changes = finishRunChanges(root, before, logPath)
+	}
 	if logPath != "" {
 		jsonSummary := summarizeJSONEventsFromLog(logPath)
 		_ = writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{
+			"changes":      changes,
 			"task_id":      taskID,
 			"mode":         "plan",
 			"started_at":   now,
@@ -96,6 +113,22 @@ func planLocked(ctx context.Context, root string, taskID string) (*RunResult, er
 		EndedAt:   time.Now(),
 		ExitCode:  &exit,
 	})
+	_ = emitWebhookEvent(root, webhookEventRunFinished, map[string]any{
+		"task_id":    taskID,
+		"mode":       "plan",
+		"started_at": now,
+		"ended_at":   time.Now(),
+		"exit_code":  exit,
+		"log_path":   logPath,
+	})
+	fireHook(root, hookPostRun, taskID, runHookEnv("plan", exit, logPath))
+	if proposal, err := readPlanProposal(root, taskID); err == nil && strings.TrimSpace(proposal) != "" {
+		_ = emitWebhookEvent(root, webhookEventPlanReady, map[string]any{
+			"task_id":   taskID,
+			"title":     t.Title,
+			"plan_path": planProposalPath(root, taskID),
+		})
+	}
 	return &RunResult{
 		DispatchedTaskID: taskID,
 		AgentExitCode:    &exit,
@@ -0,0 +1,360 @@
+package hazel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PR states recorded in taskGitMeta.PRState.
+const (
+	prStateOpen   = "OPEN"
+	prStateClosed = "CLOSED"
+	prStateMerged = "MERGED"
+)
+
+const defaultPRSyncInterval = 5 * time.Minute
+
+// prStatus is what the reconciler learns about one task PR.
+type prStatus struct {
+	State          string
+	ReviewDecision string
+	Checks         string // passing, failing, pending or "" when the PR has none
+	MergeSHA       string
+	MergedAt       string
+}
+
+// PRSyncChange describes one task whose PR state moved during a sync.
+type PRSyncChange struct {
+	ProjectKey string
+	TaskID     string
+	Repo       string // linked project key when the PR belongs to a linked repo
+	PRURL      string
+	State      string
+	Review     string
+	Checks     string
+	MergedSHA  string
+	MovedDone  bool
+	Flagged    bool
+}
+
+type PRSyncResult struct {
+	Checked int
+	Changes []PRSyncChange
+	Errors  []string
+}
+
+type ghPRView struct {
+	State          string `json:"state"`
+	MergedAt       string `json:"mergedAt"`
+	ReviewDecision string `json:"reviewDecision"`
+	MergeCommit    *struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+	StatusCheckRollup []ghCheck `json:"statusCheckRollup"`
+}
+
+// ghCheck is one statusCheckRollup entry: CheckRuns carry status/conclusion,
+// StatusContexts carry state.
+type ghCheck struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+func summarizePRChecks(v ghPRView) string {
+	if len(v.StatusCheckRollup) == 0 {
+		return ""
+	}
+	pending := false
+	for _, c := range v.StatusCheckRollup {
+		outcome := strings.ToUpper(strings.TrimSpace(c.Conclusion))
+		if outcome == "" {
+			outcome = strings.ToUpper(strings.TrimSpace(c.State))
+		}
+		switch outcome {
+		case "FAILURE", "ERROR", "CANCELLED", "TIMED_OUT", "ACTION_REQUIRED", "STARTUP_FAILURE":
+			return "failing"
+		case "SUCCESS", "NEUTRAL", "SKIPPED":
+		default:
+			pending = true
+		}
+		if s := strings.ToUpper(strings.TrimSpace(c.Status)); s != "" && s != "COMPLETED" {
+			pending = true
+		}
+	}
+	if pending {
+		return "pending"
+	}
+	return "passing"
+}
+
+// localMergeStatus detects a merge without the forge: the task's last commit is
+// reachable from origin/<base>. Squash merges are invisible to this check.
+func localMergeStatus(project TrackedProject, meta taskGitMeta, cfg Config) (prStatus, bool) {
+	last := strings.TrimSpace(meta.LastCommit)
+	if last == "" {
+		return prStatus{}, false
+	}
+	base := strings.TrimSpace(meta.Base)
+	if base == "" {
+		base = projectBaseBranch(cfg, project)
+	}
+	_, _ = runCmd(project.RepoPath, nil, "git", "fetch", "-q", "origin", base)
+	remote := "origin/" + base
+	if _, err := runCmd(project.RepoPath, nil, "git", "merge-base", "--is-ancestor", last, remote); err != nil {
+		return prStatus{}, false
+	}
+	// The oldest commit on the ancestry path is the merge commit when it is a
+	// merge that brought last in through its second parent. After a
+	// fast-forward or rebase merge it is an unrelated later commit, and last
+	// itself is what landed.
+	sha := last
+	if out, err := runCmd(project.RepoPath, nil, "git", "rev-list", "--ancestry-path", "--reverse", "--parents", last+".."+remote); err == nil {
+		first, _, _ := strings.Cut(out, "\n")
+		if f := strings.Fields(first); len(f) >= 3 {
+			if _, err := runCmd(project.RepoPath, nil, "git", "merge-base", "--is-ancestor", last, f[2]); err == nil {
+				sha = f[0]
+			}
+		}
+	}
+	return prStatus{State: prStateMerged, MergeSHA: sha}, true
+}
+
+// syncProjectPRs reconciles every task with a PR URL that is not yet merged,
+// including the PRs of linked projects (resolved through nx when set).
+func syncProjectPRs(ctx context.Context, nx *Nexus, project TrackedProject, cfg Config, res *PRSyncResult) {
+	var b Board
+	if err := readYAMLFile(boardPath(project.StorageRoot), &b); err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", project.Key, err))
+		return
+	}
+	for _, t := range b.Tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		md, err := readTaskMD(project.StorageRoot, t.ID)
+		if err != nil {
+			continue
+		}
+		meta, _ := getTaskGitFromMD(md)
+		if len(t.Projects) > 0 && nx != nil {
+			syncLinkedPRs(nx, project, t, getTaskReposFromMD(md), cfg, res)
+		}
+		if strings.TrimSpace(meta.PRURL) == "" || strings.TrimSpace(meta.MergeSHA) != "" {
+			continue
+		}
+		res.Checked++
+		st, err := projectForge(project, cfg).PRStatus(meta.PRURL)
+		if err != nil {
+			local, ok := localMergeStatus(project, meta, cfg)
+			if !ok {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s: %v", project.Key, t.ID, err))
+				continue
+			}
+			st = local
+		}
+		if st.State == prStateMerged && st.MergeSHA == "" {
+			if local, ok := localMergeStatus(project, meta, cfg); ok {
+				st.MergeSHA = local.MergeSHA
+			}
+		}
+		change, err := applyPRStatus(project, t, meta, st)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s %s: %v", project.Key, t.ID, err))
+			continue
+		}
+		if change != nil {
+			res.Changes = append(res.Changes, *change)
+		}
+	}
+}
+
+// applyPRStatus records st in the task's git meta. It only writes when
+// something changed so a quiet sync never touches board.yaml.
+func applyPRStatus(project TrackedProject, t *BoardTask, meta taskGitMeta, st prStatus) (*PRSyncChange, error) {
+	if st.State == meta.PRState && st.ReviewDecision == meta.PRReview && st.Checks == meta.PRChecks && st.State != prStateMerged {
+		return nil, nil
+	}
+	change := &PRSyncChange{
+		ProjectKey: project.Key,
+		TaskID:     t.ID,
+		PRURL:      meta.PRURL,
+		State:      st.State,
+		Review:     st.ReviewDecision,
+		Checks:     st.Checks,
+	}
+	if err := saveTaskGitMeta(project, t.ID, func(g *taskGitMeta) {
+		g.PRState = st.State
+		g.PRReview = st.ReviewDecision
+		g.PRChecks = st.Checks
+	}); err != nil {
+		return nil, err
+	}
+	switch st.State {
+	case prStateMerged:
+		if st.MergeSHA == "" {
+			return change, fmt.Errorf("PR merged but merge commit is unknown; use Mark Merged")
+		}
+		if err := markTaskMerged(project, t, st.MergeSHA); err != nil {
+			return change, err
+		}
+		if st.MergedAt != "" {
+			_ = saveTaskGitMeta(project, t.ID, func(g *taskGitMeta) { g.MergedAt = st.MergedAt })
+		}
+		change.MergedSHA = st.MergeSHA
+		moved, err := completeIfAllMerged(project, t)
+		if err != nil {
+			return change, err
+		}
+		change.MovedDone = moved
+	case prStateClosed:
+		change.Flagged = true
+		if meta.PRState != prStateClosed {
+			_ = emitWebhookEvent(project.StorageRoot, webhookEventPRClosed, map[string]any{
+				"task_id": t.ID,
+				"title":   t.Title,
+				"pr_url":  meta.PRURL,
+			})
+		}
+	}
+	return change, nil
+}
+
+// syncLinkedPRs reconciles the PRs a multi-project task opened in its linked
+// repos. Their state lives in the home task.md; once the last one merges (and
+// the home PR already has) the task moves to DONE.
+func syncLinkedPRs(nx *Nexus, home TrackedProject, t *BoardTask, repos map[string]taskGitMeta, cfg Config, res *PRSyncResult) {
+	for _, key := range t.Projects {
+		g := repos[key]
+		if strings.TrimSpace(g.PRURL) == "" || strings.TrimSpace(g.MergeSHA) != "" {
+			continue
+		}
+		p, ok := nx.ProjectByKey(key)
+		if !ok {
+			continue
+		}
+		res.Checked++
+		st, err := projectForge(p, cfg).PRStatus(g.PRURL)
+		if err != nil {
+			local, ok := localMergeStatus(p, g, cfg)
+			if !ok {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s [%s]: %v", home.Key, t.ID, key, err))
+				continue
+			}
+			st = local
+		}
+		if st.State == prStateMerged && st.MergeSHA == "" {
+			if local, ok := localMergeStatus(p, g, cfg); ok {
+				st.MergeSHA = local.MergeSHA
+			}
+		}
+		if st.State == g.PRState && st.ReviewDecision == g.PRReview && st.Checks == g.PRChecks && st.State != prStateMerged {
+			continue
+		}
+		change := PRSyncChange{ProjectKey: home.Key, TaskID: t.ID, Repo: key, PRURL: g.PRURL, State: st.State, Review: st.ReviewDecision, Checks: st.Checks}
+		if err := saveTaskRepoGitMeta(home, t.ID, key, func(m *taskGitMeta) {
+			m.PRState = st.State
+			m.PRReview = st.ReviewDecision
+			m.PRChecks = st.Checks
+		}); err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("%s %s [%s]: %v", home.Key, t.ID, key, err))
+			continue
+		}
+		switch st.State {
+		case prStateMerged:
+			if st.MergeSHA == "" {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s [%s]: PR merged but merge commit is unknown; use Mark Merged", home.Key, t.ID, key))
+				continue
+			}
+			if err := markLinkedMerged(home, t, key, st.MergeSHA); err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s [%s]: %v", home.Key, t.ID, key, err))
+				continue
+			}
+			change.MergedSHA = st.MergeSHA
+			moved, err := completeIfAllMerged(home, t)
+			if err != nil {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s: %v", home.Key, t.ID, err))
+			}
+			change.MovedDone = moved
+		case prStateClosed:
+			change.Flagged = true
+			if g.PRState != prStateClosed {
+				_ = emitWebhookEvent(home.StorageRoot, webhookEventPRClosed, map[string]any{
+					"task_id": t.ID,
+					"title":   t.Title,
+					"pr_url":  g.PRURL,
+					"project": key,
+				})
+			}
+		}
+		res.Changes = append(res.Changes, change)
+	}
+}
+
+// SyncPRs runs one reconciliation pass over the tracked projects (all when projectKey is empty).
+func SyncPRs(ctx context.Context, root string, projectKey string) (*PRSyncResult, error) {
+	nx, err := LoadNexus(root)
+	if err != nil {
+		return nil, err
+	}
+	if nx == nil {
+		return nil, fmt.Errorf("projects_root_dir is not configured")
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	res := &PRSyncResult{}
+	found := false
+	for _, p := range nx.Projects {
+		if projectKey != "" && p.Key != projectKey {
+			continue
+		}
+		found = true
+		// PR flows mutate task.md/board.yaml; serialize with agent runs.
+		_ = withRepoLock(p.StorageRoot, func() error {
+			syncProjectPRs(ctx, nx, p, cfg, res)
+			return nil
+		})
+	}
+	if projectKey != "" && !found {
+		return nil, fmt.Errorf("project not found: %s", projectKey)
+	}
+	return res, nil
+}
+
+func prSyncInterval(cfg Config) time.Duration {
+	switch {
+	case cfg.PRSyncIntervalSeconds < 0:
+		return 0
+	case cfg.PRSyncIntervalSeconds == 0:
+		return defaultPRSyncInterval
+	case cfg.PRSyncIntervalSeconds < 30:
+		return 30 * time.Second
+	default:
+		return time.Duration(cfg.PRSyncIntervalSeconds) * time.Second
+	}
+}
+
+// prSyncLoop reconciles PR state in the background while the UI server runs.
+func prSyncLoop(ctx context.Context, root string) {
+	for {
+		// Re-read config each tick so UI/CLI changes take effect without restart.
+		cfg, _ := loadConfigOrDefault(root)
+		interval := prSyncInterval(cfg)
+		wait := interval
+		if wait == 0 {
+			wait = time.Minute
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if interval == 0 {
+			continue
+		}
+		_, _ = SyncPRs(ctx, root, "")
+	}
+}
@@ -0,0 +1,144 @@
+package hazel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeGH puts a `gh` on PATH that prints the contents of $FAKE_GH_OUT.
+func fakeGH(t *testing.T, out string) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncat \"$FAKE_GH_OUT\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "gh"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake gh: %v", err)
+	}
+	outPath := filepath.Join(bin, "out.json")
+	if err := os.WriteFile(outPath, []byte(out), 0o644); err != nil {
+		t.Fatalf("write fake gh output: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_GH_OUT", outPath)
+}
+
+func TestSyncProjectPRs(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0001", Title: "synced", Status: StatusReview, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0001"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	repo := t.TempDir()
+	testGitRepo(t, repo)("remote", "add", "origin", "https://github.com/acme/app.git")
+	project := TrackedProject{Key: "app", RepoPath: repo, StorageRoot: root}
+	if err := saveTaskGitMeta(project, "HZ-0001", func(g *taskGitMeta) {
+		g.PRURL = "https://github.com/acme/app/pull/7"
+	}); err != nil {
+		t.Fatalf("save git meta: %v", err)
+	}
+	gitMeta := func() taskGitMeta {
+		md, _ := readTaskMD(root, "HZ-0001")
+		g, _ := getTaskGitFromMD(md)
+		return g
+	}
+
+	fakeGH(t, `{"state":"OPEN","reviewDecision":"APPROVED","statusCheckRollup":[{"status":"COMPLETED","conclusion":"SUCCESS"},{"status":"IN_PROGRESS","conclusion":""}]}`)
+	res := &PRSyncResult{}
+	syncProjectPRs(context.Background(), nil, project, Config{}, res)
+	if len(res.Errors) != 0 || len(res.Changes) != 1 {
+		t.Fatalf("open sync: changes=%d errors=%v", len(res.Changes), res.Errors)
+	}
+	if g := gitMeta(); g.PRState != prStateOpen || g.PRReview != "APPROVED" || g.PRChecks != "pending" {
+		t.Fatalf("open meta = %+v", g)
+	}
+
+	// Same state again is a no-op.
+	res = &PRSyncResult{}
+	syncProjectPRs(context.Background(), nil, project, Config{}, res)
+	if len(res.Changes) != 0 {
+		t.Fatalf("unchanged sync reported %d changes", len(res.Changes))
+	}
+
+	fakeGH(t, `{"state":"MERGED","mergedAt":"2026-01-02T03:04:05Z","mergeCommit":{"oid":"abc123"},"reviewDecision":"APPROVED","statusCheckRollup":[]}`)
+	res = &PRSyncResult{}
+	syncProjectPRs(context.Background(), nil, project, Config{}, res)
+	if len(res.Errors) != 0 || len(res.Changes) != 1 || !res.Changes[0].MovedDone {
+		t.Fatalf("merged sync: changes=%+v errors=%v", res.Changes, res.Errors)
+	}
+	g := gitMeta()
+	if g.MergeSHA != "abc123" || g.MergedAt != "2026-01-02T03:04:05Z" || g.PRState != prStateMerged {
+		t.Fatalf("merged meta = %+v", g)
+	}
+	task, _ := findTaskInBoard(root, "HZ-0001")
+	if task.Status != StatusDone {
+		t.Fatalf("status = %s, want DONE", task.Status)
+	}
+}
+
+func TestSummarizePRChecks(t *testing.T) {
+	var v ghPRView
+	if got := summarizePRChecks(v); got != "" {
+		t.Fatalf("no checks = %q", got)
+	}
+	v.StatusCheckRollup = []ghCheck{{State: "SUCCESS"}, {Status: "COMPLETED", Conclusion: "FAILURE"}}
+	if got := summarizePRChecks(v); got != "failing" {
+		t.Fatalf("failing checks = %q", got)
+	}
+}
+
+func TestLocalMergeStatus(t *testing.T) {
+	origin := t.TempDir()
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	git("init", "-q", "--bare", "-b", "main", origin)
+	git("remote", "add", "origin", origin)
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	branchTip := func(name string) string {
+		git("checkout", "-q", "-b", name, "main")
+		git("commit", "-q", "--allow-empty", "-m", name)
+		tip := git("rev-parse", "HEAD")
+		git("checkout", "-q", "main")
+		return tip
+	}
+	project := TrackedProject{Key: "app", RepoPath: repo}
+	status := func(last string) string {
+		t.Helper()
+		st, ok := localMergeStatus(project, taskGitMeta{LastCommit: last, Base: "main"}, Config{})
+		if !ok || st.State != prStateMerged {
+			t.Fatalf("%s not detected as merged: %+v", last, st)
+		}
+		return st.MergeSHA
+	}
+
+	// Fast-forward, then an unrelated commit on top: the task commit itself landed.
+	ff := branchTip("task/hz-0001-ff")
+	git("merge", "-q", "--ff-only", "task/hz-0001-ff")
+	git("commit", "-q", "--allow-empty", "-m", "unrelated")
+	git("push", "-q", "origin", "main")
+	if got := status(ff); got != ff {
+		t.Fatalf("fast-forward merge sha = %s, want the task commit %s", got, ff)
+	}
+
+	// A real merge commit is reported as the merge.
+	merged := branchTip("task/hz-0002-merge")
+	git("merge", "-q", "--no-ff", "-m", "merge", "task/hz-0002-merge")
+	mergeSHA := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "later")
+	git("push", "-q", "origin", "main")
+	if got := status(merged); got != mergeSHA {
+		t.Fatalf("merge sha = %s, want %s", got, mergeSHA)
+	}
+
+	if _, ok := localMergeStatus(project, taskGitMeta{LastCommit: branchTip("task/hz-0003-open"), Base: "main"}, Config{}); ok {
+		t.Fatal("unmerged branch reported as merged")
+	}
+}
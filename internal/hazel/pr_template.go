@@ -0,0 +1,145 @@
+package hazel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templatePRMD is the default PR template, written to .hazel/templates/pr.md.
+// The first "# " heading of the rendered output becomes the PR title; the rest is the body.
+const templatePRMD = `# {{.Task.ID}}: {{.Task.Title}}
+
+{{- if .Summary}}
+
+## Summary
+
+{{.Summary}}
+{{- end}}
+{{- if .Why}}
+
+## Why
+
+{{.Why}}
+{{- end}}
+{{- if .Criteria}}
+
+## Acceptance Criteria
+{{range .Criteria}}
+- [{{if .Checked}}x{{else}} {{end}}] {{.ID}}: {{.Text}}{{if .Optional}} (optional){{end}}{{with index $.CriteriaStatus .ID}} — {{.}}{{end}}{{end}}
+{{- end}}
+{{- if .Checklist}}
+
+## Implementation Checklist
+{{range .Checklist}}
+- [{{if .Checked}}x{{else}} {{end}}] {{.Text}}{{end}}
+{{- end}}
+{{- if .Runs}}
+
+## Agent Runs
+{{range .Runs}}
+- ` + "`{{.Name}}`" + ` {{.Mode}}{{if .ExitCode}} (exit {{.ExitCode}}){{end}}{{if .Changes}}{{if .Changes.Stat}}: {{.Changes.Stat}}{{end}}{{end}}{{end}}
+{{- end}}
+{{- if .Diffstat}}
+
+## Diffstat
+
+` + "```" + `
+{{.Diffstat}}
+` + "```" + `
+{{- end}}
+
+Hazel task: {{.Task.ID}} ({{.Branch}} → {{.Base}})
+`
+
+func prTemplatePath(root string) string {
+	return filepath.Join(hazelDir(root), "templates", "pr.md")
+}
+
+// prTemplateData is what pr.md templates can reference.
+type prTemplateData struct {
+	Task           *BoardTask
+	Branch         string
+	Base           string
+	Summary        string
+	Why            string
+	Criteria       []acceptanceCriterion
+	CriteriaStatus map[string]string // AC-n -> pass/fail/manual from the last verification
+	Checklist      []implChecklistItem
+	Runs           []runRecord
+	Diffstat       string
+}
+
+type prDraft struct {
+	Title string
+	Body  string
+}
+
+func markdownSectionText(md, title string) string {
+	return strings.TrimSpace(strings.Join(markdownSection(md, title), "\n"))
+}
+
+func buildPRTemplateData(project TrackedProject, task *BoardTask, branch, base string) prTemplateData {
+	root := project.StorageRoot
+	d := prTemplateData{Task: task, Branch: branch, Base: base, CriteriaStatus: map[string]string{}}
+	if md, err := readTaskMD(root, task.ID); err == nil {
+		if _, _, without, err := parseHazelConfigBlock(md); err == nil {
+			md = without
+		}
+		d.Summary = markdownSectionText(md, "Summary")
+		d.Why = markdownSectionText(md, "Context / Why")
+		d.Criteria = parseAcceptanceCriteria(md)
+	}
+	if rep, err := readCriteriaReport(root, task.ID); err == nil {
+		for _, r := range rep.Results {
+			d.CriteriaStatus[r.ID] = r.Status
+		}
+	}
+	if b, err := os.ReadFile(taskFile(root, task.ID, "impl.md")); err == nil {
+		d.Checklist = parseImplChecklist(string(b))
+	}
+	if recs, err := listRunRecords(root, task.ID); err == nil {
+		d.Runs = recs
+	}
+	for _, ref := range []string{"origin/" + base, base} {
+		if out, err := runCmd(project.RepoPath, nil, "git", "diff", "--stat", ref+"..."+branch); err == nil {
+			d.Diffstat = out
+			break
+		}
+	}
+	return d
+}
+
+// renderPRDraft renders the project's pr.md template (or the built-in default)
+// into a PR title and body.
+func renderPRDraft(project TrackedProject, task *BoardTask, branch, base string) (prDraft, error) {
+	src := templatePRMD
+	if b, err := os.ReadFile(prTemplatePath(project.StorageRoot)); err == nil && strings.TrimSpace(string(b)) != "" {
+		src = string(b)
+	}
+	tpl, err := template.New("pr.md").Parse(src)
+	if err != nil {
+		return prDraft{}, fmt.Errorf("templates/pr.md: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, buildPRTemplateData(project, task, branch, base)); err != nil {
+		return prDraft{}, fmt.Errorf("templates/pr.md: %w", err)
+	}
+	return splitPRDraft(buf.String(), task), nil
+}
+
+func splitPRDraft(out string, task *BoardTask) prDraft {
+	d := prDraft{Title: task.ID + ": " + task.Title}
+	lines := strings.Split(strings.TrimLeft(out, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "# ") {
+		if t := strings.TrimSpace(strings.TrimPrefix(lines[0], "# ")); t != "" {
+			d.Title = t
+		}
+		lines = lines[1:]
+	}
+	d.Body = strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+	return d
+}
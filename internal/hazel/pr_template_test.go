@@ -0,0 +1,64 @@
+package hazel
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderPRDraftDefaultTemplate(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	task := &BoardTask{ID: "HZ-0003", Title: "Rate limit login", Status: StatusActive, CreatedAt: now, UpdatedAt: now}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{task}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, task.ID); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	md := strings.Replace(templateTaskMD, "## Summary\n", "## Summary\n\nThrottle repeated failed logins.\n", 1)
+	md = strings.Replace(md, "## Acceptance Criteria\n", "## Acceptance Criteria\n\n- [x] Lockout after 5 failures\n- [ ] Docs updated (optional)\n", 1)
+	if err := writeTaskMD(root, task.ID, md); err != nil {
+		t.Fatalf("write task.md: %v", err)
+	}
+	impl := templateImplMD + "\n- [x] Add limiter middleware (AC-1)\n"
+	if err := os.WriteFile(taskFile(root, task.ID, "impl.md"), []byte(impl), 0o644); err != nil {
+		t.Fatalf("write impl.md: %v", err)
+	}
+
+	project := TrackedProject{Key: "app", RepoPath: t.TempDir(), StorageRoot: root}
+	d, err := renderPRDraft(project, task, "task/hz-0003-rate-limit-login", "main")
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if d.Title != "HZ-0003: Rate limit login" {
+		t.Fatalf("title = %q", d.Title)
+	}
+	for _, want := range []string{
+		"## Summary\n\nThrottle repeated failed logins.",
+		"- [x] AC-1: Lockout after 5 failures",
+		"- [ ] AC-2: Docs updated (optional)",
+		"- [x] Add limiter middleware (AC-1)",
+		"Hazel task: HZ-0003 (task/hz-0003-rate-limit-login → main)",
+	} {
+		if !strings.Contains(d.Body, want) {
+			t.Fatalf("body missing %q:\n%s", want, d.Body)
+		}
+	}
+
+	// A project override replaces the default, including the title line.
+	if err := os.WriteFile(prTemplatePath(root), []byte("# feat: {{.Task.Title}}\n\n{{.Summary}}\n"), 0o644); err != nil {
+		t.Fatalf("write override: %v", err)
+	}
+	d, err = renderPRDraft(project, task, "b", "main")
+	if err != nil {
+		t.Fatalf("render override: %v", err)
+	}
+	if d.Title != "feat: Rate limit login" || d.Body != "Throttle repeated failed logins.\n" {
+		t.Fatalf("override draft = %+v", d)
+	}
+}
@@ -0,0 +1,157 @@
+package hazel
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Preflight modes (project config `preflight`). warn is the default: `hazel run`
+// reports problems and carries on; strict refuses to dispatch; off skips the checks.
+const (
+	preflightStrict = "strict"
+	preflightWarn   = "warn"
+	preflightOff    = "off"
+)
+
+// Preflight problem codes.
+const (
+	preflightNotRepo      = "not_a_repo"
+	preflightDirty        = "dirty"
+	preflightDetached     = "detached_head"
+	preflightMerging      = "merge_in_progress"
+	preflightRebasing     = "rebase_in_progress"
+	preflightNoRemote     = "no_remote"
+	preflightBaseUnpushed = "base_unpushed"
+)
+
+// preflightProblem is one reason a repo is not safe for a git or agent action.
+type preflightProblem struct {
+	Repo    string `json:"repo"` // project key (or path outside nexus mode)
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Stashable problems are cleared by "stash and continue".
+	Stashable bool `json:"stashable,omitempty"`
+}
+
+// preflightError is returned when preflight is strict and problems remain.
+type preflightError struct {
+	Problems []preflightProblem
+}
+
+func (e *preflightError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Repo+": "+p.Message)
+	}
+	return "preflight failed: " + strings.Join(msgs, "; ")
+}
+
+func preflightMode(cfg Config) string {
+	switch m := strings.ToLower(strings.TrimSpace(cfg.Preflight)); m {
+	case preflightStrict, preflightOff:
+		return m
+	default:
+		return preflightWarn
+	}
+}
+
+func preflightLabel(p TrackedProject) string {
+	if p.Key != "" {
+		return p.Key
+	}
+	return p.RepoPath
+}
+
+// checkRepoPreflight inspects repo without changing it. base is the branch
+// task branches are cut from.
+func checkRepoPreflight(p TrackedProject, base string) []preflightProblem {
+	repo := p.RepoPath
+	label := preflightLabel(p)
+	var out []preflightProblem
+	add := func(code, msg string, stashable bool) {
+		out = append(out, preflightProblem{Repo: label, Code: code, Message: msg, Stashable: stashable})
+	}
+	if _, err := runCmd(repo, nil, "git", "rev-parse", "--git-dir"); err != nil {
+		add(preflightNotRepo, "not a git repository", false)
+		return out
+	}
+	gitPath := func(name string) bool {
+		pth, err := runCmd(repo, nil, "git", "rev-parse", "--path-format=absolute", "--git-path", name)
+		if err != nil {
+			return false
+		}
+		_, err = os.Stat(pth)
+		return err == nil
+	}
+	switch {
+	case gitPath("rebase-merge"), gitPath("rebase-apply"):
+		add(preflightRebasing, "a rebase is in progress; finish it or run `git rebase --abort`", false)
+	case gitPath("MERGE_HEAD"), gitPath("CHERRY_PICK_HEAD"):
+		add(preflightMerging, "a merge or cherry-pick is in progress; finish it or abort it", false)
+	}
+	if _, err := runCmd(repo, nil, "git", "symbolic-ref", "-q", "HEAD"); err != nil {
+		add(preflightDetached, "HEAD is detached; check out a branch", false)
+	}
+	if dirty, _ := runCmd(repo, nil, "git", "status", "--porcelain", "--untracked-files=no"); dirty != "" {
+		n := len(strings.Split(dirty, "\n"))
+		add(preflightDirty, fmt.Sprintf("working tree has %d uncommitted change(s)", n), true)
+	}
+	if _, err := runCmd(repo, nil, "git", "remote", "get-url", "origin"); err != nil {
+		add(preflightNoRemote, "no `origin` remote; branches cannot be pushed", false)
+		return out
+	}
+	if base = strings.TrimSpace(base); base != "" && localBranchExists(repo, base) {
+		if out2, err := runCmd(repo, nil, "git", "rev-list", "--count", "refs/remotes/origin/"+base+".."+base); err == nil && out2 != "0" {
+			add(preflightBaseUnpushed, fmt.Sprintf("local %s has %s commit(s) not on origin/%s", base, out2, base), false)
+		}
+	}
+	return out
+}
+
+func checkPreflight(repos []TrackedProject, base string) []preflightProblem {
+	var out []preflightProblem
+	for _, p := range repos {
+		out = append(out, checkRepoPreflight(p, base)...)
+	}
+	return out
+}
+
+// stashForPreflight stashes tracked changes so a checkout or agent run starts clean.
+func stashForPreflight(p TrackedProject, reason string) error {
+	_, err := runCmd(p.RepoPath, nil, "git", "stash", "push", "-m", "hazel preflight: "+reason)
+	return err
+}
+
+// resolvePreflight applies the user's choice from the preflight page and
+// returns the problems that still block. choice is "" (just check), "stash"
+// (stash dirty repos, then re-check) or "continue" (proceed despite problems;
+// refused in strict mode).
+func resolvePreflight(mode string, repos []TrackedProject, base, choice, reason string) ([]preflightProblem, error) {
+	if mode == preflightOff {
+		return nil, nil
+	}
+	problems := checkPreflight(repos, base)
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	switch choice {
+	case "stash":
+		for _, p := range repos {
+			for _, pr := range problems {
+				if pr.Repo == preflightLabel(p) && pr.Stashable {
+					if err := stashForPreflight(p, reason); err != nil {
+						return problems, err
+					}
+					break
+				}
+			}
+		}
+		problems = checkPreflight(repos, base)
+	case "continue":
+		if mode != preflightStrict {
+			return nil, nil
+		}
+	}
+	return problems, nil
+}
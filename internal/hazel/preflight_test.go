@@ -0,0 +1,99 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckRepoPreflight(t *testing.T) {
+	remote := t.TempDir()
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	codes := func(ps []preflightProblem) map[string]bool {
+		m := map[string]bool{}
+		for _, p := range ps {
+			m[p.Code] = true
+		}
+		return m
+	}
+	git("init", "-q", "--bare", "-b", "main", remote)
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	p := TrackedProject{Key: "app", RepoPath: repo}
+
+	if got := codes(checkRepoPreflight(p, "main")); !got[preflightNoRemote] || len(got) != 1 {
+		t.Fatalf("no remote: %v", got)
+	}
+	git("remote", "add", "origin", remote)
+	git("push", "-q", "-u", "origin", "main")
+	if got := checkRepoPreflight(p, "main"); len(got) != 0 {
+		t.Fatalf("clean repo: %+v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-q", "-am", "local only")
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("c\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := checkRepoPreflight(p, "main")
+	if c := codes(got); !c[preflightDirty] || !c[preflightBaseUnpushed] {
+		t.Fatalf("dirty + unpushed: %+v", got)
+	}
+
+	// Strict refuses "continue"; "stash" clears only the stashable problem.
+	left, err := resolvePreflight(preflightStrict, []TrackedProject{p}, "main", "continue", "test")
+	if err != nil || len(left) != 2 {
+		t.Fatalf("strict continue: %+v, %v", left, err)
+	}
+	left, err = resolvePreflight(preflightStrict, []TrackedProject{p}, "main", "stash", "test")
+	if c := codes(left); err != nil || c[preflightDirty] || !c[preflightBaseUnpushed] {
+		t.Fatalf("after stash: %+v, %v", left, err)
+	}
+	if left, _ := resolvePreflight(preflightWarn, []TrackedProject{p}, "main", "continue", "test"); len(left) != 0 {
+		t.Fatalf("warn continue: %+v", left)
+	}
+
+	git("checkout", "-q", "--detach")
+	if c := codes(checkRepoPreflight(p, "main")); !c[preflightDetached] {
+		t.Fatalf("detached: %v", c)
+	}
+}
+
+func TestPreflightTargetsShareTheBaseBranch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.ProjectsRootDir, cfg.GitBaseBranch = "projects", "trunk"
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(root, "projects", "app")
+	if err := ensureDir(repo); err != nil {
+		t.Fatal(err)
+	}
+	testGitRepo(t, repo)
+	nx, err := LoadNexus(root)
+	if err != nil || len(nx.Projects) != 1 {
+		t.Fatalf("load nexus: %+v, %v", nx, err)
+	}
+	p := nx.Projects[0]
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(p.StorageRoot), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0001", Title: "t", Status: StatusReady, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []string{"run", "start"} {
+		_, base, _, err := preflightTargets(root, nx, p.StorageRoot, p.Key, "HZ-0001", action)
+		if err != nil || base != "trunk" {
+			t.Fatalf("%s base = %q, %v", action, base, err)
+		}
+	}
+}
@@ -20,9 +20,9 @@ func hazelPoweredByURL() string {
 }
 
 func readRepoSlugFromGitConfig(root string) string {
-	// Best-effort: parse .git/config for an origin URL like:
-	//   https://github.com/flip-z/hazel.git
-	//   git@github.com:flip-z/hazel.git
+	// Best-effort: parse .git/config for the origin URL and return its repo path:
+	//   https://github.com/flip-z/hazel.git      -> flip-z/hazel
+	//   git@gitlab.example.com:group/sub/app.git -> group/sub/app
 	b, err := os.ReadFile(filepath.Join(root, ".git", "config"))
 	if err != nil {
 		return ""
@@ -32,38 +32,13 @@ func readRepoSlugFromGitConfig(root string) string {
 	if i < 0 {
 		return ""
 	}
-	s = s[i:]
-	// Find url = ...
-	for _, line := range strings.Split(s, "\n") {
+	for _, line := range strings.Split(s[i:], "\n")[1:] {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "url = ") {
-			u := strings.TrimSpace(strings.TrimPrefix(line, "url = "))
-			u = strings.TrimSuffix(u, ".git")
-			// https://github.com/OWNER/REPO
-			if strings.Contains(u, "github.com/") {
-				parts := strings.Split(u, "github.com/")
-				if len(parts) >= 2 {
-					path := parts[len(parts)-1]
-					path = strings.TrimPrefix(path, ":")
-					path = strings.TrimPrefix(path, "/")
-					segs := strings.Split(path, "/")
-					if len(segs) >= 2 {
-						return segs[0] + "/" + segs[1]
-					}
-				}
-			}
-			// git@github.com:OWNER/REPO
-			if strings.Contains(u, "github.com:") {
-				parts := strings.Split(u, "github.com:")
-				if len(parts) >= 2 {
-					path := parts[len(parts)-1]
-					path = strings.TrimPrefix(path, "/")
-					segs := strings.Split(path, "/")
-					if len(segs) >= 2 {
-						return segs[0] + "/" + segs[1]
-					}
-				}
-			}
+		if strings.HasPrefix(line, "[") {
+			break
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == "url" {
+			return parseRemoteURL(strings.TrimSpace(v)).Path
 		}
 	}
 	return ""
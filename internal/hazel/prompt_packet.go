@@ -22,58 +22,40 @@ func writePromptPacket(root string, t *BoardTask, mode string, now time.Time) (s
 }
 
 func buildPromptPacket(root string, t *BoardTask, mode string, now time.Time) (string, error) {
-	repoRoot := resolveRepoRoot(root)
-	taskMD, _ := readTaskMD(root, t.ID)
-	if stripped, err := stripTaskConfigForRender(taskMD); err == nil {
-		taskMD = stripped
+	tplMode := mode
+	if _, ok := packetTemplates[tplMode]; !ok || tplMode == "chat" {
+		tplMode = "implement"
 	}
-	implMD, _ := os.ReadFile(taskFile(root, t.ID, "impl.md"))
-
-	agentsMD, _ := os.ReadFile(filepath.Join(repoRoot, "AGENTS.md"))
-	readmeMD, _ := os.ReadFile(filepath.Join(root, "wiki", "SOURCE_README.md"))
-	featuresMD, _ := os.ReadFile(filepath.Join(root, "wiki", "FEATURES_AND_USAGE.md"))
-	changelogMD, _ := os.ReadFile(filepath.Join(root, "wiki", "CHANGELOG.md"))
+	return renderPacket(root, tplMode, buildPacketTemplateData(root, t, mode, "", now))
+}
 
+// distillPacketSources returns what a distill agent summarizes: the final run,
+// the task chat, the page being updated and the extracted draft.
+func distillPacketSources(root string, t *BoardTask, now time.Time) string {
 	var sb strings.Builder
-	sb.WriteString("# Hazel Prompt Packet\n\n")
-	sb.WriteString(fmt.Sprintf("- Mode: `%s`\n", mode))
-	sb.WriteString(fmt.Sprintf("- Generated: `%s`\n", now.Format(time.RFC3339)))
-	sb.WriteString(fmt.Sprintf("- Task: `%s`\n", t.ID))
-	sb.WriteString(fmt.Sprintf("- Title: %s\n", t.Title))
-	sb.WriteString(fmt.Sprintf("- Status: `%s`\n", t.Status))
-	sb.WriteString(fmt.Sprintf("- State root: `%s`\n", root))
-	sb.WriteString(fmt.Sprintf("- Repo root: `%s`\n", repoRoot))
-	sb.WriteString("\n## Instructions\n\n")
-	sb.WriteString("- Follow AGENTS.md constraints.\n")
-	sb.WriteString("- Do not edit human intent in task.md unless explicitly requested.\n")
-	if mode == "plan" {
-		sb.WriteString("- In plan mode, write the proposal to plan.md (temporary, pending accept/decline).\n")
-	} else {
-		sb.WriteString("- Keep implementation notes in impl.md.\n")
-	}
-	sb.WriteString("\n## Task (trimmed)\n\n")
-	sb.WriteString("```markdown\n" + clipped(taskMD, 6000) + "\n```\n")
-	sb.WriteString("\n## Implementation Notes (trimmed)\n\n")
-	sb.WriteString("```markdown\n" + clipped(string(implMD), 3000) + "\n```\n")
-
-	if strings.TrimSpace(string(agentsMD)) != "" {
-		sb.WriteString("\n## AGENTS.md (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(agentsMD), 3000) + "\n```\n")
-	}
-	if strings.TrimSpace(string(readmeMD)) != "" {
-		sb.WriteString("\n## Source README (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(readmeMD), 3000) + "\n```\n")
+	if runs, err := listRunRecords(root, t.ID); err == nil {
+		for _, r := range runs {
+			s, _ := r.JSONSummary["last_text"].(string)
+			if r.Mode == "distill" || strings.TrimSpace(s) == "" {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("\n## Final Run Summary (`%s`)\n\n", r.Name))
+			sb.WriteString("```text\n" + clipped(s, 2000) + "\n```\n")
+			break
+		}
 	}
-	if strings.TrimSpace(string(featuresMD)) != "" {
-		sb.WriteString("\n## Features/Usage Wiki (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(featuresMD), 3000) + "\n```\n")
+	if replies := distillChatReplies(root, t.ID, 6); len(replies) > 0 {
+		sb.WriteString("\n## Chat Transcript (assistant, trimmed)\n\n")
+		sb.WriteString("```markdown\n" + clipped(strings.Join(replies, "\n\n---\n\n"), 4000) + "\n```\n")
 	}
-	if strings.TrimSpace(string(changelogMD)) != "" {
-		sb.WriteString("\n## Changelog (trimmed)\n\n")
-		sb.WriteString("```markdown\n" + clipped(string(changelogMD), 2200) + "\n```\n")
+	page := distillTargetPage(root, t.ID, t.Title)
+	if b, err := os.ReadFile(filepath.Join(projectWikiDir(root), filepath.FromSlash(page))); err == nil {
+		sb.WriteString(fmt.Sprintf("\n## Existing Wiki Page (`wiki/%s`, update it)\n\n", page))
+		sb.WriteString("```markdown\n" + clipped(string(b), 4000) + "\n```\n")
 	}
-
-	return sb.String(), nil
+	sb.WriteString("\n## Extracted Draft\n\n")
+	sb.WriteString("```markdown\n" + clipped(extractDistillPage(root, t, now), 4000) + "\n```\n")
+	return sb.String()
 }
 
 func clipped(s string, max int) string {
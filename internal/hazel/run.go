@@ -22,6 +22,8 @@ type RunResult struct {
 	DispatchedTaskID string
 	AgentExitCode    *int
 	RunLogPath       string
+	// Warnings are preflight problems that did not block the run (preflight: warn).
+	Warnings []string
 }
 
 func RunTick(ctx context.Context, root string, opt RunOptions) (*RunResult, error) {
@@ -70,11 +72,24 @@ func runTickLocked(ctx context.Context, root string, opt RunOptions) (*RunResult
 		return &RunResult{}, nil
 	}
 
+	var warnings []string
+	if mode := preflightMode(cfg); mode != preflightOff {
+		project := trackedProjectForRoot(root)
+		problems := checkRepoPreflight(project, projectBaseBranch(nexusConfigFor(root), project))
+		if len(problems) > 0 && mode == preflightStrict {
+			return nil, &preflightError{Problems: problems}
+		}
+		for _, p := range problems {
+			warnings = append(warnings, "preflight: "+p.Message)
+		}
+	}
+
 	if !opt.DryRun {
-		next.Status = StatusActive
-		next.UpdatedAt = now
-		if err := writeYAMLFile(boardPath(root), &b); err != nil {
-			return nil, err
+		if err := runPreHook(ctx, root, hookPreRun, next.ID, map[string]string{"HAZEL_RUN_MODE": "implement"}); err != nil {
+			return nil, fmt.Errorf("%s: %w", next.ID, err)
+		}
+		if err := applyTaskStatus(ctx, root, &b, next, StatusActive, "run"); err != nil {
+			return nil, fmt.Errorf("%s: %w", next.ID, err)
 		}
 		if err := ensureTaskScaffold(root, next.ID); err != nil {
 			return nil, err
@@ -87,7 +102,7 @@ func runTickLocked(ctx context.Context, root string, opt RunOptions) (*RunResult
 		}
 	}
 
-	res := &RunResult{DispatchedTaskID: next.ID}
+	res := &RunResult{DispatchedTaskID: next.ID, Warnings: warnings}
 
 	if opt.DryRun {
 		return res, nil
@@ -102,7 +117,14 @@ func runTickLocked(ctx context.Context, root string, opt RunOptions) (*RunResult
 		LogPath:   lp,
 		StartedAt: now,
 	})
+	_ = emitWebhookEvent(root, webhookEventRunStarted, map[string]any{
+		"task_id":    next.ID,
+		"mode":       "implement",
+		"started_at": now,
+		"log_path":   lp,
+	})
 
+	before, track := beginRunChanges(root)
 	exit, logPath, err := runAgentCommand(ctx, root, cfg, next.ID, now)
 	if err != nil {
 		// Still reconcile state below.
@@ -110,11 +132,25 @@ func runTickLocked(ctx context.Context, root string, opt RunOptions) (*RunResult
 	}
 	res.AgentExitCode = &exit
 	res.RunLogPath = logPath
+	var changes *runChanges
+	if track {
+		changes = finishRunChanges(root, before, logPath)
+	}
+
+	// Verify acceptance criteria against the agent's changes (best-effort).
+	criteria := map[string]int{}
+	if rep, verr := verifyTaskCriteria(ctx, root, next.ID, logPath); verr == nil {
+		for _, r := range rep.Results {
+			criteria[r.Status]++
+		}
+	}
 
 	// Persist run metadata alongside the log for UI browsing (best-effort).
 	if logPath != "" {
 		jsonSummary := summarizeJSONEventsFromLog(logPath)
 		_ = writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{
+			"criteria":     criteria,
+			"changes":      changes,
 			"task_id":      next.ID,
 			"mode":         "implement",
 			"started_at":   now,
@@ -138,19 +174,34 @@ func runTickLocked(ctx context.Context, root string, opt RunOptions) (*RunResult
 		EndedAt:   time.Now(),
 		ExitCode:  &exit,
 	})
-
-	// Consolidated lifecycle: any completed agent run ends in REVIEW.
+	_ = emitWebhookEvent(root, webhookEventRunFinished, map[string]any{
+		"task_id":    next.ID,
+		"mode":       "implement",
+		"started_at": now,
+		"ended_at":   time.Now(),
+		"exit_code":  exit,
+		"log_path":   logPath,
+	})
+	fireHook(root, hookPostRun, next.ID, runHookEnv("implement", exit, logPath))
+
+	// Consolidated lifecycle: any completed agent run ends in REVIEW, unless a
+	// required acceptance criterion failed or a pre_status:REVIEW hook vetoes
+	// the move; then the task stays ACTIVE.
+	target := StatusReview
+	if criteriaReviewBlock(root, next.ID) != nil {
+		target = StatusActive
+	}
 	var b2 Board
 	if rerr := readYAMLFile(boardPath(root), &b2); rerr == nil {
 		if vErr := b2.Validate(); vErr == nil {
 			for _, t := range b2.Tasks {
 				if t.ID == next.ID {
-					t.Status = StatusReview
-					t.UpdatedAt = time.Now()
+					if err := applyTaskStatus(ctx, root, &b2, t, target, "run"); err != nil {
+						res.Warnings = append(res.Warnings, fmt.Sprintf("%s stays %s: %v", next.ID, t.Status, err))
+					}
 					break
 				}
 			}
-			_ = writeYAMLFile(boardPath(root), &b2)
 		}
 	}
 
@@ -249,50 +300,59 @@ func runAgentCommandMode(ctx context.Context, root string, cfg Config, taskID st
 	if cmdLine == "" {
 		return 0, runLogPath, fmt.Errorf("agent command is not configured for mode %s", mode)
 	}
-	cmd := exec.CommandContext(ctx, "sh", "-c", cmdLine)
-	td := taskDir(root, taskID)
+	exit, err = runShellLogged(ctx, resolveRepoRoot(root), agentEnv(root, taskID, mode), cmdLine, runLogPath)
+	if err != nil {
+		return 0, runLogPath, err
+	}
+	return exit, runLogPath, nil
+}
+
+// agentEnv is the HAZEL_* environment every agent command (and hook) receives.
+func agentEnv(root string, taskID string, mode string) []string {
 	repoRoot := resolveRepoRoot(root)
-	cmd.Dir = repoRoot
-	cmd.Env = append(os.Environ(),
+	return append(os.Environ(),
 		"HAZEL_ROOT="+repoRoot,
 		"HAZEL_STATE_ROOT="+root,
 		"HAZEL_REPO_ROOT="+repoRoot,
 		"HAZEL_TASK_ID="+taskID,
-		"HAZEL_TASK_DIR="+td,
+		"HAZEL_TASK_DIR="+taskDir(root, taskID),
 		"HAZEL_AGENT_PACKET="+taskFile(root, taskID, "agent_packet.md"),
 		"HAZEL_PROMPT_PACKET="+taskFile(root, taskID, "prompt_packet.md"),
 		"HAZEL_MODE="+mode,
 	)
+}
+
+// runShellLogged runs cmdLine via `sh -c` in dir, streaming stdout/stderr to
+// logPath (buffered and dropped when logPath is empty). A non-zero exit is reported through
+// exit, not err; err is reserved for failures to start the command.
+func runShellLogged(ctx context.Context, dir string, env []string, cmdLine string, logPath string) (exit int, err error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", cmdLine)
+	cmd.Dir = dir
+	cmd.Env = env
 
 	var out bytes.Buffer
 	var lw io.Writer = &out
 	var f *os.File
-	if runLogPath != "" {
-		ff, ferr := os.OpenFile(runLogPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if logPath != "" {
+		ff, ferr := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
 		if ferr != nil {
-			return 0, "", ferr
+			return 0, ferr
 		}
 		f = ff
+		defer f.Close()
 		// Stream logs to disk for UI tailing; avoid buffering potentially huge output in memory.
 		lw = f
 	}
 	cmd.Stdout = lw
 	cmd.Stderr = lw
 
-	err = cmd.Run()
-	exit = 0
-	if err != nil {
+	if err := cmd.Run(); err != nil {
 		if ee := (*exec.ExitError)(nil); errorAs(err, &ee) {
-			exit = ee.ExitCode()
-		} else {
-			return 0, runLogPath, err
+			return ee.ExitCode(), nil
 		}
+		return 0, err
 	}
-
-	if f != nil {
-		_ = f.Close()
-	}
-	return exit, runLogPath, nil
+	return 0, nil
 }
 
 func errorAs(err error, target any) bool {
@@ -309,6 +369,11 @@ func agentCommandForMode(cfg Config, mode string) string {
 		if strings.TrimSpace(cfg.AgentImplementCommand) != "" {
 			return cfg.AgentImplementCommand
 		}
+	case "distill":
+		// Distilling is read-and-summarize work, so it uses the plan backend.
+		if strings.TrimSpace(cfg.AgentPlanCommand) != "" {
+			return cfg.AgentPlanCommand
+		}
 	}
 	return strings.TrimSpace(cfg.AgentCommand)
 }
@@ -0,0 +1,192 @@
+package hazel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// workTreeSnapshot pins the repo state around an agent run: HEAD plus a tree
+// object of the whole working tree (tracked and untracked, minus ignored files).
+type workTreeSnapshot struct {
+	Head string
+	Tree string
+}
+
+type runChangedFile struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// runChanges is stored under "changes" in the run meta JSON; the full patch
+// lives next to the log as <run>.diff.
+type runChanges struct {
+	BeforeHead string           `json:"before_head,omitempty"`
+	AfterHead  string           `json:"after_head,omitempty"`
+	BeforeTree string           `json:"before_tree"`
+	AfterTree  string           `json:"after_tree"`
+	Commits    []string         `json:"commits,omitempty"`
+	Files      []runChangedFile `json:"files,omitempty"`
+	Stat       string           `json:"stat,omitempty"`
+	DiffPath   string           `json:"diff_path,omitempty"`
+	RevertedAt *time.Time       `json:"reverted_at,omitempty"`
+}
+
+func runDiffPathForLog(logPath string) string {
+	return strings.TrimSuffix(logPath, filepath.Ext(logPath)) + ".diff"
+}
+
+// snapshotWorkTree writes the current working tree as a git tree object using a
+// throwaway index, so the user's real index and stash are never touched.
+func snapshotWorkTree(repo string) (workTreeSnapshot, error) {
+	var s workTreeSnapshot
+	if _, err := runCmd(repo, nil, "git", "rev-parse", "--git-dir"); err != nil {
+		return s, err
+	}
+	s.Head, _ = runCmd(repo, nil, "git", "rev-parse", "--verify", "-q", "HEAD")
+
+	f, err := os.CreateTemp("", "hazel-index-*")
+	if err != nil {
+		return s, err
+	}
+	idx := f.Name()
+	_ = f.Close()
+	// git refuses an empty (zero-byte) index file; let it create one.
+	_ = os.Remove(idx)
+	defer os.Remove(idx)
+	env := []string{"GIT_INDEX_FILE=" + idx}
+	if _, err := runCmd(repo, env, "git", "add", "-A"); err != nil {
+		return s, err
+	}
+	tree, err := runCmd(repo, env, "git", "write-tree")
+	if err != nil {
+		return s, err
+	}
+	s.Tree = tree
+	return s, nil
+}
+
+// diffWorkTreeSnapshots computes what changed between two snapshots. The patch
+// is returned separately so callers decide where to persist it.
+func diffWorkTreeSnapshots(repo string, before, after workTreeSnapshot) (*runChanges, string, error) {
+	ch := &runChanges{
+		BeforeHead: before.Head,
+		AfterHead:  after.Head,
+		BeforeTree: before.Tree,
+		AfterTree:  after.Tree,
+	}
+	if before.Head != "" && after.Head != "" && before.Head != after.Head {
+		if out, err := runCmd(repo, nil, "git", "rev-list", "--reverse", before.Head+".."+after.Head); err == nil {
+			ch.Commits = strings.Fields(out)
+		}
+	}
+	if before.Tree == after.Tree {
+		return ch, "", nil
+	}
+	numstat, err := runCmd(repo, nil, "git", "diff", "--numstat", before.Tree, after.Tree)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, line := range strings.Split(numstat, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		f := runChangedFile{Path: parts[2]}
+		if parts[0] == "-" {
+			f.Binary = true
+		} else {
+			f.Added, _ = strconv.Atoi(parts[0])
+			f.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		ch.Files = append(ch.Files, f)
+	}
+	ch.Stat, _ = runCmd(repo, nil, "git", "diff", "--shortstat", before.Tree, after.Tree)
+	patch, err := runCmd(repo, nil, "git", "diff", "--binary", before.Tree, after.Tree)
+	if err != nil {
+		return nil, "", err
+	}
+	if patch != "" {
+		patch += "\n"
+	}
+	return ch, patch, nil
+}
+
+// beginRunChanges snapshots the project repo before an agent run. ok is false
+// when the repo is not a git work tree; change capture is then skipped.
+func beginRunChanges(root string) (before workTreeSnapshot, ok bool) {
+	s, err := snapshotWorkTree(resolveRepoRoot(root))
+	if err != nil {
+		return s, false
+	}
+	return s, true
+}
+
+// finishRunChanges snapshots again after the run and writes the patch next to
+// logPath. Best-effort: any git failure yields nil.
+func finishRunChanges(root string, before workTreeSnapshot, logPath string) *runChanges {
+	repo := resolveRepoRoot(root)
+	after, err := snapshotWorkTree(repo)
+	if err != nil {
+		return nil
+	}
+	ch, patch, err := diffWorkTreeSnapshots(repo, before, after)
+	if err != nil {
+		return nil
+	}
+	if patch != "" && logPath != "" {
+		p := runDiffPathForLog(logPath)
+		if err := writeFileAtomic(p, []byte(patch), 0o644); err == nil {
+			ch.DiffPath = p
+		}
+	}
+	return ch
+}
+
+// revertRunChanges reverse-applies a run's patch to the working tree. Commits
+// the agent made stay in history; their content is undone as uncommitted changes.
+func revertRunChanges(root string, name string) error {
+	logPath, ok := runLogPathForName(root, name)
+	if !ok {
+		return fmt.Errorf("run not found: %s", name)
+	}
+	rec := readRunRecord(logPath)
+	if rec.Changes == nil || rec.Changes.DiffPath == "" || !exists(rec.Changes.DiffPath) {
+		return fmt.Errorf("run %s has no recorded changes", name)
+	}
+	if rec.Changes.RevertedAt != nil {
+		return fmt.Errorf("run %s was already reverted at %s", name, rec.Changes.RevertedAt.Format(time.RFC3339))
+	}
+	repo := resolveRepoRoot(root)
+	if _, err := runCmd(repo, nil, "git", "apply", "--check", "-R", "--binary", rec.Changes.DiffPath); err != nil {
+		return fmt.Errorf("cannot revert cleanly (files changed since the run?): %w", err)
+	}
+	if _, err := runCmd(repo, nil, "git", "apply", "-R", "--binary", rec.Changes.DiffPath); err != nil {
+		return err
+	}
+	return markRunReverted(logPath, time.Now())
+}
+
+func markRunReverted(logPath string, at time.Time) error {
+	metaPath := runMetaPathForLog(logPath)
+	b, err := os.ReadFile(metaPath)
+	if err != nil {
+		return err
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return err
+	}
+	changes, _ := meta["changes"].(map[string]any)
+	if changes == nil {
+		return fmt.Errorf("run meta has no changes")
+	}
+	changes["reverted_at"] = at
+	return writeFileAtomic(metaPath, mustJSONIndent(meta), 0o644)
+}
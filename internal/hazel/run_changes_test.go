@@ -0,0 +1,69 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunChangesCaptureAndRevert(t *testing.T) {
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-qm", "init")
+
+	// The project storage root points at repo via project.json.
+	root := t.TempDir()
+	if err := writeProjectMeta(root, ProjectMeta{Key: "p", Name: "p", RepoPath: repo}); err != nil {
+		t.Fatal(err)
+	}
+	before, ok := beginRunChanges(root)
+	if !ok {
+		t.Fatal("expected git snapshot")
+	}
+
+	// Simulated agent: one commit plus an uncommitted new file.
+	if err := os.WriteFile(filepath.Join(repo, "a.txt"), []byte("one\ntwo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-qam", "agent")
+	if err := os.WriteFile(filepath.Join(repo, "b.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ensureDir(runsDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(runsDir(root), "20260101T000000_HZ-0001.log")
+	if err := os.WriteFile(logPath, []byte("log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ch := finishRunChanges(root, before, logPath)
+	if ch == nil || len(ch.Commits) != 1 || len(ch.Files) != 2 || ch.DiffPath == "" {
+		t.Fatalf("unexpected changes: %#v", ch)
+	}
+	if err := writeFileAtomic(runMetaPathForLog(logPath), mustJSONIndent(map[string]any{"task_id": "HZ-0001", "changes": ch}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := revertRunChanges(root, "20260101T000000_HZ-0001"); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(repo, "a.txt")); string(b) != "one\n" {
+		t.Fatalf("a.txt not reverted: %q", b)
+	}
+	if exists(filepath.Join(repo, "b.txt")) {
+		t.Fatal("b.txt should be removed by revert")
+	}
+	rec := readRunRecord(logPath)
+	if rec.Changes == nil || rec.Changes.RevertedAt == nil || time.Since(*rec.Changes.RevertedAt) > time.Minute {
+		t.Fatalf("revert not recorded: %#v", rec.Changes)
+	}
+	if err := revertRunChanges(root, "20260101T000000_HZ-0001"); err == nil {
+		t.Fatal("second revert should fail")
+	}
+}
@@ -0,0 +1,96 @@
+package hazel
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runRecord is one agent run as recorded by runTickLocked/planLocked:
+// a log file under .hazel/runs/ plus its sibling meta JSON.
+type runRecord struct {
+	Name        string         `json:"name"`
+	TaskID      string         `json:"task_id,omitempty"`
+	Mode        string         `json:"mode,omitempty"`
+	StartedAt   time.Time      `json:"started_at,omitempty"`
+	EndedAt     time.Time      `json:"ended_at,omitempty"`
+	ExitCode    *int           `json:"exit_code,omitempty"`
+	LogPath     string         `json:"log_path"`
+	JSONSummary map[string]any `json:"json_summary,omitempty"`
+	Changes     *runChanges    `json:"changes,omitempty"`
+}
+
+// runNameFromLog returns the stable identifier of a run (log file name without extension).
+func runNameFromLog(logPath string) string {
+	return strings.TrimSuffix(filepath.Base(logPath), filepath.Ext(logPath))
+}
+
+func runLogPathForName(root, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.Contains(name, "..") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	p := filepath.Join(runsDir(root), name+".log")
+	if !exists(p) {
+		return "", false
+	}
+	return p, true
+}
+
+func readRunRecord(logPath string) runRecord {
+	rec := runRecord{
+		Name:    runNameFromLog(logPath),
+		LogPath: logPath,
+	}
+	// Log names are <timestamp>_<task>.log; meta may be missing for runs still in flight.
+	if parts := strings.SplitN(rec.Name, "_", 2); len(parts) == 2 {
+		rec.TaskID = parts[1]
+		if t, err := time.ParseInLocation("20060102T150405", parts[0], time.Local); err == nil {
+			rec.StartedAt = t
+		}
+	}
+	b, err := os.ReadFile(runMetaPathForLog(logPath))
+	if err != nil {
+		return rec
+	}
+	var meta runRecord
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return rec
+	}
+	meta.Name = rec.Name
+	meta.LogPath = logPath
+	if meta.TaskID == "" {
+		meta.TaskID = rec.TaskID
+	}
+	if meta.StartedAt.IsZero() {
+		meta.StartedAt = rec.StartedAt
+	}
+	return meta
+}
+
+// listRunRecords returns recorded runs newest first, optionally filtered by task.
+func listRunRecords(root string, taskID string) ([]runRecord, error) {
+	ents, err := os.ReadDir(runsDir(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []runRecord{}, nil
+		}
+		return nil, err
+	}
+	out := []runRecord{}
+	for _, e := range ents {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".log") {
+			continue
+		}
+		rec := readRunRecord(filepath.Join(runsDir(root), e.Name()))
+		if taskID != "" && rec.TaskID != taskID {
+			continue
+		}
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name > out[j].Name })
+	return out, nil
+}
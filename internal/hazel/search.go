@@ -0,0 +1,466 @@
+package hazel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Search keeps an inverted index over every project's wiki pages, task files
+// (board and archive) and chat transcripts in .hazel/search/index.json at the
+// nexus root. Each query first refreshes the index: files are re-tokenized
+// only when their size or mtime changed, and removed files are dropped.
+
+const searchIndexVersion = 1
+
+// Search document kinds.
+const (
+	searchKindWiki = "wiki"
+	searchKindTask = "task"
+	searchKindChat = "chat"
+)
+
+var searchKinds = []string{searchKindWiki, searchKindTask, searchKindChat}
+
+type searchDoc struct {
+	Project  string `json:"project,omitempty"`
+	Kind     string `json:"kind"`
+	Rel      string `json:"rel"` // wiki page, task file (HZ-0001/impl.md) or chat session name
+	TaskID   string `json:"task_id,omitempty"`
+	Archived bool   `json:"archived,omitempty"`
+	ModTime  int64  `json:"mod_time"`
+	Size     int64  `json:"size"`
+	Length   int    `json:"length"` // token count
+	// Terms lists the distinct terms so a changed file can be unindexed.
+	Terms []string `json:"terms"`
+}
+
+type searchIndex struct {
+	Version int                   `json:"version"`
+	Docs    map[string]*searchDoc `json:"docs"` // keyed by absolute path
+	// Postings maps term -> doc path -> term frequency.
+	Postings map[string]map[string]int `json:"postings"`
+}
+
+// searchSource is a file that belongs in the index.
+type searchSource struct {
+	Path string
+	Doc  searchDoc
+}
+
+// SearchOptions narrows a query to one project and/or kind.
+type SearchOptions struct {
+	Project string
+	Kind    string
+	Limit   int
+}
+
+type SearchHit struct {
+	Project    string   `json:"project,omitempty"`
+	Kind       string   `json:"kind"`
+	Path       string   `json:"path"`
+	TaskID     string   `json:"task_id,omitempty"`
+	Archived   bool     `json:"archived,omitempty"`
+	Href       string   `json:"href"` // empty for archived tasks
+	Score      float64  `json:"score"`
+	Snippet    string   `json:"snippet"`
+	Highlights [][2]int `json:"highlights"` // byte ranges of matched terms in Snippet
+}
+
+func searchIndexPath(root string) string {
+	return filepath.Join(hazelDir(root), "search", "index.json")
+}
+
+// searchMu serializes index refreshes within one process.
+var searchMu sync.Mutex
+
+// searchTokens lowercases text and splits it into letter/digit runs of two or
+// more characters.
+func searchTokens(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if utf8.RuneCountInString(f) >= 2 {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// searchSources enumerates every indexable file: the nexus root wiki plus each
+// project's wiki, tasks, archive and chat sessions (or the single repo).
+func searchSources(root string, nexus *Nexus) []searchSource {
+	var out []searchSource
+	addWiki := func(project, dir string) {
+		for _, rel := range listWikiFiles(dir) {
+			out = append(out, searchSource{Path: filepath.Join(dir, filepath.FromSlash(rel)), Doc: searchDoc{Project: project, Kind: searchKindWiki, Rel: rel}})
+		}
+	}
+	addProject := func(project, storage string) {
+		addWiki(project, projectWikiDir(storage))
+		for _, base := range []string{tasksDir(storage), archiveDir(storage)} {
+			ents, err := os.ReadDir(base)
+			if err != nil {
+				continue
+			}
+			for _, e := range ents {
+				if !e.IsDir() {
+					continue
+				}
+				for _, name := range []string{"task.md", "impl.md", "plan.md"} {
+					p := filepath.Join(base, e.Name(), name)
+					if exists(p) {
+						out = append(out, searchSource{Path: p, Doc: searchDoc{
+							Project: project, Kind: searchKindTask, Rel: e.Name() + "/" + name,
+							TaskID: e.Name(), Archived: base == archiveDir(storage),
+						}})
+					}
+				}
+			}
+		}
+		ents, _ := os.ReadDir(chatSessionsDir(storage))
+		for _, e := range ents {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+				continue
+			}
+			out = append(out, searchSource{Path: filepath.Join(chatSessionsDir(storage), e.Name()), Doc: searchDoc{
+				Project: project, Kind: searchKindChat, Rel: e.Name(), TaskID: taskIDFromChatSessionName(e.Name()),
+			}})
+		}
+	}
+	if nexus == nil {
+		addProject("", root)
+		return out
+	}
+	addWiki("", filepath.Join(root, "wiki"))
+	for _, p := range nexus.Projects {
+		addProject(p.Key, p.StorageRoot)
+	}
+	return out
+}
+
+// searchDocText returns the searchable text of a document.
+func searchDocText(path, kind string) (string, error) {
+	if kind == searchKindChat {
+		evs, err := loadChatSessionEvents(path)
+		if err != nil {
+			return "", err
+		}
+		var parts []string
+		for _, ev := range evs {
+			if (ev.Type == "user_message" || ev.Type == "assistant_message") && strings.TrimSpace(ev.Text) != "" {
+				parts = append(parts, strings.TrimSpace(ev.Text))
+			}
+		}
+		return strings.Join(parts, "\n\n"), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	s := string(b)
+	if kind == searchKindTask && filepath.Base(path) == "task.md" {
+		s, _ = stripTaskConfigForRender(s)
+	}
+	return s, nil
+}
+
+func loadSearchIndex(root string) *searchIndex {
+	idx := &searchIndex{Version: searchIndexVersion, Docs: map[string]*searchDoc{}, Postings: map[string]map[string]int{}}
+	b, err := os.ReadFile(searchIndexPath(root))
+	if err != nil {
+		return idx
+	}
+	var disk searchIndex
+	if json.Unmarshal(b, &disk) != nil || disk.Version != searchIndexVersion || disk.Docs == nil || disk.Postings == nil {
+		return idx // unreadable or old format: rebuild
+	}
+	return &disk
+}
+
+func (idx *searchIndex) remove(path string) {
+	d, ok := idx.Docs[path]
+	if !ok {
+		return
+	}
+	for _, t := range d.Terms {
+		if m := idx.Postings[t]; m != nil {
+			delete(m, path)
+			if len(m) == 0 {
+				delete(idx.Postings, t)
+			}
+		}
+	}
+	delete(idx.Docs, path)
+}
+
+func (idx *searchIndex) add(src searchSource, text string, modTime, size int64) {
+	tf := map[string]int{}
+	toks := searchTokens(text)
+	for _, t := range toks {
+		tf[t]++
+	}
+	d := src.Doc
+	d.ModTime, d.Size, d.Length = modTime, size, len(toks)
+	d.Terms = make([]string, 0, len(tf))
+	for t, n := range tf {
+		d.Terms = append(d.Terms, t)
+		if idx.Postings[t] == nil {
+			idx.Postings[t] = map[string]int{}
+		}
+		idx.Postings[t][src.Path] = n
+	}
+	sort.Strings(d.Terms)
+	idx.Docs[src.Path] = &d
+}
+
+// refreshSearchIndex brings the on-disk index up to date and returns it.
+func refreshSearchIndex(root string, nexus *Nexus) (*searchIndex, error) {
+	searchMu.Lock()
+	defer searchMu.Unlock()
+	idx := loadSearchIndex(root)
+	changed := false
+	seen := map[string]bool{}
+	for _, src := range searchSources(root, nexus) {
+		seen[src.Path] = true
+		st, err := os.Stat(src.Path)
+		if err != nil {
+			continue
+		}
+		if d, ok := idx.Docs[src.Path]; ok && d.ModTime == st.ModTime().UnixNano() && d.Size == st.Size() {
+			continue
+		}
+		text, err := searchDocText(src.Path, src.Doc.Kind)
+		if err != nil {
+			continue
+		}
+		idx.remove(src.Path)
+		idx.add(src, text, st.ModTime().UnixNano(), st.Size())
+		changed = true
+	}
+	for path := range idx.Docs {
+		if !seen[path] {
+			idx.remove(path)
+			changed = true
+		}
+	}
+	if changed || !exists(searchIndexPath(root)) {
+		if err := ensureDir(filepath.Dir(searchIndexPath(root))); err != nil {
+			return idx, err
+		}
+		b, err := json.Marshal(idx)
+		if err != nil {
+			return idx, err
+		}
+		if err := writeFileAtomic(searchIndexPath(root), b, 0o644); err != nil {
+			return idx, err
+		}
+	}
+	return idx, nil
+}
+
+// Search refreshes the index and returns documents containing every query
+// term, ranked by BM25, with a highlighted snippet.
+func Search(root string, nexus *Nexus, query string, opts SearchOptions) ([]SearchHit, error) {
+	if opts.Kind != "" && !slices.Contains(searchKinds, opts.Kind) {
+		return nil, fmt.Errorf("invalid kind %q (wiki, task or chat)", opts.Kind)
+	}
+	if opts.Project != "" {
+		if nexus == nil {
+			return nil, errors.New("--project requires nexus mode")
+		}
+		if _, ok := nexus.ProjectByKey(opts.Project); !ok {
+			return nil, fmt.Errorf("project not found: %s", opts.Project)
+		}
+	}
+	terms := dedupeStrings(searchTokens(query))
+	if len(terms) == 0 {
+		return []SearchHit{}, nil
+	}
+	idx, err := refreshSearchIndex(root, nexus)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+
+	// BM25 over the whole corpus; filters only narrow the candidates.
+	const k1, b = 1.2, 0.75
+	n := float64(len(idx.Docs))
+	avgLen := 0.0
+	for _, d := range idx.Docs {
+		avgLen += float64(d.Length)
+	}
+	if n > 0 {
+		avgLen /= n
+	}
+	scores := map[string]float64{}
+	for i, t := range terms {
+		post := idx.Postings[t]
+		df := float64(len(post))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		next := map[string]float64{}
+		for path, tf := range post {
+			if i > 0 {
+				if _, ok := scores[path]; !ok {
+					continue
+				}
+			}
+			d := idx.Docs[path]
+			if d == nil || (opts.Project != "" && d.Project != opts.Project) || (opts.Kind != "" && d.Kind != opts.Kind) {
+				continue
+			}
+			f := float64(tf)
+			next[path] = scores[path] + idf*f*(k1+1)/(f+k1*(1-b+b*float64(d.Length)/math.Max(avgLen, 1)))
+		}
+		scores = next
+	}
+	paths := make([]string, 0, len(scores))
+	for p := range scores {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if scores[paths[i]] != scores[paths[j]] {
+			return scores[paths[i]] > scores[paths[j]]
+		}
+		return paths[i] < paths[j]
+	})
+	if len(paths) > opts.Limit {
+		paths = paths[:opts.Limit]
+	}
+	hits := make([]SearchHit, 0, len(paths))
+	for _, p := range paths {
+		d := idx.Docs[p]
+		text, _ := searchDocText(p, d.Kind)
+		snippet, marks := searchSnippet(text, terms, 160)
+		hits = append(hits, SearchHit{
+			Project: d.Project, Kind: d.Kind, Path: d.Rel, TaskID: d.TaskID, Archived: d.Archived,
+			Href: searchHref(d), Score: math.Round(scores[p]*1000) / 1000, Snippet: snippet, Highlights: marks,
+		})
+	}
+	return hits, nil
+}
+
+// SearchRoot runs Search from the CLI, loading the nexus when projects_root_dir is set.
+func SearchRoot(root, query string, opts SearchOptions) ([]SearchHit, error) {
+	cfg, err := loadConfigOrDefault(root)
+	if err != nil {
+		return nil, err
+	}
+	var nx *Nexus
+	if strings.TrimSpace(cfg.ProjectsRootDir) != "" {
+		if nx, err = LoadNexus(root); err != nil {
+			return nil, err
+		}
+	}
+	return Search(root, nx, query, opts)
+}
+
+// searchHref links a hit to its page. Archived tasks have no page, so their
+// hits are left unlinked.
+func searchHref(d *searchDoc) string {
+	q := ""
+	if d.Project != "" {
+		q = "project=" + url.QueryEscape(d.Project) + "&"
+	}
+	switch d.Kind {
+	case searchKindWiki:
+		return "/wiki?" + q + "file=" + url.QueryEscape(d.Rel)
+	case searchKindChat:
+		return "/chat?" + q + "session=" + url.QueryEscape(strings.TrimSuffix(d.Rel, ".jsonl"))
+	}
+	if d.Archived {
+		return ""
+	}
+	if d.Project != "" {
+		return "/task/" + d.Project + "/" + d.TaskID
+	}
+	return "/task/" + d.TaskID
+}
+
+// searchSnippet returns about width bytes of text around the first matched
+// term, with the byte ranges of every term occurrence inside it.
+func searchSnippet(text string, terms []string, width int) (string, [][2]int) {
+	text = strings.Join(strings.Fields(text), " ")
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		lower = text // case folding changed byte lengths; fall back to exact matching
+	}
+	first := -1
+	for _, t := range terms {
+		if i := searchTermIndex(lower, t, 0); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	start := 0
+	if first > width/3 {
+		start = first - width/3
+	}
+	end := min(len(text), start+width)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	snippet := text[start:end]
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	var marks [][2]int
+	for _, t := range terms {
+		for i := searchTermIndex(lower, t, start); i >= 0 && i+len(t) <= end; i = searchTermIndex(lower, t, i+len(t)) {
+			marks = append(marks, [2]int{len(prefix) + i - start, len(prefix) + i - start + len(t)})
+		}
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i][0] < marks[j][0] })
+	return prefix + snippet, marks
+}
+
+// searchTermIndex finds t in s at or after from, starting at a word boundary.
+func searchTermIndex(s, t string, from int) int {
+	for from < len(s) {
+		i := strings.Index(s[from:], t)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		if i == 0 {
+			return i
+		}
+		r, _ := utf8.DecodeLastRuneInString(s[:i])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return i
+		}
+		from = i + len(t)
+	}
+	return -1
+}
+
+func dedupeStrings(in []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
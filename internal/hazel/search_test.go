@@ -0,0 +1,75 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchIndexAndRank(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	write := func(path, body string) {
+		t.Helper()
+		if err := ensureDir(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(projectWikiDir(root), "guides", "deploy.md"), "# Deploy\n\nRolling deploys use the canary pool. Canary first, then everyone.\n")
+	write(taskFile(root, "HZ-0007", "task.md"), "# Task\n\n## Summary\n\nMove the canary config to YAML.\n")
+	write(taskFile(root, "HZ-0007", "impl.md"), "# Implementation\n\nNothing about birds.\n")
+	write(filepath.Join(archiveDir(root), "HZ-0001", "plan.md"), "# Plan\n\nRetire the legacy pool.\n")
+	ev := func(typ, text string) string {
+		return string(mustJSONIndent(codexEvent{Type: typ, Text: text, CreatedAt: time.Now()}))
+	}
+	chat := strings.ReplaceAll(ev("user_message", "Why is the canary pool slow?"), "\n", "") + "\n" +
+		strings.ReplaceAll(ev("tool_output", "canary canary canary"), "\n", "") + "\n"
+	write(filepath.Join(chatSessionsDir(root), "20260101T000000_HZ-0007_abc.jsonl"), chat)
+
+	hits, err := Search(root, nil, "canary", SearchOptions{})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(hits) != 3 || hits[0].Path != "guides/deploy.md" {
+		t.Fatalf("hits = %+v", hits)
+	}
+	for _, h := range hits {
+		if len(h.Highlights) == 0 || !strings.EqualFold(h.Snippet[h.Highlights[0][0]:h.Highlights[0][1]], "canary") {
+			t.Fatalf("highlight %+v in %q", h.Highlights, h.Snippet)
+		}
+	}
+
+	// All terms must match; filters narrow by kind.
+	if hits, _ := Search(root, nil, "canary pool", SearchOptions{Kind: searchKindChat}); len(hits) != 1 || hits[0].TaskID != "HZ-0007" || hits[0].Href != "/chat?session=20260101T000000_HZ-0007_abc" {
+		t.Fatalf("chat hits = %+v", hits)
+	}
+	if hits, _ := Search(root, nil, "legacy", SearchOptions{}); len(hits) != 1 || !hits[0].Archived || hits[0].Href != "" {
+		t.Fatalf("archived hits = %+v", hits)
+	}
+	if _, err := Search(root, nil, "canary", SearchOptions{Kind: "runs"}); err == nil {
+		t.Fatalf("invalid kind accepted")
+	}
+
+	// Changed and removed files are picked up on the next query.
+	write(taskFile(root, "HZ-0007", "impl.md"), "# Implementation\n\nNow mentions herons, a bird.\n")
+	if err := os.Remove(filepath.Join(projectWikiDir(root), "guides", "deploy.md")); err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := Search(root, nil, "herons", SearchOptions{}); len(hits) != 1 || hits[0].Path != "HZ-0007/impl.md" {
+		t.Fatalf("updated hits = %+v", hits)
+	}
+	if hits, _ := Search(root, nil, "canary", SearchOptions{Kind: searchKindWiki}); len(hits) != 0 {
+		t.Fatalf("removed page still found: %+v", hits)
+	}
+	idx := loadSearchIndex(root)
+	if _, ok := idx.Postings["birds"]; ok {
+		t.Fatalf("stale term left in index")
+	}
+}
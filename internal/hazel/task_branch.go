@@ -0,0 +1,172 @@
+package hazel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// branchStatus is how far a task branch has drifted from its base.
+type branchStatus struct {
+	Ref    string // base ref compared against (origin/<base> when present)
+	Ahead  int
+	Behind int
+	Exists bool
+}
+
+// rebaseConflictError reports the files that conflicted; the rebase has been aborted.
+type rebaseConflictError struct {
+	Onto  string
+	Files []string
+}
+
+func (e *rebaseConflictError) Error() string {
+	return fmt.Sprintf("rebase onto %s conflicts in %s; rebase aborted, repo left unchanged", e.Onto, strings.Join(e.Files, ", "))
+}
+
+func trackedProjectForRoot(stateRoot string) TrackedProject {
+	p := TrackedProject{StorageRoot: stateRoot, RepoPath: stateRoot}
+	if m, err := readProjectMeta(stateRoot); err == nil && m != nil {
+		p.Key, p.Name, p.RepoSlug = m.Key, m.Name, m.RepoSlug
+		if m.RepoPath != "" {
+			p.RepoPath = m.RepoPath
+		}
+	}
+	return p
+}
+
+// baseRef prefers the remote-tracking branch so drift reflects what the PR targets.
+func baseRef(repo, base string) string {
+	if _, err := runCmd(repo, nil, "git", "rev-parse", "--verify", "-q", "refs/remotes/origin/"+base); err == nil {
+		return "origin/" + base
+	}
+	return base
+}
+
+func localBranchExists(repo, branch string) bool {
+	_, err := runCmd(repo, nil, "git", "rev-parse", "--verify", "-q", "refs/heads/"+branch)
+	return err == nil
+}
+
+// taskBranchStatus computes ahead/behind from local refs only (no fetch), so it
+// is cheap enough for every task page render.
+func taskBranchStatus(repo string, meta taskGitMeta) branchStatus {
+	branch := strings.TrimSpace(meta.Branch)
+	base := strings.TrimSpace(meta.Base)
+	if branch == "" || base == "" || !localBranchExists(repo, branch) {
+		return branchStatus{}
+	}
+	st := branchStatus{Ref: baseRef(repo, base), Exists: true}
+	out, err := runCmd(repo, nil, "git", "rev-list", "--left-right", "--count", st.Ref+"..."+branch)
+	if err != nil {
+		return st
+	}
+	if f := strings.Fields(out); len(f) == 2 {
+		st.Behind, _ = strconv.Atoi(f[0])
+		st.Ahead, _ = strconv.Atoi(f[1])
+	}
+	return st
+}
+
+// rebaseTaskBranch fetches the base and rebases the task branch onto it. On
+// conflict the rebase is aborted and the conflicted files are returned in a
+// *rebaseConflictError. With push, a successful rebase is force-pushed with lease.
+func rebaseTaskBranch(project TrackedProject, meta taskGitMeta, push bool) (branchStatus, error) {
+	repo := project.RepoPath
+	branch := strings.TrimSpace(meta.Branch)
+	base := strings.TrimSpace(meta.Base)
+	if branch == "" || base == "" {
+		return branchStatus{}, fmt.Errorf("task has no branch; use Start Branch first")
+	}
+	if dirty, _ := runCmd(repo, nil, "git", "status", "--porcelain", "--untracked-files=no"); dirty != "" {
+		return branchStatus{}, fmt.Errorf("working tree has uncommitted changes; commit or stash them before rebasing")
+	}
+	_, _ = runCmd(repo, nil, "git", "fetch", "-q", "origin", base)
+	if _, err := runCmd(repo, nil, "git", "checkout", "-q", branch); err != nil {
+		return branchStatus{}, err
+	}
+	onto := baseRef(repo, base)
+	if _, err := runCmd(repo, nil, "git", "rebase", onto); err != nil {
+		files, _ := runCmd(repo, nil, "git", "diff", "--name-only", "--diff-filter=U")
+		_, _ = runCmd(repo, nil, "git", "rebase", "--abort")
+		if conflicted := strings.Fields(files); len(conflicted) > 0 {
+			return branchStatus{}, &rebaseConflictError{Onto: onto, Files: conflicted}
+		}
+		return branchStatus{}, err
+	}
+	if push {
+		if _, err := runCmd(repo, nil, "git", "push", "--force-with-lease", "origin", branch); err != nil {
+			return taskBranchStatus(repo, meta), err
+		}
+	}
+	return taskBranchStatus(repo, meta), nil
+}
+
+// deleteTaskBranch removes the task branch locally and on origin. Unless force
+// is set it refuses branches that are neither recorded as merged nor contained
+// in the base, so abandoned work is never lost. The nexus cfg supplies the base
+// branch (see projectBaseBranch) when the task did not record one.
+func deleteTaskBranch(project TrackedProject, taskID string, meta taskGitMeta, cfg Config, force bool) error {
+	repo := project.RepoPath
+	branch := strings.TrimSpace(meta.Branch)
+	if branch == "" {
+		return fmt.Errorf("task has no branch")
+	}
+	base := strings.TrimSpace(meta.Base)
+	if base == "" {
+		base = projectBaseBranch(cfg, project)
+	}
+	local := localBranchExists(repo, branch)
+	if !force && strings.TrimSpace(meta.MergeSHA) == "" && local {
+		if _, err := runCmd(repo, nil, "git", "merge-base", "--is-ancestor", branch, baseRef(repo, base)); err != nil {
+			return fmt.Errorf("branch %s is not merged into %s", branch, base)
+		}
+	}
+	if local {
+		if cur, _ := runCmd(repo, nil, "git", "rev-parse", "--abbrev-ref", "HEAD"); cur == branch {
+			if _, err := runCmd(repo, nil, "git", "checkout", "-q", base); err != nil {
+				return err
+			}
+		}
+		if _, err := runCmd(repo, nil, "git", "branch", "-D", branch); err != nil {
+			return err
+		}
+	}
+	if _, err := runCmd(repo, nil, "git", "ls-remote", "--exit-code", "--heads", "origin", branch); err == nil {
+		if _, err := runCmd(repo, nil, "git", "push", "-q", "origin", "--delete", branch); err != nil {
+			return err
+		}
+	}
+	return saveTaskGitMeta(project, taskID, func(g *taskGitMeta) {
+		g.BranchDeletedAt = time.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+// cleanupMergedBranch runs deleteTaskBranch when the project config enables
+// delete_merged_branches. Status changes run it as a background job under the
+// repo lock (see goBackground); archive runs it inline before task.md moves away.
+func cleanupMergedBranch(stateRoot string, taskID string, async bool) {
+	cfg, err := loadConfigOrDefault(stateRoot)
+	if err != nil || !cfg.DeleteMergedBranches {
+		return
+	}
+	md, err := readTaskMD(stateRoot, taskID)
+	if err != nil {
+		return
+	}
+	meta, _ := getTaskGitFromMD(md)
+	if meta.Branch == "" || meta.BranchDeletedAt != "" {
+		return
+	}
+	project, nexusCfg := trackedProjectForRoot(stateRoot), nexusConfigFor(stateRoot)
+	if !async {
+		_ = deleteTaskBranch(project, taskID, meta, nexusCfg, false)
+		return
+	}
+	goBackground(func() {
+		_ = withRepoLock(stateRoot, func() error {
+			return deleteTaskBranch(project, taskID, meta, nexusCfg, false)
+		})
+	})
+}
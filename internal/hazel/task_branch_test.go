@@ -0,0 +1,140 @@
+package hazel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRebaseTaskBranchConflictAndCleanup(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	task := &BoardTask{ID: "HZ-0020", Title: "drift", Status: StatusActive, CreatedAt: now, UpdatedAt: now}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{task}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, task.ID); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "base\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("checkout", "-q", "-b", "task/hz-0020-drift")
+	write("a.txt", "task\n")
+	git("commit", "-q", "-am", "task change")
+	git("checkout", "-q", "main")
+	write("a.txt", "main\n")
+	git("commit", "-q", "-am", "main change")
+
+	project := TrackedProject{Key: "app", RepoPath: repo, StorageRoot: root}
+	meta := taskGitMeta{Branch: "task/hz-0020-drift", Base: "main"}
+	if st := taskBranchStatus(repo, meta); !st.Exists || st.Ahead != 1 || st.Behind != 1 || st.Ref != "main" {
+		t.Fatalf("branch status = %+v", st)
+	}
+
+	_, err := rebaseTaskBranch(project, meta, false)
+	var conflict *rebaseConflictError
+	if !errors.As(err, &conflict) || len(conflict.Files) != 1 || conflict.Files[0] != "a.txt" {
+		t.Fatalf("expected conflict on a.txt, got %v", err)
+	}
+	if exists(filepath.Join(repo, ".git", "rebase-merge")) || exists(filepath.Join(repo, ".git", "rebase-apply")) {
+		t.Fatalf("repo left mid-rebase")
+	}
+
+	if err := deleteTaskBranch(project, task.ID, meta, Config{}, false); err == nil {
+		t.Fatalf("expected unmerged branch to be kept")
+	}
+	git("checkout", "-q", "main")
+	git("merge", "-q", "-X", "theirs", "--no-edit", "task/hz-0020-drift")
+	if err := deleteTaskBranch(project, task.ID, meta, Config{}, false); err != nil {
+		t.Fatalf("delete merged branch: %v", err)
+	}
+	if localBranchExists(repo, meta.Branch) {
+		t.Fatalf("branch still exists")
+	}
+	md, _ := readTaskMD(root, task.ID)
+	if g, _ := getTaskGitFromMD(md); g.BranchDeletedAt == "" {
+		t.Fatalf("branch_deleted_at not recorded")
+	}
+}
+
+func TestMergedBranchCleanupOnDone(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.ProjectsRootDir, cfg.GitBaseBranch = "projects", "trunk"
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	repo := filepath.Join(root, "projects", "app")
+	if err := ensureDir(repo); err != nil {
+		t.Fatal(err)
+	}
+	git := testGitRepo(t, repo)
+	git("checkout", "-q", "-b", "trunk")
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("checkout", "-q", "-b", "task/hz-0021-trunk")
+	git("commit", "-q", "--allow-empty", "-m", "task")
+	git("checkout", "-q", "trunk")
+	git("merge", "-q", "--ff-only", "task/hz-0021-trunk")
+	nx, err := LoadNexus(root)
+	if err != nil || len(nx.Projects) != 1 {
+		t.Fatalf("load nexus: %+v, %v", nx, err)
+	}
+	project := nx.Projects[0]
+	pcfg, _ := loadConfigOrDefault(project.StorageRoot)
+	pcfg.DeleteMergedBranches = true
+	if err := writeYAMLFile(configPath(project.StorageRoot), &pcfg); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(project.StorageRoot), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0021", Title: "trunk", Status: StatusReview, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(project.StorageRoot, "HZ-0021"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	// The task recorded no base and is checked out, so cleanup has to switch to
+	// the nexus git_base_branch even though the project config leaves it unset.
+	if err := saveTaskGitMeta(project, "HZ-0021", func(g *taskGitMeta) {
+		g.Branch, g.MergeSHA = "task/hz-0021-trunk", git("rev-parse", "HEAD")
+	}); err != nil {
+		t.Fatalf("save git meta: %v", err)
+	}
+	git("checkout", "-q", "task/hz-0021-trunk")
+
+	if _, err := transitionTaskStatus(project.StorageRoot, "HZ-0021", StatusDone); err != nil {
+		t.Fatalf("DONE: %v", err)
+	}
+	WaitBackground()
+	if localBranchExists(repo, "task/hz-0021-trunk") {
+		t.Fatal("merged branch kept after DONE")
+	}
+
+	// Without a recorded base or merge, the base decides whether the branch is merged.
+	git("branch", "task/hz-0021-trunk")
+	meta := taskGitMeta{Branch: "task/hz-0021-trunk"}
+	if err := deleteTaskBranch(project, "HZ-0021", meta, Config{}, false); err == nil || !strings.Contains(err.Error(), "not merged into main") {
+		t.Fatalf("delete against main: %v", err)
+	}
+	if err := deleteTaskBranch(project, "HZ-0021", meta, cfg, false); err != nil {
+		t.Fatalf("delete against trunk: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package hazel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultCommitMessageTemplate renders conventional-commit subjects such as
+// "feat(HZ-0012): Rate limit login". The Hazel-Task trailer is appended separately.
+const defaultCommitMessageTemplate = "{{.Type}}({{.Task.ID}}): {{.Subject}}"
+
+var commitTypes = []string{"feat", "fix", "chore", "docs", "refactor", "test", "perf", "build", "ci"}
+
+// workTreeChange is one entry of `git status` offered in the commit dialog.
+type workTreeChange struct {
+	Path      string
+	OrigPath  string // source path of a rename/copy
+	Status    string // two-letter porcelain XY code, "??" for untracked
+	Untracked bool
+	// Risky marks paths that look like secrets or build output; they start unchecked.
+	Risky  string
+	Staged bool
+}
+
+// riskyPathReason flags files that should not be committed by default.
+func riskyPathReason(p string) string {
+	base := strings.ToLower(path.Base(p))
+	lower := strings.ToLower(p)
+	switch {
+	case base == ".env" || strings.HasPrefix(base, ".env."):
+		return "environment file"
+	case strings.HasSuffix(base, ".pem"), strings.HasSuffix(base, ".key"), strings.HasSuffix(base, ".p12"), strings.HasSuffix(base, ".pfx"),
+		strings.HasPrefix(base, "id_rsa"), strings.HasPrefix(base, "id_ed25519"), strings.Contains(base, "secret"), strings.Contains(base, "credential"):
+		return "possible secret"
+	case strings.HasSuffix(base, ".log"), base == ".ds_store":
+		return "log/OS file"
+	}
+	for _, dir := range []string{"node_modules/", "dist/", "build/", "target/", "vendor/", ".next/", "__pycache__/", "coverage/"} {
+		if strings.HasPrefix(lower, dir) || strings.Contains(lower, "/"+dir) {
+			return "build artifact"
+		}
+	}
+	return ""
+}
+
+// listWorkTreeChanges lists changed and untracked files (ignored files excluded).
+func listWorkTreeChanges(repo string) ([]workTreeChange, error) {
+	cmd := exec.Command("git", "status", "--porcelain=v1", "-z", "-uall")
+	cmd.Dir = repo
+	var errOut bytes.Buffer
+	cmd.Stderr = &errOut
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %s", strings.TrimSpace(errOut.String()))
+	}
+	var changes []workTreeChange
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 4 {
+			continue
+		}
+		c := workTreeChange{Status: e[:2], Path: e[3:]}
+		c.Untracked = c.Status == "??"
+		c.Staged = c.Status[0] != ' ' && c.Status[0] != '?'
+		// Renames/copies are followed by the original path.
+		if (c.Status[0] == 'R' || c.Status[0] == 'C') && i+1 < len(entries) {
+			i++
+			c.OrigPath = entries[i]
+		}
+		c.Risky = riskyPathReason(c.Path)
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// workTreeFileDiff returns the patch of one file against HEAD (or /dev/null when untracked).
+func workTreeFileDiff(repo string, c workTreeChange) string {
+	args := []string{"diff", "HEAD", "--", c.Path}
+	if c.Untracked {
+		args = []string{"diff", "--no-index", "--", "/dev/null", c.Path}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo
+	// `git diff --no-index` exits 1 when files differ; the output is still the patch.
+	out, _ := cmd.Output()
+	return string(out)
+}
+
+type commitTemplateData struct {
+	Task    *BoardTask
+	Type    string
+	Subject string
+}
+
+// renderCommitMessage applies commit_message_template (or the conventional default).
+func renderCommitMessage(cfg Config, task *BoardTask, typ, subject string) (string, error) {
+	src := strings.TrimSpace(cfg.CommitMessageTemplate)
+	if src == "" {
+		src = defaultCommitMessageTemplate
+	}
+	if strings.TrimSpace(typ) == "" {
+		typ = "feat"
+	}
+	if strings.TrimSpace(subject) == "" {
+		subject = task.Title
+	}
+	tpl, err := template.New("commit").Parse(src)
+	if err != nil {
+		return "", fmt.Errorf("commit_message_template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, commitTemplateData{Task: task, Type: strings.TrimSpace(typ), Subject: strings.TrimSpace(subject)}); err != nil {
+		return "", fmt.Errorf("commit_message_template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// withTaskTrailer appends "Hazel-Task: <id>" unless the message already has it.
+func withTaskTrailer(msg, taskID string) string {
+	msg = strings.TrimRight(msg, "\n ")
+	trailer := "Hazel-Task: " + taskID
+	if strings.Contains(msg, trailer) {
+		return msg + "\n"
+	}
+	return msg + "\n\n" + trailer + "\n"
+}
+
+// runPreCommit runs pre_commit_command in the repo with the selected files in
+// HAZEL_COMMIT_FILES (newline separated). A non-zero exit blocks the commit.
+func runPreCommit(project TrackedProject, task *BoardTask, cfg Config, files []string) error {
+	cmdLine := strings.TrimSpace(cfg.PreCommitCommand)
+	if cmdLine == "" {
+		return nil
+	}
+	logDir := filepath.Join(runsDir(project.StorageRoot), "commits")
+	if err := ensureDir(logDir); err != nil {
+		return err
+	}
+	logPath := filepath.Join(logDir, time.Now().Format("20060102T150405")+"_"+task.ID+"_pre_commit.log")
+	env := append(agentEnv(project.StorageRoot, task.ID, "commit"), "HAZEL_COMMIT_FILES="+strings.Join(files, "\n"))
+	exit, err := runShellLogged(context.Background(), project.RepoPath, env, cmdLine, logPath)
+	if err != nil {
+		return fmt.Errorf("pre_commit_command: %w", err)
+	}
+	if exit != 0 {
+		return fmt.Errorf("pre_commit_command exited %d; see %s", exit, rel(project.StorageRoot, logPath))
+	}
+	return nil
+}
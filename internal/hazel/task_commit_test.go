@@ -0,0 +1,75 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommitTaskChangesSelectedFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	task := &BoardTask{ID: "HZ-0012", Title: "Rate limit login", Status: StatusActive, CreatedAt: now, UpdatedAt: now}
+	if err := writeYAMLFile(boardPath(root), &Board{Version: 1, Tasks: []*BoardTask{task}}); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, task.ID); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	git("commit", "-q", "--allow-empty", "-m", "init")
+	for name, body := range map[string]string{"limiter.go": "package x\n", ".env": "TOKEN=1\n"} {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := TrackedProject{Key: "app", RepoPath: repo, StorageRoot: root}
+
+	changes, err := listWorkTreeChanges(repo)
+	if err != nil {
+		t.Fatalf("list changes: %v", err)
+	}
+	risky := map[string]string{}
+	for _, c := range changes {
+		risky[c.Path] = c.Risky
+	}
+	if len(changes) != 2 || risky[".env"] == "" || risky["limiter.go"] != "" {
+		t.Fatalf("changes = %+v", changes)
+	}
+
+	cfg := Config{PreCommitCommand: `test "$HAZEL_COMMIT_FILES" = limiter.go`}
+	msg, err := renderCommitMessage(cfg, task, "fix", "")
+	if err != nil || msg != "fix(HZ-0012): Rate limit login" {
+		t.Fatalf("message = %q (%v)", msg, err)
+	}
+	sha, err := commitTaskChanges(project, task, cfg, []string{"limiter.go"}, msg)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if files := git("show", "--name-only", "--format=", sha); files != "limiter.go" {
+		t.Fatalf("committed files = %q", files)
+	}
+	if body := git("log", "-1", "--format=%B"); !strings.Contains(body, "Hazel-Task: HZ-0012") {
+		t.Fatalf("missing trailer: %q", body)
+	}
+	md, _ := readTaskMD(root, task.ID)
+	g, _ := getTaskGitFromMD(md)
+	if g.LastCommit != sha || len(g.Commits) != 1 || g.Commits[0] != sha {
+		t.Fatalf("git meta = %+v", g)
+	}
+
+	cfg.PreCommitCommand = "exit 7"
+	if _, err := commitTaskChanges(project, task, cfg, []string{".env"}, msg); err == nil || !strings.Contains(err.Error(), "exited 7") {
+		t.Fatalf("expected pre-commit failure, got %v", err)
+	}
+	if st := git("status", "--porcelain", "--", ".env"); st != "?? .env" {
+		t.Fatalf(".env status = %q", st)
+	}
+}
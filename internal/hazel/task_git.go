@@ -2,8 +2,12 @@ package hazel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
@@ -16,6 +20,35 @@ func gitBaseBranch(cfg Config) string {
 	return base
 }
 
+// projectBaseBranch is the branch p's tasks are cut from, reviewed against and
+// merged into: the nexus git_base_branch (Settings, `hazel config`) unless the
+// project's own config sets one.
+func projectBaseBranch(nexusCfg Config, p TrackedProject) string {
+	if p.StorageRoot != "" {
+		var pcfg Config
+		if err := readYAMLFile(configPath(p.StorageRoot), &pcfg); err == nil {
+			if base := strings.TrimSpace(pcfg.GitBaseBranch); base != "" {
+				return base
+			}
+		}
+	}
+	return gitBaseBranch(nexusCfg)
+}
+
+// nexusConfigFor loads the config of the nexus that owns a project storage
+// root (<nexus>/.hazel/projects/<key>). Outside a nexus the storage root is
+// the repo itself, so its own config is returned.
+func nexusConfigFor(stateRoot string) Config {
+	root := filepath.Clean(stateRoot)
+	if projects := filepath.Dir(root); filepath.Base(projects) == "projects" {
+		if dir := filepath.Dir(projects); filepath.Base(dir) == ".hazel" {
+			root = filepath.Dir(dir)
+		}
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	return cfg
+}
+
 func taskBranchName(taskID, title string) string {
 	slug := strings.ToLower(strings.TrimSpace(title))
 	var b strings.Builder
@@ -40,7 +73,12 @@ func taskBranchName(taskID, title string) string {
 }
 
 func runCmd(dir string, env []string, name string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
+	return runCmdContext(context.Background(), dir, env, name, args...)
+}
+
+// runCmdContext is runCmd for commands the caller can cancel (Ctrl-C in the CLI).
+func runCmdContext(ctx context.Context, dir string, env []string, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = dir
 	if len(env) > 0 {
 		cmd.Env = append(cmd.Environ(), env...)
@@ -62,6 +100,27 @@ func runCmd(dir string, env []string, name string, args ...string) (string, erro
 	return strings.TrimSpace(out.String()), nil
 }
 
+// listRepoFiles lists tracked and untracked-but-not-ignored files, so
+// .gitignore is respected without reimplementing it.
+func listRepoFiles(repo string) ([]string, error) {
+	out, err := runCmd(repo, nil, "git", "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f == "" || strings.HasPrefix(f, ".hazel/") {
+			continue
+		}
+		if st, err := os.Stat(filepath.Join(repo, filepath.FromSlash(f))); err != nil || !st.Mode().IsRegular() {
+			continue // deleted in the worktree, or a submodule/symlink
+		}
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return dedupeStrings(files), nil
+}
+
 func captureTaskGitMeta(project TrackedProject, task *BoardTask, cfg Config) (taskGitMeta, error) {
 	md, err := readTaskMD(project.StorageRoot, task.ID)
 	if err != nil {
@@ -89,7 +148,7 @@ func saveTaskGitMeta(project TrackedProject, taskID string, update func(*taskGit
 }
 
 func startTaskBranch(project TrackedProject, task *BoardTask, cfg Config) (taskGitMeta, error) {
-	base := gitBaseBranch(cfg)
+	base := projectBaseBranch(cfg, project)
 	branch := taskBranchName(task.ID, task.Title)
 	if _, err := runCmd(project.RepoPath, nil, "git", "checkout", base); err != nil {
 		return taskGitMeta{}, err
@@ -114,11 +173,42 @@ func startTaskBranch(project TrackedProject, task *BoardTask, cfg Config) (taskG
 	return meta, nil
 }
 
-func commitTaskChanges(project TrackedProject, task *BoardTask, msg string) (string, error) {
-	if _, err := runCmd(project.RepoPath, nil, "git", "add", "-A"); err != nil {
+// commitTaskChanges commits only the selected files (paths from
+// listWorkTreeChanges) after pre_commit_command passes, and records the SHA.
+func commitTaskChanges(project TrackedProject, task *BoardTask, cfg Config, files []string, msg string) (string, error) {
+	changes, err := listWorkTreeChanges(project.RepoPath)
+	if err != nil {
 		return "", err
 	}
-	if _, err := runCmd(project.RepoPath, nil, "git", "commit", "-m", msg); err != nil {
+	byPath := map[string]workTreeChange{}
+	for _, c := range changes {
+		byPath[c.Path] = c
+	}
+	var pathspec []string
+	for _, f := range files {
+		c, ok := byPath[f]
+		if !ok {
+			return "", fmt.Errorf("%s has no changes to commit", f)
+		}
+		pathspec = append(pathspec, c.Path)
+		if c.OrigPath != "" {
+			pathspec = append(pathspec, c.OrigPath)
+		}
+	}
+	if len(pathspec) == 0 {
+		return "", fmt.Errorf("select at least one file to commit")
+	}
+	if strings.TrimSpace(msg) == "" {
+		return "", fmt.Errorf("commit message is required")
+	}
+	if err := runPreCommit(project, task, cfg, files); err != nil {
+		return "", err
+	}
+	if _, err := runCmd(project.RepoPath, nil, "git", append([]string{"add", "-A", "--"}, pathspec...)...); err != nil {
+		return "", err
+	}
+	// With a pathspec, git commit takes only those paths; other staged work stays staged.
+	if _, err := runCmd(project.RepoPath, nil, "git", append([]string{"commit", "-m", withTaskTrailer(msg, task.ID), "--"}, pathspec...)...); err != nil {
 		return "", err
 	}
 	sha, err := runCmd(project.RepoPath, nil, "git", "rev-parse", "HEAD")
@@ -127,42 +217,43 @@ func commitTaskChanges(project TrackedProject, task *BoardTask, msg string) (str
 	}
 	if err := saveTaskGitMeta(project, task.ID, func(g *taskGitMeta) {
 		g.LastCommit = strings.TrimSpace(sha)
+		g.Commits = append(g.Commits, strings.TrimSpace(sha))
 	}); err != nil {
 		return "", err
 	}
 	return sha, nil
 }
 
-func openTaskPR(project TrackedProject, task *BoardTask, cfg Config, meta taskGitMeta) (string, error) {
+// openTaskPR pushes the task branch and opens its PR. A nil draft renders
+// templates/pr.md; the UI passes the user-edited preview instead.
+func openTaskPR(project TrackedProject, task *BoardTask, cfg Config, meta taskGitMeta, draft *prDraft) (string, error) {
 	branch := strings.TrimSpace(meta.Branch)
 	if branch == "" {
 		branch = taskBranchName(task.ID, task.Title)
 	}
 	base := strings.TrimSpace(meta.Base)
 	if base == "" {
-		base = gitBaseBranch(cfg)
+		base = projectBaseBranch(cfg, project)
 	}
 	if _, err := runCmd(project.RepoPath, nil, "git", "push", "-u", "origin", branch); err != nil {
 		return "", err
 	}
-	title := task.ID + ": " + task.Title
-	body := "Created by Hazel for task " + task.ID
-	ghArgs := []string{"pr", "create", "--base", base, "--head", branch, "--title", title, "--body", body}
-	if strings.TrimSpace(project.RepoSlug) != "" {
-		ghArgs = append(ghArgs, "--repo", strings.TrimSpace(project.RepoSlug))
+	if draft == nil {
+		d, err := renderPRDraft(project, task, branch, base)
+		if err != nil {
+			return "", err
+		}
+		draft = &d
 	}
-	env := []string{}
-	if tok := strings.TrimSpace(cfg.GitHubToken); tok != "" {
-		env = append(env, "GH_TOKEN="+tok)
+	title := strings.TrimSpace(draft.Title)
+	if title == "" {
+		title = task.ID + ": " + task.Title
 	}
-	out, err := runCmd(project.RepoPath, env, "gh", ghArgs...)
+	fg := projectForge(project, cfg)
+	prURL, err := fg.OpenPR(prRequest{Base: base, Head: branch, Title: title, Body: draft.Body})
 	if err != nil {
 		return "", err
 	}
-	prURL := strings.TrimSpace(strings.Fields(out)[0])
-	if !strings.HasPrefix(prURL, "http://") && !strings.HasPrefix(prURL, "https://") {
-		return "", fmt.Errorf("unable to parse PR URL from gh output: %q", out)
-	}
 	if err := saveTaskGitMeta(project, task.ID, func(g *taskGitMeta) {
 		g.Branch = branch
 		g.Base = base
@@ -170,6 +261,14 @@ func openTaskPR(project TrackedProject, task *BoardTask, cfg Config, meta taskGi
 	}); err != nil {
 		return "", err
 	}
+	_ = emitWebhookEvent(project.StorageRoot, webhookEventPROpened, map[string]any{
+		"task_id": task.ID,
+		"title":   title,
+		"branch":  branch,
+		"base":    base,
+		"pr_url":  prURL,
+		"forge":   fg.Kind(),
+	})
 	return prURL, nil
 }
 
@@ -178,8 +277,12 @@ func markTaskMerged(project TrackedProject, task *BoardTask, mergeSHA string) er
 	if sha == "" {
 		return fmt.Errorf("merge sha is required")
 	}
-	return saveTaskGitMeta(project, task.ID, func(g *taskGitMeta) {
+	if err := saveTaskGitMeta(project, task.ID, func(g *taskGitMeta) {
 		g.MergeSHA = sha
 		g.MergedAt = time.Now().UTC().Format(time.RFC3339)
-	})
+	}); err != nil {
+		return err
+	}
+	fireHook(project.StorageRoot, hookOnMerge, task.ID, map[string]string{"HAZEL_MERGE_SHA": sha})
+	return nil
 }
@@ -0,0 +1,276 @@
+package hazel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A task lives on its home project's board. task.Projects lists the other
+// nexus projects it spans; their git meta is kept in the home task.md under
+// hazel.repos.<key>. The same branch name is used in every repo, and the task
+// only reaches DONE once every repo has a merge SHA.
+
+// linkedPR is a PR opened in one linked project.
+type linkedPR struct {
+	Project string
+	URL     string
+}
+
+// normalizeLinkedProjects validates keys against the nexus and drops blanks,
+// duplicates and the home project itself.
+func normalizeLinkedProjects(nx *Nexus, home string, keys []string) ([]string, error) {
+	var out []string
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" || k == home || slices.Contains(out, k) {
+			continue
+		}
+		if nx == nil {
+			return nil, fmt.Errorf("linked projects require nexus mode")
+		}
+		if _, ok := nx.ProjectByKey(k); !ok {
+			return nil, fmt.Errorf("unknown project: %s", k)
+		}
+		out = append(out, k)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// linkedProjects resolves task.Projects to tracked projects.
+func linkedProjects(nx *Nexus, task *BoardTask) ([]TrackedProject, error) {
+	var out []TrackedProject
+	for _, k := range task.Projects {
+		if nx == nil {
+			return nil, fmt.Errorf("linked projects require nexus mode")
+		}
+		p, ok := nx.ProjectByKey(k)
+		if !ok {
+			return nil, fmt.Errorf("linked project not found: %s", k)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// setTaskLinkedProjects replaces the task's linked project list on the home board.
+func setTaskLinkedProjects(nx *Nexus, home TrackedProject, taskID string, keys []string) ([]string, error) {
+	keys, err := normalizeLinkedProjects(nx, home.Key, keys)
+	if err != nil {
+		return nil, err
+	}
+	var b Board
+	if err := readYAMLFile(boardPath(home.StorageRoot), &b); err != nil {
+		return nil, err
+	}
+	for _, t := range b.Tasks {
+		if t.ID == taskID {
+			t.Projects = keys
+			t.UpdatedAt = time.Now()
+			return keys, writeYAMLFile(boardPath(home.StorageRoot), &b)
+		}
+	}
+	return nil, fmt.Errorf("task not found: %s", taskID)
+}
+
+func getTaskReposFromMD(md string) map[string]taskGitMeta {
+	cfg, ok := getTaskConfig(md)
+	if !ok || cfg.Repos == nil {
+		return map[string]taskGitMeta{}
+	}
+	return cfg.Repos
+}
+
+func setTaskRepoGitInMD(md, key string, update func(*taskGitMeta)) (string, error) {
+	fm, has, without, err := parseHazelConfigBlock(md)
+	if err != nil {
+		return "", err
+	}
+	if fm.Hazel.Repos == nil {
+		fm.Hazel.Repos = map[string]taskGitMeta{}
+	}
+	g := fm.Hazel.Repos[key]
+	update(&g)
+	fm.Hazel.Repos[key] = g
+	block, err := formatHazelConfigBlock(fm)
+	if err != nil {
+		return "", err
+	}
+	base := md
+	if has {
+		base = without
+	}
+	return strings.TrimRight(base, " \t\r\n") + "\n" + block, nil
+}
+
+// saveTaskRepoGitMeta updates the git meta of one linked repo in the home task.md.
+func saveTaskRepoGitMeta(home TrackedProject, taskID, key string, update func(*taskGitMeta)) error {
+	md, err := readTaskMD(home.StorageRoot, taskID)
+	if err != nil {
+		return err
+	}
+	updated, err := setTaskRepoGitInMD(md, key, update)
+	if err != nil {
+		return err
+	}
+	if err := writeTaskMD(home.StorageRoot, taskID, updated); err != nil {
+		return err
+	}
+	return bumpBoardUpdatedAt(home.StorageRoot, taskID)
+}
+
+// startLinkedBranches creates the home branch (meta.Branch off meta.Base) in
+// every linked repo.
+func startLinkedBranches(home TrackedProject, linked []TrackedProject, task *BoardTask, meta taskGitMeta) error {
+	for _, p := range linked {
+		if _, err := runCmd(p.RepoPath, nil, "git", "checkout", meta.Base); err != nil {
+			return fmt.Errorf("%s: %w", p.Key, err)
+		}
+		_, _ = runCmd(p.RepoPath, nil, "git", "pull", "--ff-only", "origin", meta.Base)
+		if _, err := runCmd(p.RepoPath, nil, "git", "checkout", "-B", meta.Branch); err != nil {
+			return fmt.Errorf("%s: %w", p.Key, err)
+		}
+		if err := saveTaskRepoGitMeta(home, task.ID, p.Key, func(g *taskGitMeta) {
+			*g = taskGitMeta{Branch: meta.Branch, Base: meta.Base}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openLinkedPRs pushes the task branch and opens a PR in each linked repo that
+// has none yet. Already opened PRs are returned as well so the home PR can list them all.
+func openLinkedPRs(home TrackedProject, linked []TrackedProject, task *BoardTask, cfg Config, draft prDraft) ([]linkedPR, error) {
+	md, err := readTaskMD(home.StorageRoot, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	repos := getTaskReposFromMD(md)
+	var out []linkedPR
+	for _, p := range linked {
+		g := repos[p.Key]
+		if strings.TrimSpace(g.PRURL) != "" {
+			out = append(out, linkedPR{Project: p.Key, URL: g.PRURL})
+			continue
+		}
+		if strings.TrimSpace(g.Branch) == "" {
+			return out, fmt.Errorf("%s: no task branch; use Start Branch first", p.Key)
+		}
+		if _, err := runCmd(p.RepoPath, nil, "git", "push", "-u", "origin", g.Branch); err != nil {
+			return out, fmt.Errorf("%s: %w", p.Key, err)
+		}
+		// Only a branch with commits of its own has a tip worth recording: an
+		// empty branch points at the base, which local merge detection would
+		// take for an immediate merge.
+		tip := ""
+		if n, _ := runCmd(p.RepoPath, nil, "git", "rev-list", "--count", baseRef(p.RepoPath, g.Base)+".."+g.Branch); n != "" && n != "0" {
+			tip, _ = runCmd(p.RepoPath, nil, "git", "rev-parse", g.Branch)
+		}
+		body := strings.TrimRight(draft.Body, "\n") + "\n\nPart of " + home.Key + "/" + task.ID + "; merged together with the other repos of this task.\n"
+		fg := projectForge(p, cfg)
+		prURL, err := fg.OpenPR(prRequest{Base: g.Base, Head: g.Branch, Title: draft.Title, Body: body})
+		if err != nil {
+			return out, fmt.Errorf("%s: %w", p.Key, err)
+		}
+		if err := saveTaskRepoGitMeta(home, task.ID, p.Key, func(g *taskGitMeta) {
+			g.PRURL = prURL
+			if tip != "" {
+				g.LastCommit = tip
+			}
+		}); err != nil {
+			return out, err
+		}
+		_ = emitWebhookEvent(home.StorageRoot, webhookEventPROpened, map[string]any{
+			"task_id": task.ID,
+			"title":   draft.Title,
+			"branch":  g.Branch,
+			"base":    g.Base,
+			"pr_url":  prURL,
+			"forge":   fg.Kind(),
+			"project": p.Key,
+		})
+		out = append(out, linkedPR{Project: p.Key, URL: prURL})
+	}
+	return out, nil
+}
+
+// withLinkedPRs appends the linked PR list to a PR body.
+func withLinkedPRs(body string, prs []linkedPR) string {
+	if len(prs) == 0 {
+		return body
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(body, "\n"))
+	b.WriteString("\n\n## Linked PRs\n\n")
+	for _, pr := range prs {
+		if pr.URL == "" {
+			fmt.Fprintf(&b, "- %s: branch pushed (no compare URL)\n", pr.Project)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", pr.Project, pr.URL)
+	}
+	return b.String()
+}
+
+// linkedMergeBlock refuses DONE while any linked repo lacks a merge SHA.
+func linkedMergeBlock(projectRoot string, task *BoardTask) error {
+	if len(task.Projects) == 0 {
+		return nil
+	}
+	md, _ := readTaskMD(projectRoot, task.ID)
+	repos := getTaskReposFromMD(md)
+	var pending []string
+	for _, k := range task.Projects {
+		if strings.TrimSpace(repos[k].MergeSHA) == "" {
+			pending = append(pending, k)
+		}
+	}
+	if len(pending) > 0 {
+		return &transitionError{msg: "cannot move to DONE until every linked PR is merged; pending: " + strings.Join(pending, ", ")}
+	}
+	return nil
+}
+
+// markLinkedMerged records the merge of one linked repo and fires on_merge
+// with HAZEL_MERGE_PROJECT set to its key.
+func markLinkedMerged(home TrackedProject, task *BoardTask, key, mergeSHA string) error {
+	sha := strings.TrimSpace(mergeSHA)
+	if sha == "" {
+		return fmt.Errorf("merge sha is required")
+	}
+	if !slices.Contains(task.Projects, key) {
+		return fmt.Errorf("%s is not linked to %s", key, task.ID)
+	}
+	if err := saveTaskRepoGitMeta(home, task.ID, key, func(g *taskGitMeta) {
+		g.MergeSHA = sha
+		g.MergedAt = time.Now().UTC().Format(time.RFC3339)
+	}); err != nil {
+		return err
+	}
+	fireHook(home.StorageRoot, hookOnMerge, task.ID, map[string]string{"HAZEL_MERGE_SHA": sha, "HAZEL_MERGE_PROJECT": key})
+	return nil
+}
+
+// completeIfAllMerged moves the task to DONE once the home repo and every
+// linked repo are merged. It reports whether the task moved.
+func completeIfAllMerged(home TrackedProject, task *BoardTask) (bool, error) {
+	if task.Status == StatusDone {
+		return false, nil
+	}
+	md, err := readTaskMD(home.StorageRoot, task.ID)
+	if err != nil {
+		return false, err
+	}
+	meta, _ := getTaskGitFromMD(md)
+	if strings.TrimSpace(meta.MergeSHA) == "" || linkedMergeBlock(home.StorageRoot, task) != nil {
+		return false, nil
+	}
+	if err := bumpBoardTaskStatus(home.StorageRoot, task.ID, StatusDone); err != nil {
+		return false, err
+	}
+	task.Status = StatusDone
+	return true, nil
+}
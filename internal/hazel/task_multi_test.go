@@ -0,0 +1,122 @@
+package hazel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLinkedTaskDoneWaitsForEveryRepo(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0030", Title: "span", Status: StatusReview, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0030"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	apiRepo := t.TempDir()
+	testGitRepo(t, apiRepo)("remote", "add", "origin", "https://github.com/acme/api.git")
+	home := TrackedProject{Key: "app", RepoPath: t.TempDir(), StorageRoot: root}
+	nx := &Nexus{Projects: []TrackedProject{home, {Key: "api", RepoPath: apiRepo, StorageRoot: t.TempDir()}}}
+
+	if _, err := setTaskLinkedProjects(nx, home, "HZ-0030", []string{"nope"}); err == nil {
+		t.Fatalf("unknown project accepted")
+	}
+	keys, err := setTaskLinkedProjects(nx, home, "HZ-0030", []string{"api", "app", "api", ""})
+	if err != nil || len(keys) != 1 || keys[0] != "api" {
+		t.Fatalf("linked projects = %v, %v", keys, err)
+	}
+	task, _ := findTaskInBoard(root, "HZ-0030")
+
+	if err := markTaskMerged(home, task, "home123"); err != nil {
+		t.Fatalf("mark merged: %v", err)
+	}
+	if err := saveTaskRepoGitMeta(home, task.ID, "api", func(g *taskGitMeta) {
+		g.Branch, g.Base, g.PRURL = "task/hz-0030-span", "main", "https://github.com/acme/api/pull/3"
+	}); err != nil {
+		t.Fatalf("save repo meta: %v", err)
+	}
+	if _, err := transitionTaskStatus(root, task.ID, StatusDone); !isTransitionError(err) {
+		t.Fatalf("DONE with unmerged linked PR: err = %v", err)
+	}
+
+	fakeGH(t, `{"state":"MERGED","mergeCommit":{"oid":"api456"},"statusCheckRollup":[]}`)
+	res := &PRSyncResult{}
+	syncProjectPRs(context.Background(), nx, home, Config{}, res)
+	if len(res.Errors) != 0 || len(res.Changes) != 1 || res.Changes[0].Repo != "api" || !res.Changes[0].MovedDone {
+		t.Fatalf("linked sync: changes=%+v errors=%v", res.Changes, res.Errors)
+	}
+	md, _ := readTaskMD(root, task.ID)
+	if g := getTaskReposFromMD(md)["api"]; g.MergeSHA != "api456" || g.PRState != prStateMerged {
+		t.Fatalf("api meta = %+v", g)
+	}
+	if g, _ := getTaskGitFromMD(md); g.MergeSHA != "home123" {
+		t.Fatalf("home meta clobbered: %+v", g)
+	}
+	task, _ = findTaskInBoard(root, task.ID)
+	if task.Status != StatusDone {
+		t.Fatalf("status = %s, want DONE", task.Status)
+	}
+}
+
+func TestEmptyLinkedBranchIsNotMerged(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	now := time.Now()
+	b := &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0031", Title: "empty", Status: StatusReview, Projects: []string{"api"}, CreatedAt: now, UpdatedAt: now}}}
+	if err := writeYAMLFile(boardPath(root), b); err != nil {
+		t.Fatalf("write board: %v", err)
+	}
+	if err := ensureTaskScaffold(root, "HZ-0031"); err != nil {
+		t.Fatalf("scaffold: %v", err)
+	}
+	// The linked repo has no forge: origin's web URL is unknown to Hazel and
+	// pushes go to a local bare repo.
+	origin := t.TempDir()
+	apiRepo := t.TempDir()
+	git := testGitRepo(t, apiRepo)
+	git("init", "-q", "--bare", "-b", "main", origin)
+	git("remote", "add", "origin", "https://example.org/acme/api.git")
+	git("config", "remote.origin.pushurl", origin)
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("push", "-q", "origin", "main")
+	git("branch", "task/hz-0031-empty")
+
+	home := TrackedProject{Key: "app", RepoPath: t.TempDir(), StorageRoot: root}
+	api := TrackedProject{Key: "api", RepoPath: apiRepo, StorageRoot: t.TempDir()}
+	nx := &Nexus{Projects: []TrackedProject{home, api}}
+	task, _ := findTaskInBoard(root, "HZ-0031")
+	if err := markTaskMerged(home, task, "home123"); err != nil {
+		t.Fatalf("mark merged: %v", err)
+	}
+	if err := saveTaskRepoGitMeta(home, task.ID, "api", func(g *taskGitMeta) {
+		g.Branch, g.Base = "task/hz-0031-empty", "main"
+	}); err != nil {
+		t.Fatalf("save repo meta: %v", err)
+	}
+	prs, err := openLinkedPRs(home, []TrackedProject{api}, task, Config{}, prDraft{Title: "empty"})
+	if err != nil || len(prs) != 1 {
+		t.Fatalf("open linked PRs: %+v, %v", prs, err)
+	}
+	md, _ := readTaskMD(root, task.ID)
+	if g := getTaskReposFromMD(md)["api"]; g.LastCommit != "" {
+		t.Fatalf("last_commit recorded for an empty branch: %+v", g)
+	}
+
+	res := &PRSyncResult{}
+	syncProjectPRs(context.Background(), nx, home, Config{}, res)
+	md, _ = readTaskMD(root, task.ID)
+	if g := getTaskReposFromMD(md)["api"]; g.MergeSHA != "" {
+		t.Fatalf("empty branch recorded as merged: %+v", g)
+	}
+	if task, _ = findTaskInBoard(root, task.ID); task.Status != StatusReview {
+		t.Fatalf("status = %s, want REVIEW", task.Status)
+	}
+}
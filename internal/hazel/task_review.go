@@ -0,0 +1,256 @@
+package hazel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Review comments are inline notes on the task branch diff (base...branch),
+// stored in tasks/<id>/review.json. Unresolved comments can be sent to the
+// task's Codex session as a follow-up turn.
+
+const reviewFile = "review.json"
+
+type reviewComment struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Line is the line number on Side: "new" (branch) or "old" (base, for deleted lines).
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Code string `json:"code,omitempty"` // the diff line the comment was left on
+	Body string `json:"body"`
+	// Commit is the branch tip the comment was written against.
+	Commit    string    `json:"commit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Resolved  bool      `json:"resolved,omitempty"`
+	SentAt    time.Time `json:"sent_at,omitzero"`
+}
+
+type taskReview struct {
+	Comments []reviewComment `json:"comments"`
+}
+
+func reviewPath(root, taskID string) string {
+	return taskFile(root, taskID, reviewFile)
+}
+
+// readTaskReview returns an empty review when the task has none yet.
+func readTaskReview(root, taskID string) (*taskReview, error) {
+	b, err := os.ReadFile(reviewPath(root, taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return &taskReview{Comments: []reviewComment{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rev taskReview
+	if err := json.Unmarshal(b, &rev); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", reviewFile, err)
+	}
+	return &rev, nil
+}
+
+func updateTaskReview(root, taskID string, fn func(*taskReview) error) error {
+	rev, err := readTaskReview(root, taskID)
+	if err != nil {
+		return err
+	}
+	if err := fn(rev); err != nil {
+		return err
+	}
+	return writeFileAtomic(reviewPath(root, taskID), mustJSONIndent(rev), 0o644)
+}
+
+func (rev *taskReview) nextID() string {
+	n := 0
+	for _, c := range rev.Comments {
+		if v, err := strconv.Atoi(strings.TrimPrefix(c.ID, "c")); err == nil && v > n {
+			n = v
+		}
+	}
+	return "c" + strconv.Itoa(n+1)
+}
+
+func (rev *taskReview) unresolved() []reviewComment {
+	var out []reviewComment
+	for _, c := range rev.Comments {
+		if !c.Resolved {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// reviewDiffLine is one rendered diff line with its old/new line numbers (0 when absent).
+type reviewDiffLine struct {
+	Class string
+	Text  string
+	Old   int
+	New   int
+}
+
+type reviewDiffFile struct {
+	Path    string
+	OldPath string
+	Binary  bool
+	Adds    int
+	Dels    int
+	Lines   []reviewDiffLine
+}
+
+// parseReviewDiff splits a unified diff into files and numbers each line.
+func parseReviewDiff(patch string) []reviewDiffFile {
+	var files []reviewDiffFile
+	var cur *reviewDiffFile
+	oldN, newN := 0, 0
+	inHunks := false // past the file's first @@: "--- x" is a removed "-- x" line
+	for _, l := range strings.Split(strings.TrimRight(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			files = append(files, reviewDiffFile{})
+			cur = &files[len(files)-1]
+			inHunks = false
+			// "diff --git a/x b/y": the --- / +++ lines refine this below.
+			if i := strings.Index(l, " b/"); i >= 0 {
+				cur.Path = l[i+3:]
+				cur.OldPath = strings.TrimPrefix(l[len("diff --git "):i], "a/")
+			}
+			continue
+		case cur == nil:
+			continue
+		case inHunks: // file headers only come before the first hunk
+		case strings.HasPrefix(l, "--- "), strings.HasPrefix(l, "+++ "), strings.HasPrefix(l, "index "),
+			strings.HasPrefix(l, "new file"), strings.HasPrefix(l, "deleted file"), strings.HasPrefix(l, "similarity"),
+			strings.HasPrefix(l, "rename "), strings.HasPrefix(l, "old mode"), strings.HasPrefix(l, "new mode"):
+			if strings.HasPrefix(l, "+++ b/") {
+				cur.Path = strings.TrimPrefix(l, "+++ b/")
+			}
+			continue
+		case strings.HasPrefix(l, "Binary files "):
+			cur.Binary = true
+			continue
+		}
+		if strings.HasPrefix(l, "@@") {
+			// @@ -a,b +c,d @@
+			var a, c int
+			f := strings.Fields(l)
+			if len(f) >= 3 {
+				a, _ = strconv.Atoi(strings.SplitN(strings.TrimPrefix(f[1], "-"), ",", 2)[0])
+				c, _ = strconv.Atoi(strings.SplitN(strings.TrimPrefix(f[2], "+"), ",", 2)[0])
+			}
+			oldN, newN, inHunks = a, c, true
+			cur.Lines = append(cur.Lines, reviewDiffLine{Class: "d-hunk", Text: l})
+			continue
+		}
+		dl := reviewDiffLine{Text: l}
+		switch {
+		case strings.HasPrefix(l, "+"):
+			dl.Class, dl.New = "d-add", newN
+			newN++
+			cur.Adds++
+		case strings.HasPrefix(l, "-"):
+			dl.Class, dl.Old = "d-del", oldN
+			oldN++
+			cur.Dels++
+		case strings.HasPrefix(l, `\`):
+			dl.Class = "d-hunk"
+		default:
+			dl.Old, dl.New = oldN, newN
+			oldN++
+			newN++
+		}
+		cur.Lines = append(cur.Lines, dl)
+	}
+	return files
+}
+
+// taskBranchDiff returns `git diff <base>...<branch>` for the task branch and
+// the branch tip it was computed at. cfg is the nexus config (see projectBaseBranch).
+func taskBranchDiff(project TrackedProject, meta taskGitMeta, cfg Config) (patch, rng, tip string, err error) {
+	repo := project.RepoPath
+	branch := strings.TrimSpace(meta.Branch)
+	if branch == "" || !localBranchExists(repo, branch) {
+		return "", "", "", fmt.Errorf("task has no local branch; use Start Branch first")
+	}
+	base := strings.TrimSpace(meta.Base)
+	if base == "" {
+		base = projectBaseBranch(cfg, project)
+	}
+	rng = baseRef(repo, base) + "..." + branch
+	patch, err = runCmd(repo, nil, "git", "diff", "--no-color", "-M", rng)
+	if err != nil {
+		return "", rng, "", err
+	}
+	tip, _ = runCmd(repo, nil, "git", "rev-parse", branch)
+	return patch, rng, tip, nil
+}
+
+// reviewLineCode finds the text of the commented line in the diff.
+func reviewLineCode(files []reviewDiffFile, path, side string, line int) (string, bool) {
+	for _, f := range files {
+		if f.Path != path {
+			continue
+		}
+		for _, l := range f.Lines {
+			if (side == "old" && l.Old == line && l.New == 0) || (side != "old" && l.New == line) {
+				return l.Text, true
+			}
+		}
+	}
+	return "", false
+}
+
+// buildReviewPrompt turns unresolved comments into a follow-up agent turn.
+func buildReviewPrompt(task *BoardTask, rng string, comments []reviewComment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Code review for %s (%s) on `%s`.\n", task.ID, task.Title, rng)
+	b.WriteString("Address each comment below in the task branch, then update impl.md with what changed.\n")
+	for i, c := range comments {
+		where := fmt.Sprintf("%s:%d", c.Path, c.Line)
+		if c.Side == "old" {
+			where += " (removed line)"
+		}
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, where)
+		if code := strings.TrimSpace(c.Code); code != "" {
+			fmt.Fprintf(&b, "   > %s\n", c.Code)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(c.Body), "\n") {
+			fmt.Fprintf(&b, "   %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// sendReviewToAgent sends unresolved comments as a turn in the task's Codex
+// session (starting or resuming it) and stamps them with SentAt.
+func sendReviewToAgent(root string, task *BoardTask, rng string) (int, error) {
+	rev, err := readTaskReview(root, task.ID)
+	if err != nil {
+		return 0, err
+	}
+	open := rev.unresolved()
+	if len(open) == 0 {
+		return 0, fmt.Errorf("no unresolved review comments")
+	}
+	sess, err := startOrGetCodexSession(root, task.ID, false)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := sendCodexUserMessage(sess.SessionID, buildReviewPrompt(task, rng, open)); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	return len(open), updateTaskReview(root, task.ID, func(rev *taskReview) error {
+		for i := range rev.Comments {
+			if !rev.Comments[i].Resolved {
+				rev.Comments[i].SentAt = now
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskReviewDiffAndComments(t *testing.T) {
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "schema.sql"), []byte("-- drop me\nselect 1;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("checkout", "-q", "-b", "task/hz-0050-review")
+	if err := os.WriteFile(filepath.Join(repo, "a.go"), []byte("one\nTWO\nthree\nfour\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Removing "-- x" and adding "++ x" give body lines that look like headers.
+	if err := os.WriteFile(filepath.Join(repo, "schema.sql"), []byte("select 1;\n++ counter\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-q", "-am", "change")
+	git("checkout", "-q", "main")
+	git("branch", "-q", "-m", "main", "trunk")
+
+	// Without a recorded base the diff is against git_base_branch.
+	patch, rng, tip, err := taskBranchDiff(TrackedProject{RepoPath: repo}, taskGitMeta{Branch: "task/hz-0050-review"}, Config{GitBaseBranch: "trunk"})
+	if err != nil || rng != "trunk...task/hz-0050-review" || tip == "" {
+		t.Fatalf("diff: %q %q %v", rng, tip, err)
+	}
+	files := parseReviewDiff(patch)
+	if len(files) != 2 || files[0].Path != "a.go" || files[0].Adds != 2 || files[0].Dels != 1 {
+		t.Fatalf("files = %+v", files)
+	}
+	if sql := files[1]; sql.Path != "schema.sql" || sql.Adds != 1 || sql.Dels != 1 {
+		t.Fatalf("schema.sql = %+v", sql)
+	}
+	if code, ok := reviewLineCode(files, "schema.sql", "old", 1); !ok || code != "--- drop me" {
+		t.Fatalf("old line 1 = %q %v", code, ok)
+	}
+	if code, ok := reviewLineCode(files, "schema.sql", "new", 2); !ok || code != "+++ counter" {
+		t.Fatalf("new line 2 = %q %v", code, ok)
+	}
+	if code, ok := reviewLineCode(files, "a.go", "new", 4); !ok || code != "+four" {
+		t.Fatalf("new line 4 = %q %v", code, ok)
+	}
+	if code, ok := reviewLineCode(files, "a.go", "old", 2); !ok || code != "-two" {
+		t.Fatalf("old line 2 = %q %v", code, ok)
+	}
+	if _, ok := reviewLineCode(files, "a.go", "new", 9); ok {
+		t.Fatalf("line outside the diff found")
+	}
+
+	root := t.TempDir()
+	if err := ensureDir(filepath.Join(root, "tasks", "HZ-0050")); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []reviewComment{
+		{Path: "a.go", Line: 2, Side: "new", Code: "+TWO", Body: "keep lowercase"},
+		{Path: "a.go", Line: 4, Side: "new", Code: "+four", Body: "needed?"},
+	} {
+		if err := updateTaskReview(root, "HZ-0050", func(rev *taskReview) error {
+			c.ID, c.CreatedAt = rev.nextID(), time.Now()
+			rev.Comments = append(rev.Comments, c)
+			return nil
+		}); err != nil {
+			t.Fatalf("add comment: %v", err)
+		}
+	}
+	if err := updateTaskReview(root, "HZ-0050", func(rev *taskReview) error {
+		rev.Comments[1].Resolved = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rev, err := readTaskReview(root, "HZ-0050")
+	if err != nil || len(rev.Comments) != 2 || rev.Comments[1].ID != "c2" {
+		t.Fatalf("review = %+v, %v", rev, err)
+	}
+	open := rev.unresolved()
+	if len(open) != 1 || open[0].ID != "c1" {
+		t.Fatalf("unresolved = %+v", open)
+	}
+	prompt := buildReviewPrompt(&BoardTask{ID: "HZ-0050", Title: "review"}, rng, open)
+	if !strings.Contains(prompt, "1. a.go:2\n   > +TWO\n   keep lowercase") || strings.Contains(prompt, "needed?") {
+		t.Fatalf("prompt = %q", prompt)
+	}
+}
+
+func TestReviewTaskDiffUsesNexusBaseBranch(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	cfg.ProjectsRootDir, cfg.GitBaseBranch = "projects", "trunk"
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(root, "projects", "app")
+	if err := ensureDir(repo); err != nil {
+		t.Fatal(err)
+	}
+	git := testGitRepo(t, repo)
+	git("checkout", "-q", "-b", "trunk")
+	git("commit", "-q", "--allow-empty", "-m", "base")
+	git("branch", "task/hz-0051-review")
+	nx, err := LoadNexus(root)
+	if err != nil || len(nx.Projects) != 1 {
+		t.Fatalf("load nexus: %+v, %v", nx, err)
+	}
+	p := nx.Projects[0]
+	now := time.Now()
+	if err := writeYAMLFile(boardPath(p.StorageRoot), &Board{Version: 1, Tasks: []*BoardTask{{ID: "HZ-0051", Title: "review", Status: StatusActive, CreatedAt: now, UpdatedAt: now}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureTaskScaffold(p.StorageRoot, "HZ-0051"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveTaskGitMeta(p, "HZ-0051", func(g *taskGitMeta) { g.Branch = "task/hz-0051-review" }); err != nil {
+		t.Fatal(err)
+	}
+	if _, rng, _, err := reviewTaskDiff(p, "HZ-0051"); err != nil || rng != "trunk...task/hz-0051-review" {
+		t.Fatalf("range = %q, %v", rng, err)
+	}
+}
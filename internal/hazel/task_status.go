@@ -0,0 +1,113 @@
+package hazel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// transitionError reports a status change refused by a lifecycle guardrail
+// (as opposed to an I/O or lookup failure). Callers surface it as a client error.
+type transitionError struct {
+	msg string
+}
+
+func (e *transitionError) Error() string { return e.msg }
+
+func isTransitionError(err error) bool {
+	var te *transitionError
+	return errorAs(err, &te)
+}
+
+// transitionTaskStatus moves a board task to status after checking the
+// REVIEW/DONE guardrails. It is the entry point for human-driven status
+// changes (UI and API); runs and git actions skip the guardrails, which they
+// satisfy themselves, but still go through applyTaskStatus.
+func transitionTaskStatus(projectRoot, id string, status Status) (*BoardTask, error) {
+	if !status.Valid() {
+		return nil, &transitionError{msg: fmt.Sprintf("invalid status %q", status)}
+	}
+	var b Board
+	if err := readYAMLFile(boardPath(projectRoot), &b); err != nil {
+		return nil, err
+	}
+	var task *BoardTask
+	for _, t := range b.Tasks {
+		if t.ID == id {
+			task = t
+			break
+		}
+	}
+	if task == nil {
+		return nil, &transitionError{msg: "task not found: " + id}
+	}
+	if status == StatusReview || status == StatusDone {
+		md, _ := readTaskMD(projectRoot, id)
+		git, _ := getTaskGitFromMD(md)
+		if status == StatusReview && strings.TrimSpace(git.PRURL) == "" {
+			return nil, &transitionError{msg: "cannot move to REVIEW without PR URL; use Open PR in task Git Flow"}
+		}
+		if status == StatusReview {
+			if err := criteriaReviewBlock(projectRoot, id); err != nil {
+				return nil, err
+			}
+		}
+		if status == StatusDone && strings.TrimSpace(git.MergeSHA) == "" {
+			return nil, &transitionError{msg: "cannot move to DONE without merge SHA; use Mark Merged in task Git Flow"}
+		}
+		if status == StatusDone {
+			if err := linkedMergeBlock(projectRoot, task); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := applyTaskStatus(context.Background(), projectRoot, &b, task, status, "user"); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// applyTaskStatus is the one place a board task changes status: it runs the
+// pre_status:<STATUS> hook, which can veto the change, writes the board b that
+// task belongs to and notifies. The board is left untouched on a veto.
+func applyTaskStatus(ctx context.Context, projectRoot string, b *Board, task *BoardTask, status Status, source string) error {
+	from := task.Status
+	if from != status {
+		if err := runPreHook(ctx, projectRoot, hookPreStatus+string(status), task.ID, statusHookEnv(from, status)); err != nil {
+			return &transitionError{msg: "transition vetoed: " + err.Error()}
+		}
+	}
+	task.Status = status
+	task.UpdatedAt = time.Now()
+	if err := writeYAMLFile(boardPath(projectRoot), b); err != nil {
+		task.Status = from
+		return err
+	}
+	notifyTaskStatusChanged(projectRoot, task, from, source)
+	return nil
+}
+
+// notifyTaskStatusChanged emits the task.status_changed webhook and fires the
+// on_status:<STATUS> hook (both best-effort). REVIEW also starts the project
+// checks and DONE the merged branch cleanup and, when configured, the wiki distill.
+func notifyTaskStatusChanged(projectRoot string, task *BoardTask, from Status, source string) {
+	if from == task.Status {
+		return
+	}
+	fireHook(projectRoot, hookOnStatus+string(task.Status), task.ID, statusHookEnv(from, task.Status))
+	if task.Status == StatusReview {
+		startTaskChecks(projectRoot, task.ID, "review")
+	}
+	if task.Status == StatusDone {
+		cleanupMergedBranch(projectRoot, task.ID, true)
+		startTaskDistill(projectRoot, task.ID)
+	}
+	_ = emitWebhookEvent(projectRoot, webhookEventTaskStatusChanged, map[string]any{
+		"task_id": task.ID,
+		"title":   task.Title,
+		"from":    from,
+		"to":      task.Status,
+		"source":  source,
+	})
+}
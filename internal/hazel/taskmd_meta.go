@@ -28,18 +28,29 @@ type taskFrontmatter struct {
 }
 
 type taskHazelMeta struct {
-	Color    string      `yaml:"color"`
-	Priority string      `yaml:"priority"`
-	Git      taskGitMeta `yaml:"git,omitempty"`
+	Color    string      `yaml:"color" json:"color"`
+	Priority string      `yaml:"priority" json:"priority"`
+	Git      taskGitMeta `yaml:"git,omitempty" json:"git"`
+	// Repos holds the git meta of linked projects, keyed by project key.
+	Repos map[string]taskGitMeta `yaml:"repos,omitempty" json:"repos,omitempty"`
+	// Issue is set on tasks created by `hazel import github`.
+	Issue *taskIssueMeta `yaml:"issue,omitempty" json:"issue,omitempty"`
 }
 
 type taskGitMeta struct {
-	Branch     string `yaml:"branch,omitempty"`
-	Base       string `yaml:"base,omitempty"`
-	LastCommit string `yaml:"last_commit,omitempty"`
-	PRURL      string `yaml:"pr_url,omitempty"`
-	MergeSHA   string `yaml:"merge_sha,omitempty"`
-	MergedAt   string `yaml:"merged_at,omitempty"`
+	Branch     string   `yaml:"branch,omitempty" json:"branch,omitempty"`
+	Base       string   `yaml:"base,omitempty" json:"base,omitempty"`
+	LastCommit string   `yaml:"last_commit,omitempty" json:"last_commit,omitempty"`
+	Commits    []string `yaml:"commits,omitempty" json:"commits,omitempty"`
+	PRURL      string   `yaml:"pr_url,omitempty" json:"pr_url,omitempty"`
+	MergeSHA   string   `yaml:"merge_sha,omitempty" json:"merge_sha,omitempty"`
+	MergedAt   string   `yaml:"merged_at,omitempty" json:"merged_at,omitempty"`
+	// BranchDeletedAt is set once the task branch was removed locally and on origin.
+	BranchDeletedAt string `yaml:"branch_deleted_at,omitempty" json:"branch_deleted_at,omitempty"`
+	// Filled by the PR sync reconciler.
+	PRState  string `yaml:"pr_state,omitempty" json:"pr_state,omitempty"`
+	PRReview string `yaml:"pr_review,omitempty" json:"pr_review,omitempty"`
+	PRChecks string `yaml:"pr_checks,omitempty" json:"pr_checks,omitempty"`
 }
 
 var pastelPalette = []struct {
@@ -275,7 +286,10 @@ func getTaskGitFromMD(md string) (taskGitMeta, bool) {
 		strings.TrimSpace(g.LastCommit) == "" &&
 		strings.TrimSpace(g.PRURL) == "" &&
 		strings.TrimSpace(g.MergeSHA) == "" &&
-		strings.TrimSpace(g.MergedAt) == "" {
+		strings.TrimSpace(g.MergedAt) == "" &&
+		len(g.Commits) == 0 &&
+		strings.TrimSpace(g.BranchDeletedAt) == "" &&
+		strings.TrimSpace(g.PRState) == "" {
 		return taskGitMeta{}, false
 	}
 	return g, true
@@ -77,19 +77,43 @@ func Up(ctx context.Context, root string, opt UpOptions) (addr string, err error
 	mux.HandleFunc("/task/", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiTask(w, r, root, cfg, title, repoSlug, nx) }))
 	mux.HandleFunc("/panel/board", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiNexusBoardPanel(w, r, root, cfg, nx) }))
 	mux.HandleFunc("/chat", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiChat(w, r, root, nx) }))
+	mux.HandleFunc("/search", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiSearch(w, r, root, nx) }))
 	mux.HandleFunc("/wiki", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiWiki(w, r, root, nx) }))
+	mux.HandleFunc("/wiki/edit", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiWikiEdit(w, r, root, nx) }))
+	mux.HandleFunc("/wiki/history", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiWikiHistory(w, r, root, nx) }))
+	mux.HandleFunc("/wiki/preview", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiWikiPreview(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/wiki/save", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateWikiSave(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/wiki/rename", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateWikiRename(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/wiki/delete", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateWikiDelete(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/wiki/restore", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateWikiRestore(w, r, root, nx) }))
+	mux.HandleFunc("/webhooks", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiWebhooks(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/status", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateStatus(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/priority", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutatePriority(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/new_task", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateNewTask(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/task_md", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateTaskMD(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/task/projects", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateTaskProjects(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/task_color", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateTaskColor(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/plan", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutatePlan(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/plan_decision", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutatePlanDecision(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/distill", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateDistill(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/distill_decision", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateDistillDecision(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/interval", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateInterval(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/run/revert", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateRunRevert(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/run", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateRun(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/git/start", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitStart(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/git/commit", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitCommit(w, r, root, nx) }))
+	mux.HandleFunc("/git/commit", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiGitCommitPage(w, r, root, nx) }))
+	mux.HandleFunc("/git/preflight", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiGitPreflight(w, r, root, nx) }))
+	mux.HandleFunc("/git/review", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiGitReviewPage(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/review/comment", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateReviewComment(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/review/resolve", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateReviewResolve(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/review/send", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateReviewSend(w, r, root, nx) }))
+	mux.HandleFunc("/git/pr", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiGitPRPreview(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/git/rebase", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitRebase(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/git/branch-delete", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitBranchDelete(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/git/pr", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitPR(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/checks/run", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateChecksRun(w, r, root, nx) }))
+	mux.HandleFunc("/mutate/criteria/verify", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateCriteriaVerify(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/git/merge", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateGitMerge(w, r, root, nx) }))
 	mux.HandleFunc("/mutate/config", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiMutateConfig(w, r, root, nx) }))
 	mux.HandleFunc("/history", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { uiRuns(w, r, root, title, repoSlug, nx) }))
@@ -104,6 +128,7 @@ func Up(ctx context.Context, root string, opt UpOptions) (addr string, err error
 	mux.HandleFunc("/api/codex/turn", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { apiCodexTurn(w, r, root, nx) }))
 	mux.HandleFunc("/api/codex/approval", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { apiCodexApproval(w, r, root, nx) }))
 	mux.HandleFunc("/api/nexus/health", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { apiNexusHealth(w, r, root, nx) }))
+	mux.HandleFunc(apiV1Prefix+"/", withNexus(func(w http.ResponseWriter, r *http.Request, nx *Nexus) { apiV1(w, r, root, nx) }))
 
 	server := &http.Server{
 		Handler:           mux,
@@ -131,6 +156,8 @@ func Up(ctx context.Context, root string, opt UpOptions) (addr string, err error
 	// Always run the scheduler loop; it is a no-op unless enabled in config.
 	go schedulerLoop(ctx, root)
 	go runCodexTelemetryLoop(ctx, root)
+	go webhookDeliveryLoop(ctx, root)
+	go prSyncLoop(ctx, root)
 
 	go func() {
 		<-ctx.Done()
@@ -343,9 +370,10 @@ func uiTask(w http.ResponseWriter, r *http.Request, root string, cfg Config, tit
 	if k, ok := getTaskColorFromMD(taskMD); ok {
 		colorKey = k
 	}
+	links := loadWikiLinks(root, "")
 	renderMD := func(src string) template.HTML {
 		md := goldmark.New(
-			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithExtensions(extension.GFM, wikiLinkExtension{links: links}),
 			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
 			// Don't allow raw HTML passthrough in UI (default behavior).
 			goldmark.WithRendererOptions(),
@@ -410,6 +438,7 @@ func uiTask(w http.ResponseWriter, r *http.Request, root string, cfg Config, tit
 		"ChatAutoRun": chatAutoRun,
 		"ChatSession": chatSession,
 		"Git":         gitMeta,
+		"Backlinks":   wikiBacklinks(root, links, wikiLinkTarget{Kind: wikiLinkTask, TaskID: task.ID}),
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -467,33 +496,12 @@ func uiMutateStatus(w http.ResponseWriter, r *http.Request, root string, nexus *
 		return
 	}
 
-	var b Board
-	if err := readYAMLFile(boardPath(projectRoot), &b); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	now := time.Now()
-	for _, t := range b.Tasks {
-		if t.ID == id {
-			if status == StatusReview || status == StatusDone {
-				md, _ := readTaskMD(projectRoot, id)
-				git, _ := getTaskGitFromMD(md)
-				if status == StatusReview && strings.TrimSpace(git.PRURL) == "" {
-					http.Error(w, "cannot move to REVIEW without PR URL; use Open PR in task Git Flow", http.StatusBadRequest)
-					return
-				}
-				if status == StatusDone && strings.TrimSpace(git.MergeSHA) == "" {
-					http.Error(w, "cannot move to DONE without merge SHA; use Mark Merged in task Git Flow", http.StatusBadRequest)
-					return
-				}
-			}
-			t.Status = status
-			t.UpdatedAt = now
-			break
+	if _, err := transitionTaskStatus(projectRoot, id, status); err != nil {
+		code := http.StatusInternalServerError
+		if isTransitionError(err) {
+			code = http.StatusBadRequest
 		}
-	}
-	if err := writeYAMLFile(boardPath(projectRoot), &b); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), code)
 		return
 	}
 	if r.Header.Get("X-Hazel-Ajax") == "1" {
@@ -619,6 +627,23 @@ func uiMutateRun(w http.ResponseWriter, r *http.Request, root string, nexus *Nex
 		return
 	}
 
+	nexusCfg, _ := loadConfigOrDefault(root)
+	project := trackedProjectForRoot(projectRoot)
+	problems, err := resolvePreflight(preflightMode(cfg), []TrackedProject{project}, projectBaseBranch(nexusCfg, project), r.FormValue("preflight"), "run tick")
+	if err != nil || len(problems) > 0 {
+		target := preflightPageURL(projectKey, "", "run")
+		if err != nil {
+			target += "&error=" + url.QueryEscape(err.Error())
+		}
+		if r.Header.Get("X-Hazel-Ajax") == "1" {
+			// The board script follows this URL to show the preflight choices.
+			http.Error(w, target, http.StatusConflict)
+			return
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+
 	// Run asynchronously so the UI doesn't hang for long agent runs.
 	go func() {
 		res, err := RunTick(context.Background(), projectRoot, RunOptions{})
@@ -848,6 +873,7 @@ func uiMutatePlanDecision(w http.ResponseWriter, r *http.Request, root string, n
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		fireHook(projectRoot, hookOnPlanAccept, id, nil)
 	}
 
 	target := "/task/" + id
@@ -897,12 +923,35 @@ func uiMutateGitStart(w http.ResponseWriter, r *http.Request, root string, nexus
 		return
 	}
 	cfg, _ := loadConfigOrDefault(root)
-	if _, err := startTaskBranch(project, task, cfg); err != nil {
+	linked, err := linkedProjects(nexus, task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pcfg, _ := loadConfigOrDefault(project.StorageRoot)
+	repos := append([]TrackedProject{project}, linked...)
+	problems, err := resolvePreflight(preflightMode(pcfg), repos, projectBaseBranch(cfg, project), r.FormValue("preflight"), task.ID+" start branch")
+	if err != nil || len(problems) > 0 {
+		target := preflightPageURL(projectKey, task.ID, "start")
+		if err != nil {
+			target += "&error=" + url.QueryEscape(err.Error())
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+	meta, err := startTaskBranch(project, task, cfg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := startLinkedBranches(project, linked, task, meta); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	task.Status = StatusActive
-	_ = bumpBoardTaskStatus(project.StorageRoot, task.ID, StatusActive)
+	if err := bumpBoardTaskStatus(project.StorageRoot, task.ID, StatusActive); err != nil {
+		http.Error(w, "branch started, but the task did not move to ACTIVE: "+err.Error(), http.StatusBadRequest)
+		return
+	}
 	http.Redirect(w, r, "/task/"+projectKey+"/"+task.ID, http.StatusSeeOther)
 }
 
@@ -931,12 +980,36 @@ func uiMutateGitCommit(w http.ResponseWriter, r *http.Request, root string, nexu
 		http.Error(w, "unknown project", http.StatusBadRequest)
 		return
 	}
+	cfg, _ := loadConfigOrDefault(root)
 	msg := strings.TrimSpace(r.FormValue("message"))
 	if msg == "" {
-		msg = task.ID + ": " + task.Title
+		msg, err = renderCommitMessage(cfg, task, r.FormValue("type"), r.FormValue("subject"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
-	if _, err := commitTaskChanges(project, task, msg); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	files := r.PostForm["files"]
+	if _, ok := r.PostForm["files_listed"]; !ok {
+		// No file picker (API/old form): take every change that is not flagged risky.
+		changes, err := listWorkTreeChanges(project.RepoPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, c := range changes {
+			if c.Risky == "" {
+				files = append(files, c.Path)
+			}
+		}
+	}
+	commitPage := "/git/commit?project=" + url.QueryEscape(projectKey) + "&id=" + url.QueryEscape(task.ID)
+	err = withRepoLock(project.StorageRoot, func() error {
+		_, err := commitTaskChanges(project, task, cfg, files, msg)
+		return err
+	})
+	if err != nil {
+		http.Redirect(w, r, commitPage+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
 		return
 	}
 	http.Redirect(w, r, "/task/"+projectKey+"/"+task.ID, http.StatusSeeOther)
@@ -967,13 +1040,62 @@ func uiMutateGitPR(w http.ResponseWriter, r *http.Request, root string, nexus *N
 		http.Error(w, "unknown project", http.StatusBadRequest)
 		return
 	}
+	if err := criteriaReviewBlock(project.StorageRoot, task.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := checksPRBlock(project.StorageRoot, task.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	cfg, _ := loadConfigOrDefault(root)
 	meta, _ := captureTaskGitMeta(project, task, cfg)
-	if _, err := openTaskPR(project, task, cfg, meta); err != nil {
+	var draft *prDraft
+	if _, ok := r.PostForm["body"]; ok {
+		draft = &prDraft{Title: r.FormValue("title"), Body: r.FormValue("body")}
+	}
+	if len(task.Projects) > 0 {
+		// Linked repos go first so the home PR can list them.
+		linked, err := linkedProjects(nexus, task)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if draft == nil {
+			branch, base := strings.TrimSpace(meta.Branch), strings.TrimSpace(meta.Base)
+			if branch == "" {
+				branch = taskBranchName(task.ID, task.Title)
+			}
+			if base == "" {
+				base = projectBaseBranch(cfg, project)
+			}
+			d, err := renderPRDraft(project, task, branch, base)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			draft = &d
+		}
+		prs, err := openLinkedPRs(project, linked, task, cfg, *draft)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		draft.Body = withLinkedPRs(draft.Body, prs)
+	}
+	prURL, err := openTaskPR(project, task, cfg, meta, draft)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	_ = bumpBoardTaskStatus(project.StorageRoot, task.ID, StatusReview)
+	if err := bumpBoardTaskStatus(project.StorageRoot, task.ID, StatusReview); err != nil {
+		http.Error(w, "PR opened, but the task did not move to REVIEW: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if prURL == "" {
+		redirectTaskGit(w, r, project, task.ID, nil, "Branch pushed; origin has no web URL, so there is no compare URL to open the PR from")
+		return
+	}
 	http.Redirect(w, r, "/task/"+projectKey+"/"+task.ID, http.StatusSeeOther)
 }
 
@@ -1003,15 +1125,62 @@ func uiMutateGitMerge(w http.ResponseWriter, r *http.Request, root string, nexus
 		return
 	}
 	mergeSHA := strings.TrimSpace(r.FormValue("merge_sha"))
-	if mergeSHA == "" {
-		mergeSHA, _ = runCmd(project.RepoPath, nil, "git", "rev-parse", "HEAD")
+	repo := strings.TrimSpace(r.FormValue("repo"))
+	if repo != "" && repo != projectKey {
+		linked, ok := nexus.ProjectByKey(repo)
+		if !ok {
+			http.Error(w, "unknown project", http.StatusBadRequest)
+			return
+		}
+		if mergeSHA == "" {
+			mergeSHA, _ = runCmd(linked.RepoPath, nil, "git", "rev-parse", "HEAD")
+		}
+		err = markLinkedMerged(project, task, repo, mergeSHA)
+	} else {
+		if mergeSHA == "" {
+			mergeSHA, _ = runCmd(project.RepoPath, nil, "git", "rev-parse", "HEAD")
+		}
+		err = markTaskMerged(project, task, mergeSHA)
 	}
-	if err := markTaskMerged(project, task, mergeSHA); err != nil {
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	_ = bumpBoardTaskStatus(project.StorageRoot, task.ID, StatusDone)
-	http.Redirect(w, r, "/task/"+projectKey+"/"+task.ID, http.StatusSeeOther)
+	moved, err := completeIfAllMerged(project, task)
+	if err == nil && !moved && task.Status != StatusDone {
+		err = linkedMergeBlock(project.StorageRoot, task)
+	}
+	redirectTaskGit(w, r, project, task.ID, err, "")
+}
+
+func uiMutateCriteriaVerify(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	taskID := strings.TrimSpace(r.FormValue("id"))
+	if _, err := findTaskInBoard(projectRoot, taskID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Verification commands touch the working tree; never overlap an agent run.
+	if err := withRepoLock(projectRoot, func() error {
+		_, err := verifyTaskCriteria(r.Context(), projectRoot, taskID, "")
+		return err
+	}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/task/"+projectKey+"/"+taskID, http.StatusSeeOther)
 }
 
 func uiMutateConfig(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
@@ -1025,12 +1194,14 @@ func uiMutateConfig(w http.ResponseWriter, r *http.Request, root string, nexus *
 		return
 	}
 	cfg, _ := loadConfigOrDefault(root)
-	if strings.TrimSpace(r.FormValue("clear_github_token")) != "" {
-		cfg.GitHubToken = ""
+	migrateGitHubToken(&cfg)
+	if strings.TrimSpace(r.FormValue("clear_forge_tokens")) != "" {
+		cfg.ForgeTokens = nil
 	}
-	token := strings.TrimSpace(r.FormValue("github_token"))
-	if token != "" {
-		cfg.GitHubToken = token
+	for _, kind := range []string{forgeGitHub, forgeGitLab, forgeGitea} {
+		if token := strings.TrimSpace(r.FormValue("forge_token_" + kind)); token != "" {
+			setForgeToken(&cfg, kind, token)
+		}
 	}
 	cfg.GitBaseBranch = strings.TrimSpace(r.FormValue("git_base_branch"))
 	if cfg.GitBaseBranch == "" {
@@ -1047,25 +1218,19 @@ func uiMutateConfig(w http.ResponseWriter, r *http.Request, root string, nexus *
 	http.Redirect(w, r, target, http.StatusSeeOther)
 }
 
+// bumpBoardTaskStatus moves a task after a git action (branch start, PR open,
+// merge) has satisfied the guardrails; pre_status hooks still apply.
 func bumpBoardTaskStatus(projectRoot, taskID string, status Status) error {
 	var b Board
 	if err := readYAMLFile(boardPath(projectRoot), &b); err != nil {
 		return err
 	}
-	now := time.Now()
-	changed := false
 	for _, t := range b.Tasks {
 		if t.ID == taskID {
-			t.Status = status
-			t.UpdatedAt = now
-			changed = true
-			break
+			return applyTaskStatus(context.Background(), projectRoot, &b, t, status, "git")
 		}
 	}
-	if !changed {
-		return fmt.Errorf("task not found: %s", taskID)
-	}
-	return writeYAMLFile(boardPath(projectRoot), &b)
+	return fmt.Errorf("task not found: %s", taskID)
 }
 
 func bumpBoardUpdatedAt(root, id string) error {
@@ -1298,11 +1463,12 @@ func uiRuns(w http.ResponseWriter, r *http.Request, root string, title string, r
 	tpl := template.Must(template.New("runs").Parse(uiRunsHTML))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_ = tpl.Execute(w, map[string]any{
-		"Title":    title,
-		"RepoSlug": repoSlug,
-		"Rows":     rows,
-		"Project":  projectKey,
-		"Embed":    embed,
+		"Title":     title,
+		"RepoSlug":  repoSlug,
+		"Rows":      rows,
+		"AgentRuns": agentRunRows(projectRoot, 50),
+		"Project":   projectKey,
+		"Embed":     embed,
 	})
 }
 
@@ -1338,6 +1504,10 @@ func uiRunView(w http.ResponseWriter, r *http.Request, root string, title string
 	sessionPath := filepath.Join(chatSessionsDir(projectRoot), sessionName)
 	evs, err := loadChatSessionEvents(sessionPath)
 	if err != nil {
+		if logPath, ok := runLogPathForName(projectRoot, name); ok {
+			uiAgentRunView(w, r, projectRoot, projectKey, title, name, logPath)
+			return
+		}
 		http.NotFound(w, r)
 		return
 	}
@@ -1425,6 +1595,36 @@ const uiRunsHTML = `<!doctype html>
         </table>
       </div>
     </section>
+    {{if .AgentRuns}}
+    <section class="panel" style="margin-top:10px;">
+      <div class="row">
+        <div class="pill">Agent runs</div>
+        <div class="pill">{{len .AgentRuns}} recent</div>
+      </div>
+      <table>
+        <thead>
+          <tr>
+            <th>Run</th>
+            <th>Task</th>
+            <th>Mode</th>
+            <th>Exit</th>
+            <th>Changes</th>
+          </tr>
+        </thead>
+        <tbody>
+          {{range .AgentRuns}}
+            <tr>
+              <td><a class="link" href="/runs/{{.Name}}{{if $.Project}}?project={{$.Project}}{{end}}">{{if .StartedAt}}{{.StartedAt}}{{else}}{{.Name}}{{end}}</a></td>
+              <td>{{if .TaskID}}{{.TaskID}}{{else}}-{{end}}</td>
+              <td>{{if .Mode}}{{.Mode}}{{else}}-{{end}}</td>
+              <td>{{if .ExitCode}}{{.ExitCode}}{{else}}-{{end}}</td>
+              <td>{{if .Stat}}{{.Stat}}{{else}}-{{end}}{{if .Commits}} &middot; {{.Commits}} commit(s){{end}}{{if .Reverted}} &middot; reverted{{end}}</td>
+            </tr>
+          {{end}}
+        </tbody>
+      </table>
+    </section>
+    {{end}}
   </main>
   <script>
     (function(){
@@ -1827,7 +2027,10 @@ const uiBoardHTML = `<!doctype html>
       if (btn) { btn.disabled = true; btn.textContent = "Running..."; }
       const fd = new FormData(form);
       fetch(form.action, { method: "POST", body: new URLSearchParams(fd), headers: { "X-Hazel-Ajax": "1" } })
-        .then(() => {
+        .then((res) => {
+          if (res.status === 409) {
+            return res.text().then((u) => { location.href = u.trim(); });
+          }
           setTimeout(() => location.href = "/history", 250);
         })
         .catch(() => { if (btn) { btn.disabled = false; btn.textContent = "Run tick"; }});
@@ -1923,6 +2126,7 @@ const uiTaskHTML = `<!doctype html>
     .md { padding: 10px 12px; border-radius:4px; border:1px solid var(--line); background: rgba(0,0,0,.2); }
     .md :is(h1,h2,h3){ margin-top: 14px; }
     .md a { color: var(--link); }
+    .md .wikilink.broken { color:#ff6b6b; border-bottom:1px dashed #ff6b6b; }
     .md code { background: rgba(255,255,255,.08); padding: 1px 5px; border-radius:6px; }
     .md pre { background: rgba(0,0,0,.35); padding: 10px 12px; border-radius:4px; overflow:auto; }
     .topbar { display:flex; align-items:center; justify-content:space-between; gap:12px; }
@@ -1933,7 +2137,14 @@ const uiTaskHTML = `<!doctype html>
     .gitbar { display:grid; grid-template-columns: repeat(4, minmax(0,1fr)); gap:8px; }
     .gitbar form { margin:0; display:flex; gap:6px; align-items:center; }
     .gitbar input { width:100%; background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:7px 8px; font-size:11px; }
+    .matrix { width:100%; border-collapse:collapse; margin-top:8px; font-size:12px; }
+    .matrix th, .matrix td { text-align:left; padding:5px 8px; border-bottom:1px solid var(--line); vertical-align:top; }
+    .matrix th { font-size:10px; text-transform:uppercase; letter-spacing:.08em; color:var(--muted); font-weight:500; }
+    .matrix .muted { color:var(--muted); font-size:10px; }
+    .res-pass { color:#38d18f; }
+    .res-fail { color:#ff5f5f; }
     .gitmeta { margin-top:8px; display:flex; gap:8px; flex-wrap:wrap; font-size:10px; color:var(--muted); text-transform:uppercase; }
+    .gitwarn { margin:8px 0 0; font-size:11px; color:#ff6b6b; }
     @media (max-width: 1200px) { .gitbar { grid-template-columns: 1fr 1fr; } }
     @media (max-width: 760px) { .gitbar { grid-template-columns: 1fr; } }
     .editbar { display:flex; justify-content:space-between; align-items:center; gap:10px; flex-wrap:wrap; margin: 10px 0 8px; }
@@ -1963,16 +2174,20 @@ const uiTaskHTML = `<!doctype html>
           {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
           <button class="ghost" type="submit">Start Branch</button>
         </form>
-        <form action="/mutate/git/commit" method="post">
+        <form action="/git/commit" method="get">
+          <input type="hidden" name="id" value="{{.Task.ID}}" />
+          {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
+          <button class="ghost" type="submit">Commit…</button>
+        </form>
+        <form action="/git/review" method="get">
           <input type="hidden" name="id" value="{{.Task.ID}}" />
           {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
-          <input type="text" name="message" placeholder="Commit message (optional)" />
-          <button class="ghost" type="submit">Commit</button>
+          <button class="ghost" type="submit">Review…</button>
         </form>
-        <form action="/mutate/git/pr" method="post">
+        <form action="/git/pr" method="get">
           <input type="hidden" name="id" value="{{.Task.ID}}" />
           {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
-          <button class="ghost" type="submit">Open PR</button>
+          <button class="ghost" type="submit">Open PR…</button>
         </form>
         <form action="/mutate/git/merge" method="post">
           <input type="hidden" name="id" value="{{.Task.ID}}" />
@@ -1985,10 +2200,117 @@ const uiTaskHTML = `<!doctype html>
         <span>Branch: {{if .Git.Branch}}<code>{{.Git.Branch}}</code>{{else}}-{{end}}</span>
         <span>Base: {{if .Git.Base}}<code>{{.Git.Base}}</code>{{else}}-{{end}}</span>
         <span>Last Commit: {{if .Git.LastCommit}}<code>{{.Git.LastCommit}}</code>{{else}}-{{end}}</span>
+        {{if .Git.Commits}}<span title="{{range .Git.Commits}}{{.}}&#10;{{end}}">Commits: {{len .Git.Commits}}</span>{{end}}
         <span>PR: {{if .Git.PRURL}}<a href="{{.Git.PRURL}}" target="_blank" rel="noreferrer">{{.Git.PRURL}}</a>{{else}}-{{end}}</span>
         <span>Merge: {{if .Git.MergeSHA}}<code>{{.Git.MergeSHA}}</code>{{else}}-{{end}}</span>
+        {{with .BranchStatus}}{{if .Exists}}<span>vs <code>{{.Ref}}</code>: {{.Ahead}} ahead / {{.Behind}} behind</span>{{end}}{{end}}
+        {{if .Git.BranchDeletedAt}}<span>Branch deleted {{.Git.BranchDeletedAt}}</span>{{end}}
+        {{if .Git.PRState}}<span>PR State: <code>{{.Git.PRState}}</code>{{if .Git.PRReview}} / {{.Git.PRReview}}{{end}}{{if .Git.PRChecks}} / checks {{.Git.PRChecks}}{{end}}</span>{{end}}
       </div>
+      {{with .BranchStatus}}{{if .Exists}}
+      <div class="gitbar" style="margin-top:8px;">
+        <form action="/mutate/git/rebase" method="post">
+          <input type="hidden" name="id" value="{{$.Task.ID}}" />
+          <input type="hidden" name="project" value="{{$.Project}}" />
+          <label style="display:flex;gap:4px;align-items:center;font-size:10px;text-transform:uppercase;color:var(--muted);"><input type="checkbox" name="push" value="1" style="width:auto;" {{if $.Git.PRURL}}checked{{end}} />push</label>
+          <button class="ghost" type="submit" {{if eq .Behind 0}}disabled title="Already up to date"{{end}}>Rebase onto base</button>
+        </form>
+        <form action="/mutate/git/branch-delete" method="post" onsubmit="return confirm('Delete the task branch locally and on origin?')">
+          <input type="hidden" name="id" value="{{$.Task.ID}}" />
+          <input type="hidden" name="project" value="{{$.Project}}" />
+          <button class="ghost" type="submit">Delete branch</button>
+        </form>
+      </div>
+      {{end}}{{end}}
+      {{if .GitError}}<p class="gitwarn">{{.GitError}}</p>{{end}}
+      {{if .GitNotice}}<p class="gitmeta">{{.GitNotice}}</p>{{end}}
+      {{if and (eq .Git.PRState "CLOSED") (not .Git.MergeSHA)}}<p class="gitwarn">PR was closed without merging. Reopen it or open a new PR.</p>{{end}}
+    </section>
+    {{if .LinkCandidates}}
+    <section class="panel">
+      <h2>Projects</h2>
+      <form action="/mutate/task/projects" method="post" class="editbar">
+        <input type="hidden" name="id" value="{{.Task.ID}}" />
+        <input type="hidden" name="project" value="{{.Project}}" />
+        <span class="gitmeta" style="margin-top:0;">
+          {{range .LinkCandidates}}<label style="display:flex;gap:4px;align-items:center;"><input type="checkbox" name="projects" value="{{.Key}}" style="width:auto;" {{if index $.LinkedSet .Key}}checked{{end}} />{{.Name}}</label>{{end}}
+        </span>
+        <input type="hidden" name="projects" value="" />
+        <button class="ghost" type="submit">Save linked projects</button>
+      </form>
+      {{range .LinkedRepos}}
+      <div class="gitmeta">
+        <span><code>{{.Key}}</code></span>
+        <span>Branch: {{if .Git.Branch}}<code>{{.Git.Branch}}</code>{{else}}-{{end}}</span>
+        {{if .Status.Exists}}<span>vs <code>{{.Status.Ref}}</code>: {{.Status.Ahead}} ahead / {{.Status.Behind}} behind</span>{{end}}
+        <span>PR: {{if .Git.PRURL}}<a href="{{.Git.PRURL}}" target="_blank" rel="noreferrer">{{.Git.PRURL}}</a>{{else}}-{{end}}</span>
+        {{if .Git.PRState}}<span>PR State: <code>{{.Git.PRState}}</code>{{if .Git.PRReview}} / {{.Git.PRReview}}{{end}}{{if .Git.PRChecks}} / checks {{.Git.PRChecks}}{{end}}</span>{{end}}
+        <span>Merge: {{if .Git.MergeSHA}}<code>{{.Git.MergeSHA}}</code>{{else}}-{{end}}</span>
+      </div>
+      {{if not .Git.MergeSHA}}
+      <form action="/mutate/git/merge" method="post" class="gitbar" style="margin-top:6px;">
+        <input type="hidden" name="id" value="{{$.Task.ID}}" />
+        <input type="hidden" name="project" value="{{$.Project}}" />
+        <input type="hidden" name="repo" value="{{.Key}}" />
+        <input type="text" name="merge_sha" placeholder="{{.Key}} merge SHA (blank = HEAD)" />
+        <button class="ghost" type="submit">Mark {{.Key}} Merged</button>
+      </form>
+      {{end}}
+      {{if and (eq .Git.PRState "CLOSED") (not .Git.MergeSHA)}}<p class="gitwarn">{{.Key}} PR was closed without merging.</p>{{end}}
+      {{end}}
+      {{if .LinkedRepos}}<p class="gitmeta">Start Branch and Open PR act on every linked repo; the task reaches DONE once all PRs are merged.</p>{{end}}
+    </section>
+    {{end}}
+    {{if or .ChecksConfigured .Checks}}
+    <section class="panel">
+      <h2>Checks</h2>
+      <div class="editbar">
+        <span class="pill">{{with .Checks}}{{.Status}}{{if .Commit}} @ {{printf "%.8s" .Commit}}{{end}} ({{.Trigger}}, {{.StartedAt.Format "2006-01-02 15:04"}}){{else}}Not run yet{{end}}{{if .ChecksRequired}} · required for PR{{end}}</span>
+        <form action="/mutate/checks/run" method="post">
+          <input type="hidden" name="id" value="{{.Task.ID}}" />
+          {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
+          <button class="ghost" type="submit" {{if not .ChecksConfigured}}disabled title="No checks in project config"{{end}}>Run Checks</button>
+        </form>
+      </div>
+      {{with .Checks}}
+      <table class="matrix">
+        <tr><th>Check</th><th>Command</th><th>Result</th></tr>
+        {{range .Results}}
+        <tr>
+          <td>{{.Name}}</td>
+          <td><code>{{.Run}}</code></td>
+          <td class="res-{{.Status}}">{{.Status}}{{if .DurationMS}} <span class="muted">{{.DurationMS}}ms</span>{{end}}{{if .Error}} <span class="muted">{{.Error}}</span>{{end}}{{if .LogPath}}<br /><code class="muted">{{.LogPath}}</code>{{end}}</td>
+        </tr>
+        {{end}}
+      </table>
+      {{end}}
     </section>
+    {{end}}
+    {{if .Criteria}}
+    <section class="panel">
+      <h2>Acceptance Criteria</h2>
+      <div class="editbar">
+        <span class="pill">{{if .CriteriaCheckedAt}}Verified {{.CriteriaCheckedAt}}{{else}}Not verified yet{{end}}</span>
+        <form action="/mutate/criteria/verify" method="post">
+          <input type="hidden" name="id" value="{{.Task.ID}}" />
+          {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
+          <button class="ghost" type="submit">Verify</button>
+        </form>
+      </div>
+      <table class="matrix">
+        <tr><th>ID</th><th>Criterion</th><th>Verify</th><th>Result</th><th>Impl checklist</th></tr>
+        {{range .Criteria}}
+        <tr>
+          <td><code>{{.ID}}</code></td>
+          <td>{{if .Checked}}&#9745;{{else}}&#9744;{{end}} {{.Text}}{{if .Optional}} <span class="muted">(optional)</span>{{end}}</td>
+          <td>{{if .Verify}}<code>{{.Verify}}</code>{{else}}<span class="muted">manual</span>{{end}}</td>
+          <td class="res-{{.Status}}">{{.Status}}{{if .Stale}} <span class="muted">(stale)</span>{{end}}{{if .Detail}} <span class="muted">{{.Detail}}</span>{{end}}{{if .LogPath}}<br /><code class="muted">{{.LogPath}}</code>{{end}}</td>
+          <td>{{range .Impl}}<div>{{if .Checked}}&#9745;{{else}}&#9744;{{end}} {{.Text}}</div>{{else}}<span class="muted">unmapped</span>{{end}}</td>
+        </tr>
+        {{end}}
+      </table>
+    </section>
+    {{end}}
     <div class="split">
       <section class="panel">
         <h2>Task</h2>
@@ -2040,6 +2362,53 @@ const uiTaskHTML = `<!doctype html>
         {{end}}
       </section>
     </div>
+    {{with .Distill}}{{if or .Proposal .Notice .Error (eq $.Task.Status "DONE")}}
+    <section class="panel">
+      <h2>Wiki Distill</h2>
+      <div class="editbar">
+        <span class="pill">{{with .Proposal}}{{.Method}} proposal for <a href="/wiki?project={{$.Project}}&amp;file={{.Page}}">wiki/{{.Page}}</a>{{else}}distill: {{.Mode}}{{end}}</span>
+        <div class="row">
+          {{if .Proposal}}
+          <form action="/mutate/distill_decision" method="post">
+            <input type="hidden" name="id" value="{{$.Task.ID}}" />
+            {{if $.Project}}<input type="hidden" name="project" value="{{$.Project}}" />{{end}}
+            <input type="hidden" name="decision" value="accept" />
+            <button class="ghost" type="submit">Accept Into Wiki</button>
+          </form>
+          <form action="/mutate/distill_decision" method="post">
+            <input type="hidden" name="id" value="{{$.Task.ID}}" />
+            {{if $.Project}}<input type="hidden" name="project" value="{{$.Project}}" />{{end}}
+            <input type="hidden" name="decision" value="decline" />
+            <button class="ghost" type="submit">Decline</button>
+          </form>
+          {{end}}
+          <form action="/mutate/distill" method="post">
+            <input type="hidden" name="id" value="{{$.Task.ID}}" />
+            {{if $.Project}}<input type="hidden" name="project" value="{{$.Project}}" />{{end}}
+            <button class="ghost" type="submit">{{if .Proposal}}Redo{{else}}Distill{{end}}</button>
+          </form>
+          {{if .Agent}}
+          <form action="/mutate/distill" method="post">
+            <input type="hidden" name="id" value="{{$.Task.ID}}" />
+            {{if $.Project}}<input type="hidden" name="project" value="{{$.Project}}" />{{end}}
+            <input type="hidden" name="agent" value="1" />
+            <button class="ghost" type="submit">Distill with {{$.AgentName}}</button>
+          </form>
+          {{end}}
+        </div>
+      </div>
+      {{if .Error}}<p class="gitwarn">{{.Error}}</p>{{end}}
+      {{if .Notice}}<p class="gitmeta">{{.Notice}}</p>{{end}}
+      {{with .Proposal}}{{if .Note}}<p class="gitwarn">Agent distill fell back to extraction: {{.Note}}</p>{{end}}{{end}}
+      {{if .Proposal}}<div class="md">{{.HTML}}</div>{{end}}
+    </section>
+    {{end}}{{end}}
+    <section class="panel">
+      <h2>Backlinks</h2>
+      <div class="gitmeta" style="margin-top:0;text-transform:none;font-size:12px;">
+        {{range .Backlinks}}<span>{{if .Href}}<a href="{{.Href}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</span>{{else}}<span>No wiki pages or tasks link to {{.Task.ID}} yet. Link with <code>[[{{.Task.ID}}]]</code>.</span>{{end}}
+      </div>
+    </section>
   </main>
   <footer>
     <span>Duchess_Operator_OS</span>
@@ -0,0 +1,31 @@
+package hazel
+
+import "net/http"
+
+// checksReportForPage returns the last checks report with log paths relative
+// to the project state root (nil when checks never ran).
+func checksReportForPage(root, taskID string) *checksReport {
+	rep, err := readChecksReport(root, taskID)
+	if err != nil {
+		return nil
+	}
+	for i := range rep.Results {
+		if rep.Results[i].LogPath != "" {
+			rep.Results[i].LogPath = rel(root, rep.Results[i].LogPath)
+		}
+	}
+	return rep
+}
+
+// uiMutateChecksRun starts the project checks for a task in the background.
+func uiMutateChecksRun(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	if !startTaskChecks(project.StorageRoot, task.ID, "manual") {
+		http.Error(w, "no checks configured in project config", http.StatusBadRequest)
+		return
+	}
+	redirectTaskGit(w, r, project, task.ID, nil, "Checks started; reload for results")
+}
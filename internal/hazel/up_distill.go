@@ -0,0 +1,101 @@
+package hazel
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// distillView is the Wiki Distill panel on the task page.
+type distillView struct {
+	Proposal *distillProposal
+	HTML     template.HTML
+	Mode     string
+	Agent    bool // an agent command is configured for distill
+	Notice   string
+	Error    string
+}
+
+func distillViewForPage(r *http.Request, cfg Config, root string, links *wikiLinks, taskID string) *distillView {
+	v := &distillView{
+		Mode:   distillMode(cfg),
+		Agent:  agentCommandForMode(cfg, "distill") != "",
+		Notice: strings.TrimSpace(r.URL.Query().Get("distill")),
+		Error:  strings.TrimSpace(r.URL.Query().Get("distill_error")),
+	}
+	if p, body, err := readDistillProposal(root, taskID); err == nil {
+		_, page, _ := splitWikiFrontmatter([]byte(body))
+		v.Proposal, v.HTML = p, renderWikiMarkdown(page, links)
+	}
+	return v
+}
+
+func distillTaskForm(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) (string, string, string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", "", "", false
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", "", false
+	}
+	id := strings.TrimSpace(r.FormValue("id"))
+	if id == "" {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return "", "", "", false
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", "", false
+	}
+	if _, err := findTaskInBoard(projectRoot, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", "", false
+	}
+	return projectRoot, projectKey, id, true
+}
+
+func redirectTaskDistill(w http.ResponseWriter, r *http.Request, projectKey, id string, err error, notice string) {
+	target := "/task/" + id
+	if projectKey != "" {
+		target = "/task/" + projectKey + "/" + id
+	}
+	if err != nil {
+		target += "?distill_error=" + url.QueryEscape(err.Error())
+	} else {
+		target += "?distill=" + url.QueryEscape(notice)
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// uiMutateDistill starts a distill in the background; agent=1 asks the agent.
+func uiMutateDistill(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	projectRoot, projectKey, id, ok := distillTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	agent := r.FormValue("agent") == "1"
+	go func() { _, _ = Distill(context.Background(), projectRoot, id, DistillOptions{Agent: agent}) }()
+	redirectTaskDistill(w, r, projectKey, id, nil, "Distill started; reload for the proposal")
+}
+
+func uiMutateDistillDecision(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	projectRoot, projectKey, id, ok := distillTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(r.FormValue("decision"))) {
+	case "accept":
+		wikiMu.Lock()
+		page, err := acceptDistillProposal(projectRoot, id)
+		wikiMu.Unlock()
+		redirectTaskDistill(w, r, projectKey, id, err, "Saved to wiki/"+page)
+	case "decline":
+		redirectTaskDistill(w, r, projectKey, id, clearDistillProposal(projectRoot, id), "Proposal declined")
+	default:
+		http.Error(w, "invalid decision", http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,83 @@
+package hazel
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// gitTaskForm resolves the project and task named by a git mutate form.
+func gitTaskForm(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) (TrackedProject, *BoardTask, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return TrackedProject{}, nil, false
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return TrackedProject{}, nil, false
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return TrackedProject{}, nil, false
+	}
+	task, err := findTaskInBoard(projectRoot, strings.TrimSpace(r.FormValue("id")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return TrackedProject{}, nil, false
+	}
+	project, ok := nexus.ProjectByKey(projectKey)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return TrackedProject{}, nil, false
+	}
+	return project, task, true
+}
+
+func redirectTaskGit(w http.ResponseWriter, r *http.Request, project TrackedProject, taskID string, err error, notice string) {
+	target := "/task/" + project.Key + "/" + taskID
+	if err != nil {
+		target += "?git_error=" + url.QueryEscape(err.Error())
+	} else if notice != "" {
+		target += "?git_notice=" + url.QueryEscape(notice)
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func uiMutateGitRebase(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	md, err := readTaskMD(project.StorageRoot, task.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	meta, _ := getTaskGitFromMD(md)
+	var st branchStatus
+	err = withRepoLock(project.StorageRoot, func() error {
+		var rerr error
+		st, rerr = rebaseTaskBranch(project, meta, strings.TrimSpace(r.FormValue("push")) != "")
+		return rerr
+	})
+	redirectTaskGit(w, r, project, task.ID, err, "Rebased onto "+st.Ref)
+}
+
+func uiMutateGitBranchDelete(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	md, err := readTaskMD(project.StorageRoot, task.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	meta, _ := getTaskGitFromMD(md)
+	cfg, _ := loadConfigOrDefault(root)
+	err = withRepoLock(project.StorageRoot, func() error {
+		return deleteTaskBranch(project, task.ID, meta, cfg, false)
+	})
+	redirectTaskGit(w, r, project, task.ID, err, "Deleted branch "+meta.Branch)
+}
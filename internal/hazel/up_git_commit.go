@@ -0,0 +1,163 @@
+package hazel
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+type commitFileRow struct {
+	workTreeChange
+	Checked bool
+	Diff    []diffLine
+	Clipped bool
+}
+
+// uiGitCommitPage lists the repo's changed files with include checkboxes and
+// per-file diffs, and renders the commit message template for the task.
+func uiGitCommitPage(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	taskID := strings.TrimSpace(r.URL.Query().Get("id"))
+	task, err := findTaskInBoard(projectRoot, taskID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	project, ok := nexus.ProjectByKey(projectKey)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	errMsg := strings.TrimSpace(r.URL.Query().Get("error"))
+	changes, err := listWorkTreeChanges(project.RepoPath)
+	if err != nil && errMsg == "" {
+		errMsg = err.Error()
+	}
+	const maxDiffLines = 300
+	rows := make([]commitFileRow, 0, len(changes))
+	for _, c := range changes {
+		row := commitFileRow{workTreeChange: c, Checked: c.Risky == ""}
+		lines := classifyDiffLines(workTreeFileDiff(project.RepoPath, c))
+		if len(lines) > maxDiffLines {
+			lines = lines[:maxDiffLines]
+			row.Clipped = true
+		}
+		row.Diff = lines
+		rows = append(rows, row)
+	}
+	msg, terr := renderCommitMessage(cfg, task, "", "")
+	if terr != nil && errMsg == "" {
+		errMsg = terr.Error()
+	}
+	branch, _ := runCmd(project.RepoPath, nil, "git", "rev-parse", "--abbrev-ref", "HEAD")
+
+	tpl := template.Must(template.New("git_commit").Parse(uiGitCommitHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Project":   projectKey,
+		"Task":      task,
+		"Branch":    branch,
+		"Files":     rows,
+		"Types":     commitTypes,
+		"Message":   msg,
+		"PreCommit": strings.TrimSpace(cfg.PreCommitCommand),
+		"Error":     errMsg,
+	})
+}
+
+const uiGitCommitHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Commit - {{.Task.ID}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    .meta { margin-top:6px; display:flex; gap:6px; flex-wrap:wrap; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:3px 7px; font-size:10px; text-transform:uppercase; background:rgba(0,0,0,.2); }
+    main { padding:10px; display:grid; gap:10px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    label { font-size:10px; text-transform:uppercase; letter-spacing:.1em; color:var(--muted); }
+    input[type=text], select, textarea { background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:7px 8px; font-size:12px; }
+    textarea { width:100%; min-height:90px; font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; }
+    .msgrow { display:grid; grid-template-columns: 140px 1fr; gap:8px; margin-bottom:8px; }
+    .file { border-bottom:1px solid rgba(255,255,255,.08); padding:4px 0; }
+    .file summary { cursor:pointer; display:flex; gap:8px; align-items:center; font-size:12px; list-style:none; }
+    .file code { font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; }
+    .st { color:var(--muted); font-family: ui-monospace, monospace; font-size:11px; width:2.5em; }
+    .risky { color:#facc15; font-size:10px; text-transform:uppercase; }
+    pre { margin:6px 0 0; white-space:pre-wrap; word-break:break-word; background: rgba(0,0,0,.22); border:1px solid var(--line); border-radius:4px; padding:8px; max-height:40vh; overflow:auto; font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; font-size:12px; }
+    .d-file { color:#facc15; } .d-hunk { color:var(--accent); } .d-add { color:#38d18f; } .d-del { color:#ff6b6b; }
+    .row { display:flex; gap:8px; align-items:center; justify-content:space-between; margin-top:8px; flex-wrap:wrap; }
+    button { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; }
+    .err { color:#ff6b6b; font-size:12px; margin:0 0 8px; }
+    .muted { color:var(--muted); font-size:11px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/task/{{.Project}}/{{.Task.ID}}">Back to task</a>
+    <h1>Commit: {{.Task.ID}}</h1>
+    <div class="meta">
+      {{if .Branch}}<span class="pill">{{.Branch}}</span>{{end}}
+      <span class="pill">{{len .Files}} changed file(s)</span>
+    </div>
+  </header>
+  <main>
+    <form action="/mutate/git/commit" method="post">
+      <input type="hidden" name="id" value="{{.Task.ID}}" />
+      <input type="hidden" name="project" value="{{.Project}}" />
+      <input type="hidden" name="files_listed" value="1" />
+      <section class="panel">
+        <h2>Message</h2>
+        {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+        <div class="msgrow">
+          <select name="type" id="ctype">
+            {{range .Types}}<option value="{{.}}">{{.}}</option>{{end}}
+          </select>
+          <input type="text" name="subject" id="csubject" value="{{.Task.Title}}" />
+        </div>
+        <label for="cmsg">Full message (leave empty to use the template)</label>
+        <textarea id="cmsg" name="message" placeholder="{{.Message}}"></textarea>
+        <p class="muted">A <code>Hazel-Task: {{.Task.ID}}</code> trailer is added automatically.{{if .PreCommit}} Runs <code>{{.PreCommit}}</code> first; a non-zero exit aborts the commit.{{end}}</p>
+      </section>
+      <section class="panel">
+        <h2>Files</h2>
+        {{if not .Files}}<p class="muted">Working tree is clean.</p>{{end}}
+        {{range .Files}}
+          <details class="file">
+            <summary>
+              <input type="checkbox" name="files" value="{{.Path}}" {{if .Checked}}checked{{end}} onclick="event.stopPropagation()" />
+              <span class="st">{{.Status}}</span>
+              <code>{{if .OrigPath}}{{.OrigPath}} → {{end}}{{.Path}}</code>
+              {{if .Risky}}<span class="risky">{{.Risky}}</span>{{end}}
+            </summary>
+            <pre>{{range .Diff}}<span class="{{.Class}}">{{.Text}}</span>
+{{end}}{{if .Clipped}}… (diff clipped){{end}}</pre>
+          </details>
+        {{end}}
+        <div class="row">
+          <span class="muted">Files flagged as secrets or build output start unchecked.</span>
+          <button type="submit">Commit selected</button>
+        </div>
+      </section>
+    </form>
+  </main>
+</body>
+</html>`
@@ -0,0 +1,141 @@
+package hazel
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// uiGitPRPreview renders templates/pr.md for a task and lets the user edit the
+// title/body before /mutate/git/pr pushes the branch and opens the PR.
+func uiGitPRPreview(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	taskID := strings.TrimSpace(r.URL.Query().Get("id"))
+	task, err := findTaskInBoard(projectRoot, taskID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	project, ok := nexus.ProjectByKey(projectKey)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return
+	}
+	cfg, _ := loadConfigOrDefault(root)
+	meta, _ := captureTaskGitMeta(project, task, cfg)
+	branch := strings.TrimSpace(meta.Branch)
+	if branch == "" {
+		branch = taskBranchName(task.ID, task.Title)
+	}
+	base := strings.TrimSpace(meta.Base)
+	if base == "" {
+		base = projectBaseBranch(cfg, project)
+	}
+	blocked := criteriaReviewBlock(project.StorageRoot, task.ID)
+	if blocked == nil {
+		blocked = checksPRBlock(project.StorageRoot, task.ID)
+	}
+	draft, renderErr := renderPRDraft(project, task, branch, base)
+	errMsg := ""
+	if renderErr != nil {
+		errMsg = renderErr.Error()
+		draft = prDraft{Title: task.ID + ": " + task.Title}
+	}
+	var preview strings.Builder
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	_ = md.Convert([]byte(draft.Body), &preview)
+
+	tpl := template.Must(template.New("git_pr").Parse(uiGitPRHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Project":  projectKey,
+		"Task":     task,
+		"Branch":   branch,
+		"Base":     base,
+		"Forge":    projectForge(project, cfg).Kind(),
+		"Draft":    draft,
+		"Preview":  template.HTML(preview.String()),
+		"Error":    errMsg,
+		"Template": rel(project.StorageRoot, prTemplatePath(project.StorageRoot)),
+		"Blocked":  blocked,
+	})
+}
+
+const uiGitPRHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Open PR - {{.Task.ID}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    .meta { margin-top:6px; display:flex; gap:6px; flex-wrap:wrap; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:3px 7px; font-size:10px; text-transform:uppercase; background:rgba(0,0,0,.2); }
+    main { padding:10px; display:grid; grid-template-columns: minmax(0,1fr) minmax(0,1fr); gap:10px; }
+    @media (max-width: 900px) { main { grid-template-columns: 1fr; } }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    label { display:block; font-size:10px; text-transform:uppercase; letter-spacing:.1em; color:var(--muted); margin:0 0 4px; }
+    input, textarea { width:100%; background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:8px; font-size:12px; }
+    textarea { min-height:60vh; font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; resize:vertical; }
+    .row { display:flex; gap:8px; align-items:center; justify-content:space-between; margin-top:8px; flex-wrap:wrap; }
+    button { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; }
+    button[disabled] { opacity:.5; cursor:not-allowed; }
+    .md { font-size:13px; line-height:1.5; overflow:auto; max-height:75vh; }
+    .md code, .md pre { font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; font-size:12px; }
+    .md pre { background: rgba(0,0,0,.22); border:1px solid var(--line); border-radius:4px; padding:8px; overflow:auto; }
+    .err { color:#ff6b6b; font-size:12px; margin:0 0 8px; }
+    .muted { color:var(--muted); font-size:11px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/task/{{.Project}}/{{.Task.ID}}">Back to task</a>
+    <h1>Open PR: {{.Task.ID}}</h1>
+    <div class="meta">
+      <span class="pill">{{.Forge}}</span>
+      <span class="pill">{{.Branch}} → {{.Base}}</span>
+    </div>
+  </header>
+  <main>
+    <section class="panel">
+      <h2>Edit</h2>
+      {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+      {{if .Blocked}}<p class="err">{{.Blocked}}</p>{{end}}
+      <form action="/mutate/git/pr" method="post">
+        <input type="hidden" name="id" value="{{.Task.ID}}" />
+        <input type="hidden" name="project" value="{{.Project}}" />
+        <label for="title">Title</label>
+        <input id="title" type="text" name="title" value="{{.Draft.Title}}" />
+        <label for="body" style="margin-top:8px;">Body (Markdown)</label>
+        <textarea id="body" name="body">{{.Draft.Body}}</textarea>
+        <div class="row">
+          <span class="muted">Rendered from {{.Template}}</span>
+          <button type="submit" {{if .Blocked}}disabled{{end}}>Push branch &amp; open PR</button>
+        </div>
+      </form>
+    </section>
+    <section class="panel">
+      <h2>Preview</h2>
+      <div class="md">{{.Preview}}</div>
+    </section>
+  </main>
+</body>
+</html>`
@@ -0,0 +1,370 @@
+package hazel
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type reviewLineRow struct {
+	reviewDiffLine
+	Side     string
+	Line     int
+	Comments []reviewComment
+}
+
+type reviewFileRow struct {
+	reviewDiffFile
+	Rows     []reviewLineRow
+	Comments int
+	Clipped  bool
+}
+
+func reviewPageURL(projectKey, taskID string) string {
+	return "/git/review?project=" + url.QueryEscape(projectKey) + "&id=" + url.QueryEscape(taskID)
+}
+
+// reviewTaskDiff loads the task branch diff for a review form or page.
+func reviewTaskDiff(project TrackedProject, taskID string) ([]reviewDiffFile, string, string, error) {
+	md, err := readTaskMD(project.StorageRoot, taskID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	meta, _ := getTaskGitFromMD(md)
+	patch, rng, tip, err := taskBranchDiff(project, meta, nexusConfigFor(project.StorageRoot))
+	if err != nil {
+		return nil, rng, "", err
+	}
+	return parseReviewDiff(patch), rng, tip, nil
+}
+
+// uiGitReviewPage renders the task branch diff file by file with inline
+// review comments.
+func uiGitReviewPage(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	task, err := findTaskInBoard(projectRoot, strings.TrimSpace(r.URL.Query().Get("id")))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	project, ok := nexus.ProjectByKey(projectKey)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return
+	}
+	errMsg := strings.TrimSpace(r.URL.Query().Get("error"))
+	files, rng, tip, err := reviewTaskDiff(project, task.ID)
+	if err != nil && errMsg == "" {
+		errMsg = err.Error()
+	}
+	rev, err := readTaskReview(project.StorageRoot, task.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Comments whose line is no longer in the diff are listed as outdated.
+	placed := map[string]bool{}
+	const maxDiffLines = 1500
+	rows := make([]reviewFileRow, 0, len(files))
+	for _, f := range files {
+		fr := reviewFileRow{reviewDiffFile: f}
+		lines := f.Lines
+		if len(lines) > maxDiffLines {
+			lines = lines[:maxDiffLines]
+			fr.Clipped = true
+		}
+		for _, l := range lines {
+			lr := reviewLineRow{reviewDiffLine: l, Side: "new", Line: l.New}
+			if l.New == 0 {
+				lr.Side, lr.Line = "old", l.Old
+			}
+			if lr.Line > 0 {
+				for _, c := range rev.Comments {
+					if c.Path == f.Path && c.Side == lr.Side && c.Line == lr.Line {
+						lr.Comments = append(lr.Comments, c)
+						placed[c.ID] = true
+						fr.Comments++
+					}
+				}
+			}
+			fr.Rows = append(fr.Rows, lr)
+		}
+		rows = append(rows, fr)
+	}
+	var outdated []reviewComment
+	for _, c := range rev.Comments {
+		if !placed[c.ID] {
+			outdated = append(outdated, c)
+		}
+	}
+	if len(tip) > 12 {
+		tip = tip[:12]
+	}
+
+	tpl := template.Must(template.New("git_review").Funcs(template.FuncMap{
+		// dict passes the comment plus form fields to the "comment" block.
+		"dict": func(kv ...any) map[string]any {
+			m := map[string]any{}
+			for i := 0; i+1 < len(kv); i += 2 {
+				m[fmt.Sprint(kv[i])] = kv[i+1]
+			}
+			return m
+		},
+	}).Parse(uiGitReviewHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Project":    projectKey,
+		"Task":       task,
+		"Range":      rng,
+		"Tip":        tip,
+		"Files":      rows,
+		"Outdated":   outdated,
+		"Unresolved": len(rev.unresolved()),
+		"Total":      len(rev.Comments),
+		"Error":      errMsg,
+	})
+}
+
+func redirectReview(w http.ResponseWriter, r *http.Request, project TrackedProject, taskID string, err error, anchor string) {
+	target := reviewPageURL(project.Key, taskID)
+	if err != nil {
+		target += "&error=" + url.QueryEscape(err.Error())
+	}
+	if anchor != "" {
+		target += "#" + anchor
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// uiMutateReviewComment adds an inline comment on a line of the branch diff.
+func uiMutateReviewComment(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	path := strings.TrimSpace(r.FormValue("path"))
+	side := strings.TrimSpace(r.FormValue("side"))
+	body := strings.TrimSpace(r.FormValue("body"))
+	line, _ := strconv.Atoi(r.FormValue("line"))
+	if side != "old" {
+		side = "new"
+	}
+	anchor := fmt.Sprintf("L-%s-%s-%d", path, side, line)
+	if path == "" || line <= 0 || body == "" {
+		redirectReview(w, r, project, task.ID, fmt.Errorf("comment needs a file, a line and some text"), anchor)
+		return
+	}
+	files, _, tip, err := reviewTaskDiff(project, task.ID)
+	if err != nil {
+		redirectReview(w, r, project, task.ID, err, "")
+		return
+	}
+	code, found := reviewLineCode(files, path, side, line)
+	if !found {
+		redirectReview(w, r, project, task.ID, fmt.Errorf("%s:%d is not in the branch diff", path, line), "")
+		return
+	}
+	err = updateTaskReview(project.StorageRoot, task.ID, func(rev *taskReview) error {
+		rev.Comments = append(rev.Comments, reviewComment{
+			ID: rev.nextID(), Path: path, Line: line, Side: side, Code: code,
+			Body: body, Commit: tip, CreatedAt: time.Now(),
+		})
+		return nil
+	})
+	redirectReview(w, r, project, task.ID, err, anchor)
+}
+
+// uiMutateReviewResolve toggles a comment's resolved flag.
+func uiMutateReviewResolve(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	cid := strings.TrimSpace(r.FormValue("comment"))
+	err := updateTaskReview(project.StorageRoot, task.ID, func(rev *taskReview) error {
+		for i := range rev.Comments {
+			if rev.Comments[i].ID == cid {
+				rev.Comments[i].Resolved = r.FormValue("resolved") == "1"
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown comment %q", cid)
+	})
+	redirectReview(w, r, project, task.ID, err, "")
+}
+
+// uiMutateReviewSend sends the unresolved comments to the task's agent
+// session and opens the chat view.
+func uiMutateReviewSend(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	_, rng, _, err := reviewTaskDiff(project, task.ID)
+	if err != nil {
+		redirectReview(w, r, project, task.ID, err, "")
+		return
+	}
+	if _, err := sendReviewToAgent(project.StorageRoot, task, rng); err != nil {
+		redirectReview(w, r, project, task.ID, err, "")
+		return
+	}
+	http.Redirect(w, r, "/chat?project="+url.QueryEscape(project.Key)+"&task="+url.QueryEscape(task.ID), http.StatusSeeOther)
+}
+
+const uiGitReviewHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Review - {{.Task.ID}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    .meta { margin-top:6px; display:flex; gap:6px; flex-wrap:wrap; align-items:center; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:3px 7px; font-size:10px; text-transform:uppercase; background:rgba(0,0,0,.2); }
+    main { padding:10px; display:grid; gap:10px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    textarea { width:100%; min-height:60px; background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:7px 8px; font-size:12px; font-family:inherit; }
+    .file { border-bottom:1px solid rgba(255,255,255,.08); padding:4px 0; }
+    .file summary { cursor:pointer; display:flex; gap:8px; align-items:center; font-size:12px; list-style:none; }
+    code, .diff { font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; }
+    .diff { margin-top:6px; width:100%; border-collapse:collapse; font-size:12px; background: rgba(0,0,0,.22); border:1px solid var(--line); }
+    .diff td { padding:0 6px; vertical-align:top; }
+    .diff td.n { color:var(--muted); text-align:right; width:1%; white-space:nowrap; user-select:none; }
+    .diff td.t { white-space:pre-wrap; word-break:break-word; }
+    .diff td.c { width:1%; }
+    .diff tr:hover .add-c { visibility:visible; }
+    .add-c { visibility:hidden; background:none; border:none; color:var(--accent); cursor:pointer; padding:0; font-size:12px; }
+    .d-hunk { color:var(--accent); } .d-add { color:#38d18f; } .d-del { color:#ff6b6b; }
+    tr.d-add td.t { background:rgba(56,209,143,.08); } tr.d-del td.t { background:rgba(255,107,107,.08); }
+    .cmt { margin:6px 0; border:1px solid var(--line); border-left:3px solid var(--accent); border-radius:4px; padding:6px 8px; background:rgba(16,32,34,.9); font-family:"Space Grotesk", ui-sans-serif, system-ui; font-size:12px; white-space:pre-wrap; }
+    .cmt.resolved { opacity:.55; border-left-color:var(--line); }
+    .cmt .by { display:flex; gap:6px; align-items:center; color:var(--muted); font-size:10px; text-transform:uppercase; white-space:normal; }
+    .row { display:flex; gap:8px; align-items:center; justify-content:space-between; margin-top:8px; flex-wrap:wrap; }
+    form.inline { display:inline; margin:0; }
+    button { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; }
+    button.ghost { background:rgba(0,0,0,.2); border-color:var(--line); padding:3px 8px; font-size:10px; }
+    .err { color:#ff6b6b; font-size:12px; margin:0 0 8px; }
+    .muted { color:var(--muted); font-size:11px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/task/{{.Project}}/{{.Task.ID}}">Back to task</a>
+    <h1>Review: {{.Task.ID}}</h1>
+    <div class="meta">
+      {{if .Range}}<span class="pill">{{.Range}}</span>{{end}}
+      {{if .Tip}}<span class="pill">@ {{.Tip}}</span>{{end}}
+      <span class="pill">{{len .Files}} file(s)</span>
+      <span class="pill">{{.Unresolved}} unresolved / {{.Total}} comment(s)</span>
+      <form action="/mutate/review/send" method="post" class="inline">
+        <input type="hidden" name="id" value="{{.Task.ID}}" />
+        <input type="hidden" name="project" value="{{.Project}}" />
+        <button type="submit" {{if not .Unresolved}}disabled{{end}}>Send review to agent</button>
+      </form>
+    </div>
+  </header>
+  <main>
+    {{if .Error}}<section class="panel"><p class="err">{{.Error}}</p></section>{{end}}
+    {{define "comment"}}
+      <div class="cmt{{if .C.Resolved}} resolved{{end}}">
+        <div class="by">
+          <span>{{.C.ID}}</span><span>{{.C.CreatedAt.Format "2006-01-02 15:04"}}</span>
+          {{if not .C.SentAt.IsZero}}<span>sent {{.C.SentAt.Format "2006-01-02 15:04"}}</span>{{end}}
+          <form action="/mutate/review/resolve" method="post" class="inline">
+            <input type="hidden" name="id" value="{{.TaskID}}" />
+            <input type="hidden" name="project" value="{{.Project}}" />
+            <input type="hidden" name="comment" value="{{.C.ID}}" />
+            {{if .C.Resolved}}<button class="ghost" type="submit" name="resolved" value="0">Reopen</button>{{else}}<button class="ghost" type="submit" name="resolved" value="1">Resolve</button>{{end}}
+          </form>
+        </div>
+        {{.C.Body}}
+      </div>
+    {{end}}
+    {{$project := .Project}}{{$task := .Task.ID}}
+    {{if .Outdated}}
+    <section class="panel">
+      <h2>Outdated comments</h2>
+      <p class="muted">These lines are no longer in the branch diff.</p>
+      {{range .Outdated}}
+        <p class="muted"><code>{{.Path}}:{{.Line}}</code>{{if .Code}} <code>{{.Code}}</code>{{end}}</p>
+        {{template "comment" (dict "C" . "Project" $project "TaskID" $task)}}
+      {{end}}
+    </section>
+    {{end}}
+    <section class="panel">
+      <h2>Changes</h2>
+      {{if not .Files}}<p class="muted">No changes between the base and the task branch.</p>{{end}}
+      {{range .Files}}{{$path := .Path}}
+        <details class="file" open>
+          <summary>
+            <code>{{if and .OldPath (ne .OldPath .Path)}}{{.OldPath}} → {{end}}{{.Path}}</code>
+            <span class="d-add">+{{.Adds}}</span><span class="d-del">-{{.Dels}}</span>
+            {{if .Comments}}<span class="pill">{{.Comments}} comment(s)</span>{{end}}
+          </summary>
+          {{if .Binary}}<p class="muted">Binary file.</p>{{else}}
+          <table class="diff">
+            {{range .Rows}}
+            <tr class="{{.Class}}" {{if .Line}}id="L-{{$path}}-{{.Side}}-{{.Line}}"{{end}}>
+              <td class="n">{{if .Old}}{{.Old}}{{end}}</td>
+              <td class="n">{{if .New}}{{.New}}{{end}}</td>
+              <td class="c">{{if .Line}}<button class="add-c" type="button" title="Comment on this line" onclick="hazelReviewForm(this)">+</button>{{end}}</td>
+              <td class="t">{{.Text}}</td>
+            </tr>
+            {{if .Line}}
+            <tr class="cform" hidden>
+              <td colspan="3"></td>
+              <td>
+                <form action="/mutate/review/comment" method="post">
+                  <input type="hidden" name="id" value="{{$task}}" />
+                  <input type="hidden" name="project" value="{{$project}}" />
+                  <input type="hidden" name="path" value="{{$path}}" />
+                  <input type="hidden" name="side" value="{{.Side}}" />
+                  <input type="hidden" name="line" value="{{.Line}}" />
+                  <textarea name="body" placeholder="Comment on line {{.Line}}"></textarea>
+                  <div class="row"><span class="muted">{{$path}}:{{.Line}}</span><button type="submit">Add comment</button></div>
+                </form>
+              </td>
+            </tr>
+            {{end}}
+            {{range .Comments}}
+            <tr><td colspan="3"></td><td>{{template "comment" (dict "C" . "Project" $project "TaskID" $task)}}</td></tr>
+            {{end}}
+            {{end}}
+          </table>
+          {{if .Clipped}}<p class="muted">… (diff clipped)</p>{{end}}
+          {{end}}
+        </details>
+      {{end}}
+    </section>
+  </main>
+  <script>
+    function hazelReviewForm(btn) {
+      const row = btn.closest('tr').nextElementSibling;
+      if (!row || !row.classList.contains('cform')) return;
+      row.hidden = !row.hidden;
+      if (!row.hidden) row.querySelector('textarea').focus();
+    }
+  </script>
+</body>
+</html>`
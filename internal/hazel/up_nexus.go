@@ -6,9 +6,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/extension"
@@ -22,6 +25,10 @@ type nexusCard struct {
 	ColorHex      string
 	PriorityLabel string
 	RingHex       string
+	// Linked is set when the card is shown on a project the task spans but does not live in.
+	Linked bool
+	// Checks is the last local checks status (passing, failing, running).
+	Checks string
 }
 
 type nexusCompactItem struct {
@@ -108,7 +115,11 @@ func uiBoardNexus(w http.ResponseWriter, r *http.Request, root string, cfg Confi
 		"Projects":        nexus.Projects,
 		"SelectedProject": selected,
 		"GitBaseBranch":   base,
-		"HasGitHubToken":  strings.TrimSpace(cfg.GitHubToken) != "",
+		"TokenSet": map[string]bool{
+			forgeGitHub: forgeToken(cfg, forgeGitHub, "") != "",
+			forgeGitLab: forgeToken(cfg, forgeGitLab, "") != "",
+			forgeGitea:  forgeToken(cfg, forgeGitea, "") != "",
+		},
 	})
 }
 
@@ -129,9 +140,8 @@ func uiNexusBoardPanel(w http.ResponseWriter, r *http.Request, root string, cfg
 
 	cols := map[Status][]nexusCard{}
 	for _, p := range nexus.Projects {
-		if selected != "" && p.Key != selected {
-			continue
-		}
+		// Other projects' boards still contribute the tasks that span the selected one.
+		linkedOnly := selected != "" && p.Key != selected
 		var b Board
 		if err := readYAMLFile(boardPath(p.StorageRoot), &b); err != nil {
 			continue
@@ -140,6 +150,9 @@ func uiNexusBoardPanel(w http.ResponseWriter, r *http.Request, root string, cfg
 			if !visibleSet[t.Status] {
 				continue
 			}
+			if linkedOnly && !slices.Contains(t.Projects, selected) {
+				continue
+			}
 			colorKey := defaultColorKeyForID(t.ID)
 			lbl := ""
 			if md, err := readTaskMD(p.StorageRoot, t.ID); err == nil {
@@ -158,6 +171,8 @@ func uiNexusBoardPanel(w http.ResponseWriter, r *http.Request, root string, cfg
 				ColorHex:      colorHexForKey(colorKey),
 				PriorityLabel: lbl,
 				RingHex:       ringHexForPriorityLabel(lbl),
+				Linked:        linkedOnly,
+				Checks:        checksStatus(p.StorageRoot, t.ID),
 			})
 		}
 	}
@@ -315,9 +330,10 @@ func uiTaskNexus(w http.ResponseWriter, r *http.Request, cfg Config, nexus *Nexu
 	if p, ok := getTaskPriorityFromMD(taskMD); ok {
 		priority = p
 	}
+	links := loadWikiLinks(project.StorageRoot, projectKey)
 	renderMD := func(src string) template.HTML {
 		md := goldmark.New(
-			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithExtensions(extension.GFM, wikiLinkExtension{links: links}),
 			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
 			goldmark.WithRendererOptions(),
 		)
@@ -334,6 +350,15 @@ func uiTaskNexus(w http.ResponseWriter, r *http.Request, cfg Config, nexus *Nexu
 	implMD := read("impl.md")
 	planMD := read(planProposalFile)
 	gitMeta, _ := getTaskGitFromMD(taskMD)
+	linkedRepos, linkCandidates := taskLinkedView(nexus, project, task, taskMD)
+	linkedSet := map[string]bool{}
+	for _, k := range task.Projects {
+		linkedSet[k] = true
+	}
+	criteriaCheckedAt := ""
+	if rep, err := readCriteriaReport(project.StorageRoot, task.ID); err == nil {
+		criteriaCheckedAt = rep.CheckedAt.Format("2006-01-02 15:04")
+	}
 	agentName, agentTip := agentUI(cfg)
 	chatLabel := "Run in Chat"
 	chatAutoRun := true
@@ -351,28 +376,41 @@ func uiTaskNexus(w http.ResponseWriter, r *http.Request, cfg Config, nexus *Nexu
 		chatHref += "&session=" + url.QueryEscape(chatSession)
 	}
 	if err := tpl.Execute(w, map[string]any{
-		"Task":        task,
-		"TaskMD":      taskMD,
-		"TaskHTML":    renderMD(renderTask),
-		"ImplHTML":    renderMD(implMD),
-		"ImplMD":      implMD,
-		"PlanHTML":    renderMD(planMD),
-		"PlanMD":      planMD,
-		"HasPlan":     strings.TrimSpace(planMD) != "",
-		"RingHex":     ringHexForPriorityLabel(priority),
-		"Priority":    priority,
-		"AllPrios":    []string{"", "HIGH", "MEDIUM", "LOW"},
-		"Title":       project.Name,
-		"RepoSlug":    project.RepoSlug,
-		"HazelURL":    hazelPoweredByURL(),
-		"AgentName":   agentName,
-		"AgentTip":    agentTip,
-		"Project":     projectKey,
-		"ChatHref":    chatHref,
-		"ChatLabel":   chatLabel,
-		"ChatAutoRun": chatAutoRun,
-		"ChatSession": chatSession,
-		"Git":         gitMeta,
+		"Task":              task,
+		"TaskMD":            taskMD,
+		"TaskHTML":          renderMD(renderTask),
+		"ImplHTML":          renderMD(implMD),
+		"ImplMD":            implMD,
+		"PlanHTML":          renderMD(planMD),
+		"PlanMD":            planMD,
+		"HasPlan":           strings.TrimSpace(planMD) != "",
+		"RingHex":           ringHexForPriorityLabel(priority),
+		"Priority":          priority,
+		"AllPrios":          []string{"", "HIGH", "MEDIUM", "LOW"},
+		"Title":             project.Name,
+		"RepoSlug":          project.RepoSlug,
+		"HazelURL":          hazelPoweredByURL(),
+		"AgentName":         agentName,
+		"AgentTip":          agentTip,
+		"Project":           projectKey,
+		"ChatHref":          chatHref,
+		"ChatLabel":         chatLabel,
+		"ChatAutoRun":       chatAutoRun,
+		"ChatSession":       chatSession,
+		"Git":               gitMeta,
+		"BranchStatus":      taskBranchStatus(project.RepoPath, gitMeta),
+		"GitError":          strings.TrimSpace(r.URL.Query().Get("git_error")),
+		"GitNotice":         strings.TrimSpace(r.URL.Query().Get("git_notice")),
+		"LinkedRepos":       linkedRepos,
+		"LinkCandidates":    linkCandidates,
+		"LinkedSet":         linkedSet,
+		"Checks":            checksReportForPage(project.StorageRoot, task.ID),
+		"ChecksConfigured":  len(cfg.Checks) > 0,
+		"ChecksRequired":    cfg.RequireChecksForPR && len(cfg.Checks) > 0,
+		"Criteria":          buildCriteriaMatrix(project.StorageRoot, task.ID, taskMD, implMD),
+		"CriteriaCheckedAt": criteriaCheckedAt,
+		"Backlinks":         wikiBacklinks(project.StorageRoot, links, wikiLinkTarget{Kind: wikiLinkTask, TaskID: task.ID}),
+		"Distill":           distillViewForPage(r, cfg, project.StorageRoot, links, task.ID),
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -444,9 +482,39 @@ func uiWiki(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
 	})
 	sort.Strings(files)
 
+	// Frontmatter drives the type/tag filters and the page header.
+	base := filepath.Dir(wikiDir)
+	pages := loadWikiPages(base)
+	pageByRel := map[string]wikiPage{}
+	typeSet, tagSet := map[string]bool{}, map[string]bool{}
+	for _, p := range pages {
+		pageByRel[p.Rel] = p
+		if p.Meta.Type != "" {
+			typeSet[p.Meta.Type] = true
+		}
+		for _, t := range p.Meta.Tags {
+			tagSet[t] = true
+		}
+	}
+	filterType := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("type")))
+	filterTag := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag")))
+	filtering := filterType != "" || filterTag != ""
+	matches := func(rel string) bool {
+		p, ok := pageByRel[rel]
+		return ok && (filterType == "" || p.Meta.Type == filterType) && (filterTag == "" || slices.Contains(p.Meta.Tags, filterTag))
+	}
+
 	selectedFile := strings.TrimSpace(r.URL.Query().Get("file"))
+	if selectedFile == "" && filtering {
+		for _, f := range files {
+			if matches(f) {
+				selectedFile = f
+				break
+			}
+		}
+	}
 	if selectedFile == "" {
-		for _, cand := range []string{"README.md", "FEATURES_AND_USAGE.md", "SOURCE_README.md", "CHANGELOG.md"} {
+		for _, cand := range []string{"README.md", wikiIndexFile, "FEATURES_AND_USAGE.md", sourceReadmeFile, changelogFile} {
 			if exists(filepath.Join(wikiDir, cand)) {
 				selectedFile = cand
 				break
@@ -456,8 +524,9 @@ func uiWiki(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
 	if selectedFile == "" && len(files) > 0 {
 		selectedFile = files[0]
 	}
-	selectedFile = filepath.ToSlash(filepath.Clean(selectedFile))
-	if strings.HasPrefix(selectedFile, "../") || strings.Contains(selectedFile, "/../") {
+	if clean, ok := cleanWikiRel(selectedFile); ok {
+		selectedFile = clean
+	} else {
 		selectedFile = ""
 	}
 	selectedPath := filepath.Join(wikiDir, filepath.FromSlash(selectedFile))
@@ -467,28 +536,53 @@ func uiWiki(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
 			selectedPath = filepath.Join(wikiDir, filepath.FromSlash(selectedFile))
 		}
 	}
+	embed := strings.TrimSpace(r.URL.Query().Get("embed")) == "1"
+	if selectedFile == wikiIndexFile {
+		_ = writeWikiIndex(base) // viewing the index refreshes it
+	}
+	links := loadWikiLinks(base, selected)
+	links.embed = embed
 	rendered := template.HTML("<p>No wiki files yet.</p>")
+	var backlinks []wikiBacklink
 	if exists(selectedPath) {
 		b, err := os.ReadFile(selectedPath)
 		if err == nil {
-			ext := strings.ToLower(filepath.Ext(selectedFile))
-			if ext == ".md" || ext == ".markdown" {
-				md := goldmark.New(
-					goldmark.WithExtensions(extension.GFM),
-					goldmark.WithParserOptions(parser.WithAutoHeadingID()),
-					goldmark.WithRendererOptions(),
-				)
-				var sb strings.Builder
-				if err := md.Convert(b, &sb); err == nil {
-					rendered = template.HTML(sb.String())
-				} else {
-					rendered = template.HTML("<p>Unable to render markdown.</p>")
+			if isWikiMarkdown(selectedFile) {
+				rendered = renderWikiMarkdown(b, links)
+				if selectedFile != wikiIndexFile {
+					backlinks = wikiBacklinks(base, links, wikiLinkTarget{Kind: wikiLinkPage, Rel: selectedFile})
 				}
 			} else {
 				rendered = template.HTML("<pre>" + template.HTMLEscapeString(string(b)) + "</pre>")
 			}
 		}
 	}
+	if filtering {
+		keep := map[string]bool{}
+		for _, n := range nodes {
+			if n.IsDir || !matches(n.Rel) {
+				continue
+			}
+			keep[n.Rel] = true
+			for d := path.Dir(n.Rel); d != "."; d = path.Dir(d) {
+				keep[d] = true
+			}
+		}
+		filtered := nodes[:0]
+		for _, n := range nodes {
+			if keep[n.Rel] {
+				filtered = append(filtered, n)
+			}
+		}
+		nodes = filtered
+	}
+	filterQuery := ""
+	if filterType != "" {
+		filterQuery += "&type=" + url.QueryEscape(filterType)
+	}
+	if filterTag != "" {
+		filterQuery += "&tag=" + url.QueryEscape(filterTag)
+	}
 	for i := range nodes {
 		nodes[i].Current = !nodes[i].IsDir && nodes[i].Rel == selectedFile
 		if !nodes[i].IsDir {
@@ -499,12 +593,50 @@ func uiWiki(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
 			if strings.TrimSpace(r.URL.Query().Get("embed")) == "1" {
 				nodes[i].Href += "&embed=1"
 			}
+			nodes[i].Href += filterQuery
 		}
 	}
+	type recentView struct {
+		wikiChange
+		Href string
+	}
+	var recent []recentView
+	for _, c := range recentWikiChanges(base, 8) {
+		v := recentView{wikiChange: c, Href: links.href(wikiLinkTarget{Kind: wikiLinkPage, Rel: c.Rel})}
+		if !c.Exists {
+			v.Href = "/wiki/history?project=" + url.QueryEscape(selected) + "&file=" + url.QueryEscape(c.Rel)
+		}
+		recent = append(recent, v)
+	}
+	page := pageByRel[selectedFile]
+	sourceTaskHref := ""
+	if t, ok := links.resolve(page.Meta.SourceTask); ok && page.Meta.SourceTask != "" {
+		sourceTaskHref = links.href(t)
+	}
+	types := make([]string, 0, len(typeSet))
+	for _, t := range wikiPageTypes {
+		if typeSet[t] {
+			types = append(types, t)
+		}
+	}
+	tags := make([]string, 0, len(tagSet))
+	for t := range tagSet {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	pageQuery := "project=" + url.QueryEscape(selected)
+	editHref, historyHref := "", ""
+	mirror, _ := wikiMirrorSource(base, selectedFile)
+	if selectedFile != "" && exists(selectedPath) {
+		if !generatedWikiPage(selectedFile) && mirror == "" {
+			editHref = "/wiki/edit?" + pageQuery + "&file=" + url.QueryEscape(selectedFile)
+		}
+		historyHref = "/wiki/history?" + pageQuery + "&file=" + url.QueryEscape(selectedFile)
+	}
 
 	tpl := template.Must(template.New("wiki").Parse(uiWikiHTML))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	embed := strings.TrimSpace(r.URL.Query().Get("embed")) == "1"
 	_ = tpl.Execute(w, map[string]any{
 		"Projects":        projects,
 		"SelectedProject": selected,
@@ -514,9 +646,39 @@ func uiWiki(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
 		"SelectedHTML":    rendered,
 		"Mode":            mode,
 		"Embed":           embed,
+		"EditHref":        editHref,
+		"HistoryHref":     historyHref,
+		"Backlinks":       backlinks,
+		"ShowBacklinks":   isWikiMarkdown(selectedFile) && selectedFile != wikiIndexFile,
+		"NewHref":         "/wiki/edit?" + pageQuery + "&new=1",
+		"Page":            page,
+		"Stale":           page.Stale(time.Now()),
+		"SourceTaskHref":  sourceTaskHref,
+		"Mirror":          mirror,
+		"Types":           types,
+		"Tags":            tags,
+		"FilterType":      filterType,
+		"FilterTag":       filterTag,
+		"Recent":          recent,
 	})
 }
 
+// renderWikiMarkdown renders a wiki page body (without frontmatter) as GFM
+// with [[...]] links resolved against links.
+func renderWikiMarkdown(b []byte, links *wikiLinks) template.HTML {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, wikiLinkExtension{links: links}),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(),
+	)
+	_, body, _ := splitWikiFrontmatter(b)
+	var sb strings.Builder
+	if err := md.Convert(body, &sb); err != nil {
+		return template.HTML("<p>Unable to render markdown.</p>")
+	}
+	return template.HTML(sb.String())
+}
+
 const uiNexusDashboardHTML = `<!doctype html>
 <html lang="en">
 <head>
@@ -548,6 +710,8 @@ const uiNexusDashboardHTML = `<!doctype html>
     .tabs { display:flex; gap:8px; align-items:center; flex-wrap:wrap; }
     .tab { border:1px solid var(--line); border-radius:4px; padding:7px 10px; color:#d9f9ff; text-decoration:none; text-transform:uppercase; font-size:10px; letter-spacing:.08em; }
     .tab.active { border-color:var(--accent); color:var(--accent); background:rgba(19,218,236,.1); }
+    .search input { width:220px; background:rgba(0,0,0,.25); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:6px 8px; font-size:11px; font-family:inherit; }
+    .search input:focus { outline:none; border-color:var(--accent); }
     .cfg details { position:relative; }
     .cfg summary { list-style:none; cursor:pointer; border:1px solid var(--line); border-radius:4px; padding:5px 8px; color:#d9f9ff; font-size:10px; text-transform:uppercase; letter-spacing:.08em; }
     .cfg summary::-webkit-details-marker { display:none; }
@@ -577,6 +741,9 @@ const uiNexusDashboardHTML = `<!doctype html>
           <a class="tab {{if eq $.SelectedProject .Key}}active{{end}}" href="/?project={{.Key}}">{{.Name}}</a>
         {{end}}
       </nav>
+      <form class="search" action="/search" method="get">
+        <input type="search" name="q" placeholder="Search wiki, tasks, chats…" aria-label="Search" />
+      </form>
       <div class="status" id="hzStatus">
         <div class="cfg">
           <details>
@@ -585,17 +752,26 @@ const uiNexusDashboardHTML = `<!doctype html>
               <input type="hidden" name="project" value="{{.SelectedProject}}" />
               <label>
                 GitHub Token
-                <input type="password" name="github_token" placeholder="{{if .HasGitHubToken}}saved (enter new to replace){{else}}ghp_xxx{{end}}" />
+                <input type="password" name="forge_token_github" placeholder="{{if index .TokenSet "github"}}saved (enter new to replace){{else}}ghp_xxx{{end}}" />
+              </label>
+              <label>
+                GitLab Token
+                <input type="password" name="forge_token_gitlab" placeholder="{{if index .TokenSet "gitlab"}}saved (enter new to replace){{else}}glpat-xxx{{end}}" />
+              </label>
+              <label>
+                Gitea / Forgejo Token
+                <input type="password" name="forge_token_gitea" placeholder="{{if index .TokenSet "gitea"}}saved (enter new to replace){{else}}token{{end}}" />
               </label>
               <label style="display:flex;align-items:center;gap:8px;text-transform:none;font-size:11px;color:#d9f9ff;">
-                <input type="checkbox" name="clear_github_token" value="1" style="width:auto;" />
-                Clear stored token
+                <input type="checkbox" name="clear_forge_tokens" value="1" style="width:auto;" />
+                Clear stored tokens
               </label>
               <label>
                 Base Branch
                 <input type="text" name="git_base_branch" value="{{.GitBaseBranch}}" />
               </label>
               <button type="submit">Save</button>
+              <a href="/webhooks?project={{.SelectedProject}}">Webhook deliveries</a>
             </form>
           </details>
         </div>
@@ -786,6 +962,9 @@ const uiBoardNexusPanelHTML = `<!doctype html>
     .meta { margin-top:10px; display:flex; gap:8px; align-items:center; flex-wrap:wrap; }
     .meta form { margin:0; display:flex; gap:6px; align-items:center; }
     .pill { border:1px solid var(--line); padding:2px 8px; border-radius:4px; font-size:10px; background: rgba(0,0,0,.2); text-transform:uppercase; }
+    .pill.checks-passing { border-color:#38d18f; color:#38d18f; }
+    .pill.checks-failing { border-color:#ff5f5f; color:#ff5f5f; }
+    .pill.checks-running { border-color:var(--warn, #facc15); }
     select, input, button { background: rgba(0,0,0,.25); border:1px solid var(--line); color: var(--text); padding:7px 9px; border-radius:4px; font-size:11px; }
     button:hover, .tab:hover { border-color:var(--accent); color:var(--accent); }
     .hint { color: #8dc7cf; font-size:10px; text-transform:uppercase; letter-spacing:.08em; }
@@ -823,7 +1002,9 @@ const uiBoardNexusPanelHTML = `<!doctype html>
               <div class="id"><a href="/task/{{.ProjectKey}}/{{.Task.ID}}">{{.Task.ID}}</a></div>
               <div class="title">{{.Task.Title}}</div>
               <div class="meta">
-                <span class="pill">{{.ProjectName}}</span>
+                <span class="pill" {{if .Linked}}title="Linked task; lives in {{.ProjectName}}"{{end}}>{{if .Linked}}↗ {{end}}{{.ProjectName}}</span>
+                {{range .Task.Projects}}<span class="pill">+{{.}}</span>{{end}}
+                {{if .Checks}}<span class="pill checks-{{.Checks}}" title="Local checks">checks: {{.Checks}}</span>{{end}}
                 <form action="/mutate/status" method="post">
                   <input type="hidden" name="project" value="{{.ProjectKey}}" />
                   <input type="hidden" name="id" value="{{.Task.ID}}" />
@@ -935,7 +1116,9 @@ const uiWikiHTML = `<!doctype html>
     .tree, .doc { background:var(--panel); border:1px solid var(--line); border-radius:4px; min-height:0; }
     .tree { overflow:auto; padding:10px; }
     .doc { display:flex; flex-direction:column; overflow:hidden; }
-    .dochead { border-bottom:1px solid var(--line); padding:8px 10px; font-size:11px; text-transform:uppercase; color:#97d4dd; letter-spacing:.08em; }
+    .dochead { border-bottom:1px solid var(--line); padding:8px 10px; font-size:11px; text-transform:uppercase; color:#97d4dd; letter-spacing:.08em; display:flex; gap:10px; align-items:center; }
+    .dochead .acts { margin-left:auto; display:flex; gap:10px; }
+    .node.new { color:var(--accent); font-size:10px; text-transform:uppercase; letter-spacing:.08em; }
     .docbody { padding:14px 16px; overflow:auto; }
     .node { display:block; margin:3px 0; border-radius:4px; padding:4px 6px; color:#cfeff4; text-decoration:none; font-size:12px; }
     .node.dir { color:#8cc9d1; text-transform:uppercase; font-size:10px; letter-spacing:.08em; cursor:default; }
@@ -944,6 +1127,22 @@ const uiWikiHTML = `<!doctype html>
     .md a { color:var(--accent); }
     .md code { background: rgba(255,255,255,.08); padding:1px 5px; border-radius:6px; }
     .md pre { background: rgba(0,0,0,.3); padding:10px 12px; border-radius:4px; overflow:auto; }
+    .md .wikilink { text-transform:none; font-size:inherit; }
+    .md .wikilink.broken { color:#ff6b6b; border-bottom:1px dashed #ff6b6b; }
+    .backlinks { margin-top:18px; padding-top:10px; border-top:1px solid var(--line); display:flex; flex-wrap:wrap; gap:6px 12px; align-items:center; }
+    .backlinks h4 { margin:0; width:100%; font-size:10px; text-transform:uppercase; letter-spacing:.12em; color:#97d4dd; }
+    .backlinks a, .backlinks span { font-size:12px; text-transform:none; }
+    .backlinks .none { color:#8dc7cf; }
+    .filters { display:flex; gap:6px; margin:6px 0 8px; }
+    .filters select { flex:1; min-width:0; background:rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:4px; font-size:11px; }
+    .recent { margin-top:12px; padding-top:8px; border-top:1px solid var(--line); }
+    .recent .node.file { display:flex; flex-direction:column; }
+    .recent small { color:#8dc7cf; font-size:10px; }
+    .dochead .pill { border:1px solid var(--line); border-radius:4px; padding:1px 6px; font-size:10px; }
+    .dochead .tag { text-transform:none; font-size:11px; }
+    .warn { border:1px solid #facc15; color:#facc15; background:rgba(250,204,21,.08); border-radius:4px; padding:6px 8px; font-size:12px; margin:0 0 10px; }
+    .pagemeta { display:flex; gap:12px; flex-wrap:wrap; font-size:11px; color:#97d4dd; margin:0 0 10px; }
+    .pagemeta a { text-transform:none; }
     .compact main { padding:8px; }
     .compact .layout { grid-template-columns: 1fr; }
     .compact .tree { max-height:140px; }
@@ -960,17 +1159,63 @@ const uiWikiHTML = `<!doctype html>
   <main>
     <section class="layout">
       <aside class="tree">
+        <a class="node new" target="_top" href="{{.NewHref}}">+ New page</a>
+        {{if or .Types .Tags}}
+        <form class="filters" action="/wiki" method="get">
+          <input type="hidden" name="project" value="{{.SelectedProject}}" />
+          <input type="hidden" name="mode" value="{{.Mode}}" />
+          {{if .Embed}}<input type="hidden" name="embed" value="1" />{{end}}
+          <select name="type" onchange="this.form.submit()">
+            <option value="">All types</option>
+            {{range .Types}}<option value="{{.}}" {{if eq $.FilterType .}}selected{{end}}>{{.}}</option>{{end}}
+          </select>
+          <select name="tag" onchange="this.form.submit()">
+            <option value="">All tags</option>
+            {{range .Tags}}<option value="{{.}}" {{if eq $.FilterTag .}}selected{{end}}>#{{.}}</option>{{end}}
+          </select>
+        </form>
+        {{end}}
         {{range .Nodes}}
           {{if .IsDir}}
             <div class="node dir" style="padding-left: {{printf "%d" .Depth}}em;">{{.Name}}/</div>
           {{else}}
             <a class="node file {{if .Current}}active{{end}}" style="padding-left: {{printf "%d" .Depth}}em;" href="{{.Href}}">{{.Name}}</a>
           {{end}}
+        {{else}}
+          <div class="node dir">No matching pages</div>
+        {{end}}
+        {{if .Recent}}
+        <div class="recent">
+          <div class="node dir">Recently changed</div>
+          {{range .Recent}}<a class="node file" href="{{.Href}}"><span>{{.Rel}}</span><small>{{.Op}} · {{.At.Format "Jan 2 15:04"}}</small></a>{{end}}
+        </div>
         {{end}}
       </aside>
       <article class="doc">
-        <div class="dochead">{{.SelectedFile}}</div>
-        <div class="docbody md">{{.SelectedHTML}}</div>
+        <div class="dochead">
+          <span>{{.SelectedFile}}</span>
+          {{with .Page.Meta}}{{if .Type}}<a class="pill" href="/wiki?project={{$.SelectedProject}}&mode={{$.Mode}}&type={{.Type}}{{if $.Embed}}&embed=1{{end}}">{{.Type}}</a>{{end}}{{range .Tags}}<a class="tag" href="/wiki?project={{$.SelectedProject}}&mode={{$.Mode}}&tag={{.}}{{if $.Embed}}&embed=1{{end}}">#{{.}}</a>{{end}}{{end}}
+          {{if .Mirror}}<span class="pill" title="Read-only; edit the source in the repository">mirrored from {{.Mirror}}</span>{{end}}
+          {{if .HistoryHref}}<span class="acts">{{if .EditHref}}<a target="_top" href="{{.EditHref}}">Edit</a>{{end}}<a target="_top" href="{{.HistoryHref}}">History</a></span>{{end}}
+        </div>
+        <div class="docbody md">
+          {{if .Page.MetaErr}}<p class="warn">{{.Page.MetaErr}}</p>{{end}}
+          {{if .Stale}}<p class="warn">Review overdue: this page was due for review on {{.Page.Meta.ReviewBy}}{{if .Page.Meta.Owner}} by {{.Page.Meta.Owner}}{{end}}.</p>{{end}}
+          {{with .Page.Meta}}{{if or .Owner .ReviewBy .SourceTask}}
+          <p class="pagemeta">
+            {{if .Owner}}<span>Owner: {{.Owner}}</span>{{end}}
+            {{if .ReviewBy}}<span>Review by: {{.ReviewBy}}</span>{{end}}
+            {{if .SourceTask}}<span>Source: {{if $.SourceTaskHref}}<a target="_top" href="{{$.SourceTaskHref}}">{{.SourceTask}}</a>{{else}}{{.SourceTask}}{{end}}</span>{{end}}
+          </p>
+          {{end}}{{end}}
+          {{.SelectedHTML}}
+          {{if .ShowBacklinks}}
+          <div class="backlinks">
+            <h4>Backlinks</h4>
+            {{range .Backlinks}}{{if .Href}}<a href="{{.Href}}">{{.Title}}</a>{{else}}<span>{{.Title}}</span>{{end}}{{else}}<span class="none">No pages or tasks link here.</span>{{end}}
+          </div>
+          {{end}}
+        </div>
       </article>
     </section>
   </main>
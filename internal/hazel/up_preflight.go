@@ -0,0 +1,154 @@
+package hazel
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// preflightTargets returns the repos and base branch an action touches:
+// "start" checks the task's home and linked repos, "run" the project repo.
+func preflightTargets(root string, nexus *Nexus, projectRoot, projectKey, taskID, action string) ([]TrackedProject, string, string, error) {
+	pcfg, _ := loadConfigOrDefault(projectRoot)
+	mode := preflightMode(pcfg)
+	cfg, _ := loadConfigOrDefault(root)
+	if action == "run" {
+		project := trackedProjectForRoot(projectRoot)
+		return []TrackedProject{project}, projectBaseBranch(cfg, project), mode, nil
+	}
+	project, ok := nexus.ProjectByKey(projectKey)
+	if !ok {
+		return nil, "", "", fmt.Errorf("unknown project")
+	}
+	task, err := findTaskInBoard(projectRoot, taskID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	linked, err := linkedProjects(nexus, task)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return append([]TrackedProject{project}, linked...), projectBaseBranch(cfg, project), mode, nil
+}
+
+func preflightPageURL(projectKey, taskID, action string) string {
+	return "/git/preflight?project=" + url.QueryEscape(projectKey) + "&id=" + url.QueryEscape(taskID) + "&action=" + url.QueryEscape(action)
+}
+
+// uiGitPreflight lists the problems blocking an action with "stash and
+// continue", "continue anyway" (warn mode only) and "abort" choices.
+func uiGitPreflight(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	taskID := strings.TrimSpace(q.Get("id"))
+	action := strings.TrimSpace(q.Get("action"))
+	var target, label string
+	switch action {
+	case "start":
+		target, label = "/mutate/git/start", "Start Branch"
+	case "run":
+		target, label = "/mutate/run", "Run tick"
+	default:
+		http.Error(w, "unknown preflight action", http.StatusBadRequest)
+		return
+	}
+	repos, base, mode, err := preflightTargets(root, nexus, projectRoot, projectKey, taskID, action)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	problems := checkPreflight(repos, base)
+	stashable := false
+	for _, p := range problems {
+		stashable = stashable || p.Stashable
+	}
+	back := "/"
+	if projectKey != "" {
+		back = "/?project=" + url.QueryEscape(projectKey)
+	}
+	if taskID != "" && projectKey != "" {
+		back = "/task/" + projectKey + "/" + taskID
+	}
+	tpl := template.Must(template.New("git_preflight").Parse(uiGitPreflightHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Project":   projectKey,
+		"TaskID":    taskID,
+		"Label":     label,
+		"Target":    target,
+		"Mode":      mode,
+		"Problems":  problems,
+		"Stashable": stashable,
+		"Back":      back,
+		"Error":     strings.TrimSpace(q.Get("error")),
+	})
+}
+
+const uiGitPreflightHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Preflight - {{.Label}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    main { padding:10px; max-width:900px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:3px 7px; font-size:10px; text-transform:uppercase; background:rgba(0,0,0,.2); }
+    ul { margin:0; padding:0; list-style:none; }
+    li { display:flex; gap:8px; align-items:baseline; padding:6px 0; border-bottom:1px solid rgba(50,98,103,.4); font-size:12px; }
+    code { font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; font-size:11px; color:var(--muted); }
+    .row { display:flex; gap:8px; align-items:center; margin-top:10px; flex-wrap:wrap; }
+    button, .btn { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; text-decoration:none; }
+    .btn.ghost { background:rgba(0,0,0,.2); border-color:var(--line); }
+    .err { color:#ff6b6b; font-size:12px; margin:0 0 8px; }
+    .muted { color:var(--muted); font-size:11px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="{{.Back}}">Back</a>
+    <h1>Preflight: {{.Label}}{{if .TaskID}} {{.TaskID}}{{end}}</h1>
+  </header>
+  <main>
+    <section class="panel">
+      {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+      {{if .Problems}}
+      <ul>
+        {{range .Problems}}<li><span class="pill">{{.Repo}}</span><code>{{.Code}}</code><span>{{.Message}}</span></li>{{end}}
+      </ul>
+      {{else}}
+      <p class="muted">No problems found; the repository is safe to use.</p>
+      {{end}}
+      <form action="{{.Target}}" method="post" class="row">
+        {{if .TaskID}}<input type="hidden" name="id" value="{{.TaskID}}" />{{end}}
+        {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
+        {{if .Problems}}
+          {{if .Stashable}}<button type="submit" name="preflight" value="stash">Stash and continue</button>{{end}}
+          {{if ne .Mode "strict"}}<button type="submit" name="preflight" value="continue">Continue anyway</button>{{end}}
+        {{else}}
+          <button type="submit" name="preflight" value="continue">{{.Label}}</button>
+        {{end}}
+        <a class="btn ghost" href="{{.Back}}">Abort</a>
+      </form>
+      <p class="muted">Preflight mode: {{.Mode}}{{if eq .Mode "strict"}} (problems other than uncommitted changes must be fixed by hand){{end}}.</p>
+    </section>
+  </main>
+</body>
+</html>`
@@ -0,0 +1,236 @@
+package hazel
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type agentRunRow struct {
+	Name      string
+	TaskID    string
+	Mode      string
+	StartedAt string
+	ExitCode  *int
+	Stat      string
+	Commits   int
+	Reverted  bool
+}
+
+func agentRunRows(root string, limit int) []agentRunRow {
+	recs, err := listRunRecords(root, "")
+	if err != nil {
+		return nil
+	}
+	var rows []agentRunRow
+	for _, rec := range recs {
+		row := agentRunRow{
+			Name:     rec.Name,
+			TaskID:   rec.TaskID,
+			Mode:     rec.Mode,
+			ExitCode: rec.ExitCode,
+		}
+		if !rec.StartedAt.IsZero() {
+			row.StartedAt = rec.StartedAt.Format("2006-01-02 15:04")
+		}
+		if rec.Changes != nil {
+			row.Stat = rec.Changes.Stat
+			row.Commits = len(rec.Changes.Commits)
+			row.Reverted = rec.Changes.RevertedAt != nil
+		}
+		rows = append(rows, row)
+		if limit > 0 && len(rows) >= limit {
+			break
+		}
+	}
+	return rows
+}
+
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+func classifyDiffLines(patch string) []diffLine {
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+	out := make([]diffLine, 0, len(lines))
+	for _, l := range lines {
+		cls := ""
+		switch {
+		case strings.HasPrefix(l, "diff --git"), strings.HasPrefix(l, "+++ "), strings.HasPrefix(l, "--- "), strings.HasPrefix(l, "index "):
+			cls = "d-file"
+		case strings.HasPrefix(l, "@@"):
+			cls = "d-hunk"
+		case strings.HasPrefix(l, "+"):
+			cls = "d-add"
+		case strings.HasPrefix(l, "-"):
+			cls = "d-del"
+		}
+		out = append(out, diffLine{Class: cls, Text: l})
+	}
+	return out
+}
+
+// uiAgentRunView renders an agent run (log + captured changes). uiRunView falls
+// back to it when the name is not a chat session.
+func uiAgentRunView(w http.ResponseWriter, r *http.Request, projectRoot, projectKey, title, name, logPath string) {
+	rec := readRunRecord(logPath)
+	logText := ""
+	if b, err := os.ReadFile(logPath); err == nil {
+		logText = tailLines(string(b), 400)
+	}
+	var diff []diffLine
+	diffClipped := false
+	if rec.Changes != nil && rec.Changes.DiffPath != "" {
+		if b, err := os.ReadFile(rec.Changes.DiffPath); err == nil {
+			const maxDiff = 400 << 10
+			if len(b) > maxDiff {
+				b = b[:maxDiff]
+				diffClipped = true
+			}
+			diff = classifyDiffLines(string(b))
+		}
+	}
+	embed := strings.TrimSpace(r.URL.Query().Get("embed")) == "1"
+	tpl := template.Must(template.New("agent_run").Parse(uiAgentRunHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Title":       title,
+		"Name":        name,
+		"Run":         rec,
+		"Changes":     rec.Changes,
+		"Diff":        diff,
+		"DiffClipped": diffClipped,
+		"Log":         logText,
+		"Project":     projectKey,
+		"Embed":       embed,
+		"Error":       strings.TrimSpace(r.URL.Query().Get("error")),
+		"Notice":      strings.TrimSpace(r.URL.Query().Get("notice")),
+	})
+}
+
+func uiMutateRunRevert(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	projectRoot, projectKey, err := resolveProjectRoot(nexus, r, root)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("run"))
+	target := "/runs/" + url.PathEscape(name) + "?project=" + url.QueryEscape(projectKey)
+	err = withRepoLock(projectRoot, func() error { return revertRunChanges(projectRoot, name) })
+	if err != nil {
+		http.Redirect(w, r, target+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, target+"&notice=reverted", http.StatusSeeOther)
+}
+
+const uiAgentRunHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>{{.Title}} - Run {{.Name}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; display:flex; flex-direction:column; }
+    body::before { content:""; position:fixed; inset:0; pointer-events:none; background:linear-gradient(rgba(19,218,236,.04) 50%, rgba(0,0,0,0) 50%); background-size:100% 4px; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); position: sticky; top:0; z-index:10; }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    .meta { margin-top:6px; display:flex; gap:6px; flex-wrap:wrap; }
+    main { padding:10px; flex:1; min-height:0; display:grid; gap:10px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:3px 7px; font-size:10px; text-transform:uppercase; color:var(--text); background:rgba(0,0,0,.2); }
+    .mono, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; font-size:12px; }
+    pre { margin:0; white-space:pre-wrap; word-break:break-word; background: rgba(0,0,0,.22); border:1px solid var(--line); border-radius:4px; padding:10px; max-height:60vh; overflow:auto; }
+    table { width:100%; border-collapse: collapse; font-size:12px; }
+    th, td { text-align:left; padding:5px 6px; border-bottom:1px solid rgba(255,255,255,.08); }
+    th { color:var(--muted); font-size:10px; text-transform:uppercase; letter-spacing:.1em; }
+    .add { color:#38d18f; } .del { color:#ff6b6b; }
+    .d-file { color:#facc15; } .d-hunk { color:var(--accent); } .d-add { color:#38d18f; } .d-del { color:#ff6b6b; }
+    .row { display:flex; gap:8px; align-items:center; justify-content:space-between; flex-wrap:wrap; }
+    button { background:rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:6px 10px; font-size:10px; text-transform:uppercase; cursor:pointer; }
+    button:hover { border-color:#ff6b6b; color:#ff6b6b; }
+    .err { color:#ff6b6b; font-size:12px; } .ok { color:#38d18f; font-size:12px; }
+    .muted { color:var(--muted); font-size:12px; }
+  </style>
+</head>
+<body>
+  {{if not .Embed}}
+  <header>
+    <a href="/history{{if .Project}}?project={{.Project}}{{end}}">Back to history</a>
+    <h1>{{.Name}}</h1>
+    <div class="meta">
+      <span class="pill">{{if .Run.TaskID}}{{.Run.TaskID}}{{else}}(none){{end}}</span>
+      {{if .Run.Mode}}<span class="pill">{{.Run.Mode}}</span>{{end}}
+      <span class="pill">exit {{if .Run.ExitCode}}{{.Run.ExitCode}}{{else}}-{{end}}</span>
+      {{if not .Run.StartedAt.IsZero}}<span class="pill">{{.Run.StartedAt.Format "2006-01-02 15:04:05"}}</span>{{end}}
+    </div>
+  </header>
+  {{end}}
+  <main>
+    <section class="panel">
+      <div class="row">
+        <h2>Changes</h2>
+        {{if and .Changes .Changes.DiffPath}}
+          {{if .Changes.RevertedAt}}
+            <span class="pill">Reverted {{.Changes.RevertedAt.Format "2006-01-02 15:04"}}</span>
+          {{else}}
+            <form action="/mutate/run/revert" method="post" onsubmit="return confirm('Reverse-apply this run\'s changes to the working tree?')">
+              <input type="hidden" name="run" value="{{.Name}}" />
+              {{if .Project}}<input type="hidden" name="project" value="{{.Project}}" />{{end}}
+              <button type="submit">Revert this run's changes</button>
+            </form>
+          {{end}}
+        {{end}}
+      </div>
+      {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+      {{if eq .Notice "reverted"}}<p class="ok">Changes reverted in the working tree. Commits made by the run remain in history.</p>{{end}}
+      {{if not .Changes}}
+        <p class="muted">No change capture for this run.</p>
+      {{else}}
+        <p class="muted">{{if .Changes.Stat}}{{.Changes.Stat}}{{else}}No file changes.{{end}}</p>
+        {{if .Changes.Commits}}
+          <table>
+            <tr><th>New commits</th></tr>
+            {{range .Changes.Commits}}<tr><td class="mono">{{.}}</td></tr>{{end}}
+          </table>
+        {{end}}
+        {{if .Changes.Files}}
+          <table>
+            <tr><th>File</th><th>+</th><th>-</th></tr>
+            {{range .Changes.Files}}
+              <tr><td class="mono">{{.Path}}</td>{{if .Binary}}<td colspan="2" class="muted">binary</td>{{else}}<td class="add">{{.Added}}</td><td class="del">{{.Deleted}}</td>{{end}}</tr>
+            {{end}}
+          </table>
+        {{end}}
+      {{end}}
+    </section>
+    {{if .Diff}}
+    <section class="panel">
+      <h2>Diff{{if .DiffClipped}} (clipped){{end}}</h2>
+      <pre>{{range .Diff}}<span class="{{.Class}}">{{.Text}}</span>
+{{end}}</pre>
+    </section>
+    {{end}}
+    <section class="panel">
+      <h2>Log (tail)</h2>
+      <pre>{{if .Log}}{{.Log}}{{else}}(empty){{end}}</pre>
+    </section>
+  </main>
+</body>
+</html>`
@@ -0,0 +1,139 @@
+package hazel
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+type searchHitView struct {
+	SearchHit
+	SnippetHTML template.HTML
+}
+
+// highlightSnippet escapes a snippet and wraps its highlight ranges in <mark>.
+func highlightSnippet(snippet string, marks [][2]int) template.HTML {
+	var b strings.Builder
+	pos := 0
+	for _, m := range marks {
+		if m[0] < pos || m[1] > len(snippet) {
+			continue
+		}
+		b.WriteString(template.HTMLEscapeString(snippet[pos:m[0]]))
+		b.WriteString("<mark>" + template.HTMLEscapeString(snippet[m[0]:m[1]]) + "</mark>")
+		pos = m[1]
+	}
+	b.WriteString(template.HTMLEscapeString(snippet[pos:]))
+	return template.HTML(b.String())
+}
+
+// uiSearch renders ranked search results for the dashboard search box.
+func uiSearch(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	query := strings.TrimSpace(q.Get("q"))
+	opts := SearchOptions{Project: strings.TrimSpace(q.Get("project")), Kind: strings.TrimSpace(q.Get("kind")), Limit: 50}
+	var hits []searchHitView
+	errMsg := ""
+	if query != "" {
+		res, err := Search(root, nexus, query, opts)
+		if err != nil {
+			errMsg = err.Error()
+		}
+		for _, h := range res {
+			hits = append(hits, searchHitView{SearchHit: h, SnippetHTML: highlightSnippet(h.Snippet, h.Highlights)})
+		}
+	}
+	var projects []TrackedProject
+	if nexus != nil {
+		projects = nexus.Projects
+	}
+	tpl := template.Must(template.New("search").Parse(uiSearchHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Query":    query,
+		"Project":  opts.Project,
+		"Kind":     opts.Kind,
+		"Kinds":    searchKinds,
+		"Projects": projects,
+		"Hits":     hits,
+		"Error":    errMsg,
+	})
+}
+
+const uiSearchHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Search{{if .Query}} - {{.Query}}{{end}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    main { padding:10px; max-width:1000px; display:grid; gap:10px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; }
+    form.q { display:flex; gap:8px; flex-wrap:wrap; }
+    input[type=search], select { background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:7px 8px; font-size:12px; }
+    input[type=search] { flex:1; min-width:220px; }
+    button { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; }
+    .hit { padding:8px 0; border-bottom:1px solid rgba(50,98,103,.4); }
+    .hit a { color:var(--text); text-decoration:none; font-size:13px; }
+    .hit a:hover { color:var(--accent); }
+    .meta { display:flex; gap:6px; align-items:center; margin-bottom:4px; }
+    .pill { border:1px solid var(--line); border-radius:4px; padding:2px 6px; font-size:10px; text-transform:uppercase; background:rgba(0,0,0,.2); }
+    .snip { color:var(--muted); font-size:12px; line-height:1.45; margin-top:3px; }
+    mark { background:rgba(250,204,21,.25); color:#fff3b0; border-radius:2px; padding:0 1px; }
+    .err { color:#ff6b6b; font-size:12px; }
+    .muted { color:var(--muted); font-size:11px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/{{if .Project}}?project={{.Project}}{{end}}">Back to board</a>
+    <h1>Search</h1>
+  </header>
+  <main>
+    <section class="panel">
+      <form class="q" action="/search" method="get">
+        <input type="search" name="q" value="{{.Query}}" placeholder="Search wiki, tasks and chats" autofocus />
+        {{if .Projects}}
+        <select name="project">
+          <option value="">All projects</option>
+          {{range .Projects}}<option value="{{.Key}}" {{if eq $.Project .Key}}selected{{end}}>{{.Name}}</option>{{end}}
+        </select>
+        {{end}}
+        <select name="kind">
+          <option value="">Everything</option>
+          {{range .Kinds}}<option value="{{.}}" {{if eq $.Kind .}}selected{{end}}>{{.}}</option>{{end}}
+        </select>
+        <button type="submit">Search</button>
+      </form>
+    </section>
+    {{if .Query}}
+    <section class="panel">
+      {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+      {{if not .Hits}}<p class="muted">No matches for “{{.Query}}”.</p>{{end}}
+      {{range .Hits}}
+      <div class="hit">
+        <div class="meta">
+          <span class="pill">{{.Kind}}</span>
+          {{if .Project}}<span class="pill">{{.Project}}</span>{{end}}
+          {{if .Archived}}<span class="pill">archived</span>{{end}}
+          {{if .Href}}<a href="{{.Href}}">{{.Path}}</a>{{else}}<span>{{.Path}}</span>{{end}}
+        </div>
+        <div class="snip">{{.SnippetHTML}}</div>
+      </div>
+      {{end}}
+    </section>
+    {{end}}
+  </main>
+</body>
+</html>`
@@ -0,0 +1,49 @@
+package hazel
+
+import (
+	"net/http"
+	"strings"
+)
+
+// linkedRepoView is one linked project row on the task page.
+type linkedRepoView struct {
+	Key    string
+	Name   string
+	Git    taskGitMeta
+	Status branchStatus
+}
+
+// taskLinkedView returns the linked repo rows and the nexus projects that can be linked.
+func taskLinkedView(nexus *Nexus, home TrackedProject, task *BoardTask, md string) ([]linkedRepoView, []TrackedProject) {
+	repos := getTaskReposFromMD(md)
+	var rows []linkedRepoView
+	for _, k := range task.Projects {
+		row := linkedRepoView{Key: k, Name: k, Git: repos[k]}
+		if p, ok := nexus.ProjectByKey(k); ok {
+			row.Name = p.Name
+			row.Status = taskBranchStatus(p.RepoPath, row.Git)
+		}
+		rows = append(rows, row)
+	}
+	var candidates []TrackedProject
+	for _, p := range nexus.Projects {
+		if p.Key != home.Key {
+			candidates = append(candidates, p)
+		}
+	}
+	return rows, candidates
+}
+
+// uiMutateTaskProjects replaces the projects a task spans.
+func uiMutateTaskProjects(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	project, task, ok := gitTaskForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	var keys []string
+	for _, v := range r.PostForm["projects"] {
+		keys = append(keys, strings.Split(v, ",")...)
+	}
+	_, err := setTaskLinkedProjects(nexus, project, task.ID, keys)
+	redirectTaskGit(w, r, project, task.ID, err, "")
+}
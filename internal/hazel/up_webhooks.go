@@ -0,0 +1,150 @@
+package hazel
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+func uiWebhooks(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	selected := normalizeNexusProjectSelection(nexus, strings.TrimSpace(r.URL.Query().Get("project")))
+	project, ok := nexus.ProjectByKey(selected)
+	if !ok {
+		http.Error(w, "unknown project", http.StatusBadRequest)
+		return
+	}
+	cfg, _ := loadConfigOrDefault(project.StorageRoot)
+	type hookView struct {
+		URL       string
+		Events    string
+		HasSecret bool
+	}
+	hooks := make([]hookView, 0, len(cfg.Webhooks))
+	for _, h := range cfg.Webhooks {
+		ev := strings.Join(h.Events, ", ")
+		if ev == "" {
+			ev = "*"
+		}
+		hooks = append(hooks, hookView{URL: h.URL, Events: ev, HasSecret: strings.TrimSpace(h.Secret) != ""})
+	}
+	webhookMu.Lock()
+	q, qErr := readWebhookQueue(project.StorageRoot)
+	webhookMu.Unlock()
+	deliveries, logErr := readWebhookLog(project.StorageRoot, 200)
+	errText := ""
+	if qErr != nil {
+		errText = qErr.Error()
+	} else if logErr != nil {
+		errText = logErr.Error()
+	}
+
+	tpl := template.Must(template.New("webhooks").Parse(uiWebhooksHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Projects":        nexus.Projects,
+		"SelectedProject": selected,
+		"ProjectName":     project.Name,
+		"ConfigPath":      configPath(project.StorageRoot),
+		"Hooks":           hooks,
+		"Events":          webhookEvents,
+		"Pending":         q.Deliveries,
+		"Deliveries":      deliveries,
+		"Error":           errText,
+	})
+}
+
+const uiWebhooksHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>Webhooks</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --line:#326267; --accent:#13daec; --text:#e7fbff; --panel:rgba(25,49,51,.35); --ok:#38d18f; --bad:#ff5f5f; --warn:#facc15; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    body::before { content:""; position:fixed; inset:0; pointer-events:none; background:linear-gradient(rgba(19,218,236,.04) 50%, rgba(0,0,0,0) 50%); background-size:100% 4px; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); position: sticky; top:0; z-index:10; }
+    a { color:var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    main { padding:10px 16px; display:grid; gap:12px; }
+    section { background:var(--panel); border:1px solid var(--line); border-radius:4px; }
+    h2 { margin:0; border-bottom:1px solid var(--line); padding:8px 10px; font-size:11px; text-transform:uppercase; color:#97d4dd; letter-spacing:.08em; font-weight:500; }
+    table { width:100%; border-collapse:collapse; font-size:12px; }
+    th, td { text-align:left; padding:6px 10px; border-bottom:1px solid rgba(50,98,103,.5); vertical-align:top; }
+    th { font-size:10px; text-transform:uppercase; letter-spacing:.08em; color:#8cc9d1; font-weight:500; }
+    code { background: rgba(255,255,255,.08); padding:1px 5px; border-radius:6px; }
+    .empty { padding:10px; font-size:12px; color:#97d4dd; }
+    .ok { color:var(--ok); }
+    .bad { color:var(--bad); }
+    .warn { color:var(--warn); }
+    .err { margin:0; padding:8px 10px; border:1px solid var(--bad); border-radius:4px; color:var(--bad); font-size:12px; }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/?project={{.SelectedProject}}">Back to board</a>
+    <h1>{{.ProjectName}} Webhooks</h1>
+  </header>
+  <main>
+    {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+    <section>
+      <h2>Endpoints</h2>
+      {{if .Hooks}}
+      <table>
+        <tr><th>URL</th><th>Events</th><th>Signed</th></tr>
+        {{range .Hooks}}
+        <tr><td><code>{{.URL}}</code></td><td>{{.Events}}</td><td>{{if .HasSecret}}HMAC-SHA256{{else}}no{{end}}</td></tr>
+        {{end}}
+      </table>
+      {{else}}
+      <div class="empty">
+        No webhooks configured. Add a <code>webhooks:</code> list to <code>{{.ConfigPath}}</code>
+        (events: {{range $i, $e := .Events}}{{if $i}}, {{end}}<code>{{$e}}</code>{{end}}).
+      </div>
+      {{end}}
+    </section>
+    <section>
+      <h2>Pending ({{len .Pending}})</h2>
+      {{if .Pending}}
+      <table>
+        <tr><th>Event</th><th>URL</th><th>Attempts</th><th>Next attempt</th><th>Last error</th></tr>
+        {{range .Pending}}
+        <tr><td>{{.Event}}</td><td><code>{{.URL}}</code></td><td>{{.Attempts}}</td><td>{{.NextAttempt.Format "2006-01-02 15:04:05"}}</td><td class="warn">{{.LastError}}</td></tr>
+        {{end}}
+      </table>
+      {{else}}
+      <div class="empty">Queue is empty.</div>
+      {{end}}
+    </section>
+    <section>
+      <h2>Delivery log</h2>
+      {{if .Deliveries}}
+      <table>
+        <tr><th>When</th><th>Event</th><th>URL</th><th>Attempt</th><th>Result</th><th>Duration</th></tr>
+        {{range .Deliveries}}
+        <tr>
+          <td>{{.At.Format "2006-01-02 15:04:05"}}</td>
+          <td>{{.Event}}</td>
+          <td><code>{{.URL}}</code></td>
+          <td>{{.Attempt}}</td>
+          <td>
+            {{if .Delivered}}<span class="ok">{{.StatusCode}} delivered</span>
+            {{else if .GaveUp}}<span class="bad">gave up{{if .StatusCode}} ({{.StatusCode}}){{end}}: {{.Error}}</span>
+            {{else}}<span class="warn">retrying{{if .StatusCode}} ({{.StatusCode}}){{end}}: {{.Error}}</span>{{end}}
+          </td>
+          <td>{{.DurationMS}}ms</td>
+        </tr>
+        {{end}}
+      </table>
+      {{else}}
+      <div class="empty">No deliveries yet.</div>
+      {{end}}
+    </section>
+  </main>
+</body>
+</html>`
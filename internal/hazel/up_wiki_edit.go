@@ -0,0 +1,501 @@
+package hazel
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// wikiMu serializes wiki writes from the UI.
+var wikiMu sync.Mutex
+
+// wikiBase resolves the directory whose wiki/ a request edits: the selected
+// project's storage root, or the nexus root when no project is tracked.
+func wikiBase(nexus *Nexus, root, key string) (string, string) {
+	if nexus == nil {
+		return root, ""
+	}
+	key = normalizeNexusProjectSelection(nexus, key)
+	if p, ok := nexus.ProjectByKey(key); ok {
+		return p.StorageRoot, key
+	}
+	return root, ""
+}
+
+func wikiPageURL(path, key, file string) string {
+	u := path + "?project=" + url.QueryEscape(key)
+	if file != "" {
+		u += "&file=" + url.QueryEscape(file)
+	}
+	return u
+}
+
+type wikiEditView struct {
+	Project string
+	File    string
+	New     bool
+	Content string
+	BaseSum string
+	Preview template.HTML
+	Error   string
+	Type    string   // template the new page started from
+	Types   []string // page types with templates
+	Mirror  string   // repo file the page is mirrored from; read-only
+	// Generated pages (index, code map, changelog, source README) are
+	// rewritten by Hazel and read-only too.
+	Generated bool
+}
+
+func renderWikiEditor(w http.ResponseWriter, v wikiEditView) {
+	v.Types = wikiPageTypes
+	tpl := template.Must(template.New("wiki_edit").Parse(uiWikiEditHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, v)
+}
+
+// uiWikiEdit shows the editor for an existing page, or an empty one with new=1.
+func uiWikiEdit(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	base, key := wikiBase(nexus, root, q.Get("project"))
+	v := wikiEditView{Project: key, New: q.Get("new") == "1"}
+	if v.New {
+		v.File = strings.TrimSpace(q.Get("file"))
+		if body, ok := wikiTemplate(base, strings.ToLower(strings.TrimSpace(q.Get("type")))); ok {
+			v.Type, v.Content = strings.ToLower(strings.TrimSpace(q.Get("type"))), body
+			v.Preview = renderWikiMarkdown([]byte(body), loadWikiLinks(base, key))
+		}
+		renderWikiEditor(w, v)
+		return
+	}
+	rel, ok := cleanWikiRel(q.Get("file"))
+	if !ok {
+		http.Error(w, "invalid wiki path", http.StatusBadRequest)
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))
+	if err != nil {
+		http.Error(w, "wiki page not found: "+rel, http.StatusNotFound)
+		return
+	}
+	v.File, v.Content, v.BaseSum, v.Preview = rel, string(b), wikiSum(b), renderWikiMarkdown(b, loadWikiLinks(base, key))
+	v.Mirror, _ = wikiMirrorSource(base, rel)
+	v.Generated = generatedWikiPage(rel)
+	renderWikiEditor(w, v)
+}
+
+// uiWikiPreview renders posted markdown for the editor's live preview.
+func uiWikiPreview(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	base, key := wikiBase(nexus, root, r.FormValue("project"))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(renderWikiMarkdown([]byte(r.FormValue("content")), loadWikiLinks(base, key))))
+}
+
+// wikiForm parses a wiki mutate form and returns the wiki base, project key and page.
+func wikiForm(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) (string, string, string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", "", "", false
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", "", "", false
+	}
+	base, key := wikiBase(nexus, root, r.FormValue("project"))
+	return base, key, strings.TrimSpace(r.FormValue("file")), true
+}
+
+func uiMutateWikiSave(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	base, key, file, ok := wikiForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	create := r.FormValue("create") == "1"
+	content := strings.ReplaceAll(r.FormValue("content"), "\r\n", "\n")
+	v := wikiEditView{Project: key, File: file, New: create, Content: content, BaseSum: r.FormValue("base_sum"), Preview: renderWikiMarkdown([]byte(content), loadWikiLinks(base, key))}
+	rel, err := cleanWikiPagePath(file)
+	if err == nil && !create {
+		if clean, ok := cleanWikiRel(file); ok {
+			rel = clean // existing non-.md pages stay editable
+		}
+	}
+	if err == nil {
+		err = checkWikiWritable(base, rel)
+	}
+	if err == nil {
+		wikiMu.Lock()
+		_, err = saveWikiPage(base, rel, []byte(content), create, v.BaseSum, "")
+		if err == nil {
+			err = writeWikiIndex(base)
+		}
+		wikiMu.Unlock()
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		v.Error = err.Error()
+		renderWikiEditor(w, v)
+		return
+	}
+	http.Redirect(w, r, wikiPageURL("/wiki", key, rel), http.StatusSeeOther)
+}
+
+func uiMutateWikiRename(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	base, key, file, ok := wikiForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	from, fok := cleanWikiRel(file)
+	to, err := cleanWikiPagePath(r.FormValue("to"))
+	if !fok {
+		err = fmt.Errorf("invalid wiki path %q", file)
+	}
+	if err == nil {
+		err = checkWikiWritable(base, from)
+	}
+	if err == nil && generatedWikiPage(to) {
+		err = fmt.Errorf("wiki page %s is generated by Hazel; choose another name", to)
+	}
+	if err == nil {
+		wikiMu.Lock()
+		err = renameWikiPage(base, from, to)
+		if err == nil {
+			err = writeWikiIndex(base)
+		}
+		wikiMu.Unlock()
+	}
+	if err != nil {
+		http.Redirect(w, r, wikiPageURL("/wiki/history", key, file)+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, wikiPageURL("/wiki", key, to), http.StatusSeeOther)
+}
+
+func uiMutateWikiDelete(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	base, key, file, ok := wikiForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	rel, rok := cleanWikiRel(file)
+	err := fmt.Errorf("invalid wiki path %q", file)
+	if rok {
+		err = checkWikiWritable(base, rel)
+	}
+	if err == nil {
+		wikiMu.Lock()
+		err = deleteWikiPage(base, rel)
+		if err == nil {
+			err = writeWikiIndex(base)
+		}
+		wikiMu.Unlock()
+	}
+	if err != nil {
+		http.Redirect(w, r, wikiPageURL("/wiki/history", key, file)+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, wikiPageURL("/wiki/history", key, rel), http.StatusSeeOther)
+}
+
+func uiMutateWikiRestore(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	base, key, file, ok := wikiForm(w, r, root, nexus)
+	if !ok {
+		return
+	}
+	rel, rok := cleanWikiRel(file)
+	rev, _ := strconv.Atoi(r.FormValue("rev"))
+	err := fmt.Errorf("invalid wiki path %q", file)
+	if rok {
+		err = checkWikiWritable(base, rel)
+	}
+	if err == nil {
+		wikiMu.Lock()
+		_, err = restoreWikiRevision(base, rel, rev)
+		if err == nil {
+			err = writeWikiIndex(base)
+		}
+		wikiMu.Unlock()
+	}
+	if err != nil {
+		http.Redirect(w, r, wikiPageURL("/wiki/history", key, file)+"&error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, wikiPageURL("/wiki", key, rel), http.StatusSeeOther)
+}
+
+// uiWikiHistory lists a page's revisions and diffs the selected one against
+// its predecessor (or against=N).
+func uiWikiHistory(w http.ResponseWriter, r *http.Request, root string, nexus *Nexus) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	base, key := wikiBase(nexus, root, q.Get("project"))
+	rel, ok := cleanWikiRel(q.Get("file"))
+	if !ok {
+		http.Error(w, "invalid wiki path", http.StatusBadRequest)
+		return
+	}
+	errMsg := strings.TrimSpace(q.Get("error"))
+	// Show out-of-band changes as history before anything else happens.
+	wikiMu.Lock()
+	if err := captureExternalWikiRevision(base, rel); err != nil && errMsg == "" {
+		errMsg = err.Error()
+	}
+	wikiMu.Unlock()
+	revs, err := wikiPageHistory(base, rel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sel := len(revs)
+	if n, err := strconv.Atoi(q.Get("rev")); err == nil && n >= 1 && n <= len(revs) {
+		sel = n
+	}
+	against := sel - 1
+	if n, err := strconv.Atoi(q.Get("against")); err == nil && n >= 0 && n <= len(revs) {
+		against = n
+	}
+	// Diff against the last revision that had content (deletes have none).
+	for against > 0 && revs[against-1].Op == wikiOpDelete {
+		against--
+	}
+	var diff []diffLine
+	if sel > 0 {
+		to := sel
+		if revs[sel-1].Op == wikiOpDelete {
+			to = 0
+		}
+		patch, derr := wikiRevisionDiff(base, rel, against, to)
+		if derr != nil && errMsg == "" {
+			errMsg = derr.Error()
+		}
+		diff = classifyDiffLines(patch)
+	}
+	// Newest first for display.
+	rows := make([]wikiRevision, len(revs))
+	for i, rv := range revs {
+		rows[len(revs)-1-i] = rv
+	}
+	tpl := template.Must(template.New("wiki_history").Parse(uiWikiHistoryHTML))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tpl.Execute(w, map[string]any{
+		"Project":  key,
+		"File":     rel,
+		"Exists":   exists(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel))),
+		"Revs":     rows,
+		"Selected": sel,
+		"Against":  against,
+		"Diff":     diff,
+		"Error":    errMsg,
+	})
+}
+
+const uiWikiEditHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>{{if .New}}New wiki page{{else}}Edit {{.File}}{{end}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; display:flex; flex-direction:column; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    main { padding:10px; flex:1; display:flex; flex-direction:column; gap:10px; min-height:0; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    .split { flex:1; display:grid; grid-template-columns: 1fr 1fr; gap:10px; min-height:60vh; }
+    .split .panel { display:flex; flex-direction:column; min-height:0; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    input[type=text], textarea { background: rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:7px 8px; font-size:12px; }
+    textarea { flex:1; width:100%; resize:none; font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; line-height:1.45; }
+    .preview { flex:1; overflow:auto; padding:0 6px; }
+    .preview a { color:var(--accent); }
+    .preview .wikilink.broken { color:#ff6b6b; border-bottom:1px dashed #ff6b6b; }
+    .preview code { background: rgba(255,255,255,.08); padding:1px 5px; border-radius:6px; }
+    .preview pre { background: rgba(0,0,0,.3); padding:10px 12px; border-radius:4px; overflow:auto; }
+    .row { display:flex; gap:8px; align-items:center; flex-wrap:wrap; }
+    .row input[type=text] { min-width:260px; }
+    button { background:rgba(19,218,236,.15); border:1px solid var(--accent); color:var(--text); border-radius:4px; padding:7px 12px; font-size:11px; text-transform:uppercase; cursor:pointer; }
+    button.ghost { background:rgba(0,0,0,.2); border-color:var(--line); }
+    button.danger { border-color:#ff6b6b; background:rgba(255,107,107,.12); }
+    form.inline { display:inline-flex; gap:8px; align-items:center; margin:0; }
+    .err { color:#ff6b6b; font-size:12px; margin:0; }
+    a.tpl { color:var(--text); text-decoration:none; border:1px solid var(--line); border-radius:4px; padding:4px 8px; font-size:11px; text-transform:uppercase; }
+    a.tpl.on { border-color:var(--accent); color:var(--accent); }
+    .muted { color:var(--muted); font-size:11px; }
+    @media (max-width: 960px) { .split { grid-template-columns:1fr; } }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/wiki?project={{.Project}}{{if not .New}}&file={{.File}}{{end}}">Back to wiki</a>
+    <h1>{{if .New}}New wiki page{{else if or .Mirror .Generated}}View: {{.File}}{{else}}Edit: {{.File}}{{end}}</h1>
+  </header>
+  <main>
+    {{if .Error}}<section class="panel"><p class="err">{{.Error}}</p></section>{{end}}
+    {{if .Mirror}}<section class="panel"><p class="muted">Read-only: mirrored from <code>{{.Mirror}}</code> by <code>hazel sync-wiki</code>. Edit the source in the repository.</p></section>{{end}}
+    {{if .Generated}}<section class="panel"><p class="muted">Read-only: generated by Hazel and rewritten on every sync.</p></section>{{end}}
+    <form id="editor" action="/mutate/wiki/save" method="post" style="display:contents;">
+      <input type="hidden" name="project" value="{{.Project}}" />
+      <input type="hidden" name="base_sum" value="{{.BaseSum}}" />
+      {{if .New}}
+      <input type="hidden" name="create" value="1" />
+      <section class="panel row">
+        <input type="text" name="file" value="{{.File}}" placeholder="guides/deploy.md" required />
+        <span class="muted">Path inside wiki/; <code>.md</code> is added when missing.</span>
+      </section>
+      <section class="panel row">
+        <span class="muted">Start from:</span>
+        <a class="tpl {{if not .Type}}on{{end}}" href="/wiki/edit?project={{.Project}}&new=1">blank</a>
+        {{range .Types}}<a class="tpl {{if eq $.Type .}}on{{end}}" href="/wiki/edit?project={{$.Project}}&new=1&type={{.}}">{{.}}</a>{{end}}
+      </section>
+      {{else}}
+      <input type="hidden" name="file" value="{{.File}}" />
+      {{end}}
+      <div class="split">
+        <section class="panel">
+          <h2>Markdown</h2>
+          <textarea id="content" name="content" spellcheck="false"{{if or .Mirror .Generated}} readonly{{end}}>{{.Content}}</textarea>
+        </section>
+        <section class="panel">
+          <h2>Preview</h2>
+          <div class="preview" id="preview">{{.Preview}}</div>
+        </section>
+      </div>
+      {{if not (or .Mirror .Generated)}}
+      <section class="panel row">
+        <button type="submit">{{if .New}}Create page{{else}}Save{{end}}</button>
+        {{if not .New}}<span class="muted">Every save is kept in the page history.</span>{{end}}
+      </section>
+      {{end}}
+    </form>
+    {{if or .Mirror .Generated}}
+    <section class="panel row"><a class="muted" href="/wiki/history?project={{.Project}}&file={{.File}}">History</a></section>
+    {{else if not .New}}
+    <section class="panel row">
+      <form class="inline" action="/mutate/wiki/rename" method="post">
+        <input type="hidden" name="project" value="{{.Project}}" />
+        <input type="hidden" name="file" value="{{.File}}" />
+        <input type="text" name="to" value="{{.File}}" />
+        <button class="ghost" type="submit">Rename</button>
+      </form>
+      <form class="inline" action="/mutate/wiki/delete" method="post" onsubmit="return confirm('Delete {{.File}}? It can be restored from history.')">
+        <input type="hidden" name="project" value="{{.Project}}" />
+        <input type="hidden" name="file" value="{{.File}}" />
+        <button class="danger" type="submit">Delete</button>
+      </form>
+      <a class="muted" href="/wiki/history?project={{.Project}}&file={{.File}}">History</a>
+    </section>
+    {{end}}
+  </main>
+  <script>
+    (function () {
+      const ta = document.getElementById('content');
+      const pv = document.getElementById('preview');
+      let timer = null;
+      ta.addEventListener('input', function () {
+        clearTimeout(timer);
+        timer = setTimeout(async function () {
+          const body = new URLSearchParams({ project: {{.Project}}, content: ta.value });
+          try {
+            const res = await fetch('/wiki/preview', { method: 'POST', body });
+            if (res.ok) pv.innerHTML = await res.text();
+          } catch (_) {}
+        }, 250);
+      });
+    })();
+  </script>
+</body>
+</html>`
+
+const uiWikiHistoryHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <title>History - {{.File}}</title>
+  <style>
+    @import url('https://fonts.googleapis.com/css2?family=Space+Grotesk:wght@400;500;700&display=swap');
+    :root { --bg:#102022; --panel:rgba(25,49,51,.35); --text:#e7fbff; --muted:#8dc7cf; --accent:#13daec; --line:#326267; }
+    * { box-sizing:border-box; }
+    body { margin:0; font-family:"Space Grotesk", ui-sans-serif, system-ui; background:var(--bg); color:var(--text); min-height:100dvh; }
+    header { padding:10px 16px; border-bottom:1px solid var(--line); background: rgba(16,32,34,.95); }
+    header a { color: var(--accent); text-decoration:none; font-size:11px; text-transform:uppercase; margin-right:10px; }
+    h1 { margin:8px 0 0; color:var(--accent); font-size:15px; text-transform:uppercase; letter-spacing:.1em; }
+    main { padding:10px; display:grid; grid-template-columns: 360px 1fr; gap:10px; }
+    .panel { border:1px solid var(--line); background:var(--panel); border-radius:4px; padding:10px; min-width:0; }
+    h2 { margin:0 0 8px; font-size:11px; letter-spacing:.14em; text-transform:uppercase; color:var(--accent); border-bottom:1px solid var(--line); padding-bottom:6px; }
+    table { width:100%; border-collapse:collapse; font-size:12px; }
+    td { padding:5px 4px; border-bottom:1px solid rgba(50,98,103,.4); vertical-align:middle; }
+    tr.sel td { background:rgba(19,218,236,.08); }
+    td a { color:var(--accent); text-decoration:none; }
+    .op { font-size:10px; text-transform:uppercase; color:var(--muted); }
+    pre { margin:0; white-space:pre-wrap; word-break:break-word; background: rgba(0,0,0,.22); border:1px solid var(--line); border-radius:4px; padding:8px; font-family: ui-monospace, SFMono-Regular, Menlo, Monaco, Consolas, monospace; font-size:12px; }
+    .d-file { color:#facc15; } .d-hunk { color:var(--accent); } .d-add { color:#38d18f; } .d-del { color:#ff6b6b; }
+    button { background:rgba(0,0,0,.2); border:1px solid var(--line); color:var(--text); border-radius:4px; padding:3px 8px; font-size:10px; text-transform:uppercase; cursor:pointer; }
+    form { margin:0; }
+    .err { color:#ff6b6b; font-size:12px; grid-column: 1 / -1; margin:0; }
+    .muted { color:var(--muted); font-size:11px; }
+    @media (max-width: 960px) { main { grid-template-columns:1fr; } }
+  </style>
+</head>
+<body>
+  <header>
+    <a href="/wiki?project={{.Project}}{{if .Exists}}&file={{.File}}{{end}}">Back to wiki</a>
+    {{if .Exists}}<a href="/wiki/edit?project={{.Project}}&file={{.File}}">Edit</a>{{end}}
+    <h1>History: {{.File}}{{if not .Exists}} (deleted){{end}}</h1>
+  </header>
+  <main>
+    {{if .Error}}<p class="err">{{.Error}}</p>{{end}}
+    <section class="panel">
+      <h2>Revisions</h2>
+      {{if not .Revs}}<p class="muted">No history yet.</p>{{end}}
+      <table>
+        {{range .Revs}}
+        <tr class="{{if eq .Rev $.Selected}}sel{{end}}">
+          <td><a href="/wiki/history?project={{$.Project}}&file={{$.File}}&rev={{.Rev}}">r{{.Rev}}</a></td>
+          <td class="op">{{.Op}}{{if .From}} from {{.From}}{{end}}</td>
+          <td class="muted">{{.At.Format "2006-01-02 15:04"}}</td>
+          <td class="muted">{{.Size}} B</td>
+          <td>
+            {{if ne .Op "delete"}}
+            <form action="/mutate/wiki/restore" method="post" onsubmit="return confirm('Restore r{{.Rev}} as the current content?')">
+              <input type="hidden" name="project" value="{{$.Project}}" />
+              <input type="hidden" name="file" value="{{$.File}}" />
+              <input type="hidden" name="rev" value="{{.Rev}}" />
+              <button type="submit">Restore</button>
+            </form>
+            {{end}}
+          </td>
+        </tr>
+        {{end}}
+      </table>
+    </section>
+    <section class="panel">
+      <h2>{{if .Selected}}r{{.Against}} → r{{.Selected}}{{else}}Diff{{end}}</h2>
+      {{if .Diff}}<pre>{{range .Diff}}<span class="{{.Class}}">{{.Text}}</span>
+{{end}}</pre>{{else}}<p class="muted">No changes.</p>{{end}}
+    </section>
+  </main>
+</body>
+</html>`
@@ -0,0 +1,450 @@
+package hazel
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	webhookEventTaskStatusChanged = "task.status_changed"
+	webhookEventRunStarted        = "run.started"
+	webhookEventRunFinished       = "run.finished"
+	webhookEventApprovalPending   = "approval.pending"
+	webhookEventPlanReady         = "plan.ready"
+	webhookEventPROpened          = "pr.opened"
+	webhookEventPRClosed          = "pr.closed"
+)
+
+var webhookEvents = []string{
+	webhookEventTaskStatusChanged,
+	webhookEventRunStarted,
+	webhookEventRunFinished,
+	webhookEventApprovalPending,
+	webhookEventPlanReady,
+	webhookEventPROpened,
+	webhookEventPRClosed,
+}
+
+const (
+	webhookMaxAttempts = 6
+	webhookBaseBackoff = 10 * time.Second
+	webhookTimeout     = 10 * time.Second
+)
+
+// WebhookConfig is one outbound endpoint from the project config `webhooks:` list.
+// An empty Events list (or "*") subscribes to every event.
+type WebhookConfig struct {
+	URL    string   `yaml:"url"`
+	Events []string `yaml:"events,omitempty"`
+	Secret string   `yaml:"secret,omitempty"`
+}
+
+func (h WebhookConfig) wants(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		e = strings.TrimSpace(e)
+		if e == "*" || e == event {
+			return true
+		}
+		// "task.*" style prefixes.
+		if strings.HasSuffix(e, ".*") && strings.HasPrefix(event, strings.TrimSuffix(e, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+type webhookDelivery struct {
+	ID          string          `json:"id"`
+	Event       string          `json:"event"`
+	URL         string          `json:"url"`
+	Hook        int             `json:"hook"` // index into the config's webhooks list
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type webhookQueue struct {
+	Deliveries []webhookDelivery `json:"deliveries"`
+}
+
+// webhookLogEntry is one delivery attempt, appended to deliveries.jsonl.
+type webhookLogEntry struct {
+	At         time.Time `json:"at"`
+	DeliveryID string    `json:"delivery_id"`
+	Event      string    `json:"event"`
+	URL        string    `json:"url"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	GaveUp     bool      `json:"gave_up,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+func webhooksDir(root string) string      { return filepath.Join(hazelDir(root), "webhooks") }
+func webhookQueuePath(root string) string { return filepath.Join(webhooksDir(root), "queue.json") }
+func webhookLogPath(root string) string   { return filepath.Join(webhooksDir(root), "deliveries.jsonl") }
+
+// webhookMu guards queue.json and deliveries.jsonl across all projects. Events
+// are emitted from HTTP handlers, agent runs and the delivery loop concurrently.
+var webhookMu sync.Mutex
+
+func readWebhookQueue(root string) (webhookQueue, error) {
+	var q webhookQueue
+	b, err := os.ReadFile(webhookQueuePath(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return q, nil
+		}
+		return q, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return q, nil
+	}
+	if err := json.Unmarshal(b, &q); err != nil {
+		return q, fmt.Errorf("parse %s: %w", webhookQueuePath(root), err)
+	}
+	return q, nil
+}
+
+func writeWebhookQueue(root string, q webhookQueue) error {
+	if err := ensureDir(webhooksDir(root)); err != nil {
+		return err
+	}
+	return writeFileAtomic(webhookQueuePath(root), mustJSONIndent(q), 0o644)
+}
+
+func newWebhookDeliveryID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b[:])
+}
+
+// emitWebhookEvent enqueues event for every webhook in the project config that
+// subscribes to it. Delivery happens asynchronously in webhookDeliveryLoop, so
+// this never blocks on the network and is safe to call while holding the repo lock.
+func emitWebhookEvent(root string, event string, data map[string]any) error {
+	cfg, err := loadConfigOrDefault(root)
+	if err != nil || len(cfg.Webhooks) == 0 {
+		return err
+	}
+	now := time.Now()
+	project := map[string]any{"storage_root": root}
+	if m, err := readProjectMeta(root); err == nil {
+		project["key"] = m.Key
+		project["name"] = m.Name
+		project["repo_path"] = m.RepoPath
+		if m.RepoSlug != "" {
+			project["repo_slug"] = m.RepoSlug
+		}
+	}
+
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	q, err := readWebhookQueue(root)
+	if err != nil {
+		return err
+	}
+	added := false
+	for i, h := range cfg.Webhooks {
+		if strings.TrimSpace(h.URL) == "" || !h.wants(event) {
+			continue
+		}
+		id := newWebhookDeliveryID()
+		payload, err := json.Marshal(map[string]any{
+			"id":         id,
+			"event":      event,
+			"created_at": now,
+			"project":    project,
+			"data":       data,
+		})
+		if err != nil {
+			return err
+		}
+		q.Deliveries = append(q.Deliveries, webhookDelivery{
+			ID:          id,
+			Event:       event,
+			URL:         strings.TrimSpace(h.URL),
+			Hook:        i,
+			Payload:     payload,
+			NextAttempt: now,
+			CreatedAt:   now,
+		})
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	return writeWebhookQueue(root, q)
+}
+
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+	}
+	return d
+}
+
+func postWebhook(ctx context.Context, client *http.Client, d webhookDelivery, secret string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hazel-webhooks/1")
+	req.Header.Set("X-Hazel-Event", d.Event)
+	req.Header.Set("X-Hazel-Delivery", d.ID)
+	if secret != "" {
+		req.Header.Set("X-Hazel-Signature-256", signWebhookPayload(secret, d.Payload))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// deliverDueWebhooks attempts every queued delivery whose retry time has come.
+// Successful and exhausted deliveries leave the queue; failures are rescheduled
+// with exponential backoff. Every attempt is appended to the delivery log.
+// When another process (`hazel up` or a CLI command) is delivering from the
+// same queue, it returns without sending so no delivery goes out twice.
+func deliverDueWebhooks(ctx context.Context, root string, client *http.Client, now time.Time) (int, error) {
+	if !exists(webhookQueuePath(root)) {
+		return 0, nil
+	}
+	sent := 0
+	_, err := tryFileLock(webhookQueuePath(root)+".lock", func() error {
+		var err error
+		sent, err = sendDueWebhooks(ctx, root, client, now)
+		return err
+	})
+	return sent, err
+}
+
+func sendDueWebhooks(ctx context.Context, root string, client *http.Client, now time.Time) (int, error) {
+	webhookMu.Lock()
+	q, err := readWebhookQueue(root)
+	webhookMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	var due []webhookDelivery
+	for _, d := range q.Deliveries {
+		if !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	// Secrets are looked up at send time so rotating one in config applies to
+	// pending retries; a delivery whose webhook was removed is dropped. Hooks
+	// are matched by position, since several may share a URL.
+	cfg, _ := loadConfigOrDefault(root)
+
+	done := map[string]bool{}
+	updated := map[string]webhookDelivery{}
+	var entries []webhookLogEntry
+	sent := 0
+	for _, d := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		d.Attempts++
+		entry := webhookLogEntry{At: time.Now(), DeliveryID: d.ID, Event: d.Event, URL: d.URL, Attempt: d.Attempts}
+		if d.Hook < 0 || d.Hook >= len(cfg.Webhooks) || strings.TrimSpace(cfg.Webhooks[d.Hook].URL) != d.URL {
+			entry.Error = "webhook no longer configured"
+			entry.GaveUp = true
+			done[d.ID] = true
+			entries = append(entries, entry)
+			continue
+		}
+		start := time.Now()
+		code, perr := postWebhook(ctx, client, d, cfg.Webhooks[d.Hook].Secret)
+		entry.DurationMS = time.Since(start).Milliseconds()
+		entry.StatusCode = code
+		switch {
+		case perr == nil:
+			entry.Delivered = true
+			done[d.ID] = true
+			sent++
+		case d.Attempts >= webhookMaxAttempts:
+			entry.Error = perr.Error()
+			entry.GaveUp = true
+			done[d.ID] = true
+		default:
+			entry.Error = perr.Error()
+			d.LastError = perr.Error()
+			d.NextAttempt = time.Now().Add(webhookBackoff(d.Attempts))
+			updated[d.ID] = d
+		}
+		entries = append(entries, entry)
+	}
+
+	webhookMu.Lock()
+	defer webhookMu.Unlock()
+	// Re-read: events may have been enqueued while we were sending.
+	q, err = readWebhookQueue(root)
+	if err != nil {
+		return sent, err
+	}
+	keep := q.Deliveries[:0]
+	for _, d := range q.Deliveries {
+		if done[d.ID] {
+			continue
+		}
+		if u, ok := updated[d.ID]; ok {
+			d = u
+		}
+		keep = append(keep, d)
+	}
+	q.Deliveries = keep
+	if err := writeWebhookQueue(root, q); err != nil {
+		return sent, err
+	}
+	return sent, appendWebhookLog(root, entries)
+}
+
+func appendWebhookLog(root string, entries []webhookLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := ensureDir(webhooksDir(root)); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(webhookLogPath(root), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for _, e := range entries {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWebhookLog returns up to limit delivery attempts, newest first.
+func readWebhookLog(root string, limit int) ([]webhookLogEntry, error) {
+	f, err := os.Open(webhookLogPath(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var all []webhookLogEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e webhookLogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		all = append(all, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]webhookLogEntry, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		out = append(out, all[i])
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// webhookOutboxes returns the state roots that have a webhook queue: root
+// itself and the project storage roots below .hazel/projects. Queues only
+// exist once an event was enqueued, so without webhooks this finds nothing.
+func webhookOutboxes(root string) []string {
+	projects, _ := filepath.Glob(filepath.Join(hazelDir(root), "projects", "*"))
+	roots := append([]string{root}, projects...)
+	var out []string
+	for _, r := range roots {
+		if exists(webhookQueuePath(r)) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// webhookFlushTimeout bounds the delivery pass a CLI command makes before exiting.
+const webhookFlushTimeout = 15 * time.Second
+
+// FlushWebhooks makes one bounded delivery pass over every outbox under root,
+// so events queued by a CLI command go out without `hazel up`. Failed
+// deliveries stay queued with their backoff for the next pass.
+func FlushWebhooks(ctx context.Context, root string) {
+	ctx, cancel := context.WithTimeout(ctx, webhookFlushTimeout)
+	defer cancel()
+	client := &http.Client{}
+	for _, r := range webhookOutboxes(root) {
+		if ctx.Err() != nil {
+			return
+		}
+		_, _ = deliverDueWebhooks(ctx, r, client, time.Now())
+	}
+}
+
+// webhookDeliveryLoop drains every webhook queue while the UI server runs. It
+// reads the outbox directories directly rather than loading the nexus, which
+// would refresh every project wiki on each tick.
+func webhookDeliveryLoop(ctx context.Context, root string) {
+	client := &http.Client{}
+	for {
+		timer := time.NewTimer(2 * time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, r := range webhookOutboxes(root) {
+			_, _ = deliverDueWebhooks(ctx, r, client, time.Now())
+		}
+	}
+}
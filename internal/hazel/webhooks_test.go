@@ -0,0 +1,187 @@
+package hazel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookDeliveryRetriesAndSigns(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	var sigs []string
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, b)
+		sigs = append(sigs, r.Header.Get("X-Hazel-Signature-256"))
+		if calls == 1 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	cfg := defaultConfig()
+	cfg.Webhooks = []WebhookConfig{
+		{URL: srv.URL, Events: []string{"run.*"}, Secret: "s3cret"},
+		{URL: srv.URL + "/status-only", Events: []string{webhookEventTaskStatusChanged}},
+	}
+	if err := writeYAMLFile(configPath(root), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if err := emitWebhookEvent(root, webhookEventRunFinished, map[string]any{"task_id": "HZ-0001", "exit_code": 3}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	q, err := readWebhookQueue(root)
+	if err != nil || len(q.Deliveries) != 1 {
+		t.Fatalf("expected 1 queued delivery (event filter), got %d err=%v", len(q.Deliveries), err)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	if sent, err := deliverDueWebhooks(ctx, root, srv.Client(), now); err != nil || sent != 0 {
+		t.Fatalf("first attempt: sent=%d err=%v", sent, err)
+	}
+	q, _ = readWebhookQueue(root)
+	if len(q.Deliveries) != 1 || q.Deliveries[0].Attempts != 1 || !q.Deliveries[0].NextAttempt.After(now) {
+		t.Fatalf("expected delivery rescheduled after failure: %#v", q.Deliveries)
+	}
+	// Not due yet: nothing is sent.
+	if sent, _ := deliverDueWebhooks(ctx, root, srv.Client(), now); sent != 0 {
+		t.Fatalf("delivered before backoff elapsed")
+	}
+	if sent, err := deliverDueWebhooks(ctx, root, srv.Client(), now.Add(time.Hour)); err != nil || sent != 1 {
+		t.Fatalf("retry: sent=%d err=%v", sent, err)
+	}
+	q, _ = readWebhookQueue(root)
+	if len(q.Deliveries) != 0 {
+		t.Fatalf("queue should be empty after success: %#v", q.Deliveries)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+	if want := signWebhookPayload("s3cret", bodies[1]); sigs[1] != want {
+		t.Fatalf("signature mismatch: got %q want %q", sigs[1], want)
+	}
+	var payload struct {
+		Event string         `json:"event"`
+		Data  map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(bodies[1], &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.Event != webhookEventRunFinished || payload.Data["exit_code"] != float64(3) {
+		t.Fatalf("unexpected payload: %s", bodies[1])
+	}
+
+	log, err := readWebhookLog(root, 0)
+	if err != nil || len(log) != 2 {
+		t.Fatalf("expected 2 log entries, got %d err=%v", len(log), err)
+	}
+	if !log[0].Delivered || log[1].Delivered || log[1].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected log: %#v", log)
+	}
+}
+
+func TestWebhookSharedURLKeepsEachSecret(t *testing.T) {
+	var mu sync.Mutex
+	sigs := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		sigs[r.Header.Get("X-Hazel-Signature-256")] = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	project := filepath.Join(hazelDir(root), "projects", "app")
+	cfg := defaultConfig()
+	cfg.Webhooks = []WebhookConfig{{URL: srv.URL, Secret: "one"}, {URL: srv.URL, Secret: "two"}}
+	if err := ensureDir(hazelDir(project)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeYAMLFile(configPath(project), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if got := webhookOutboxes(root); len(got) != 0 {
+		t.Fatalf("outboxes before any event = %v", got)
+	}
+	if err := emitWebhookEvent(project, webhookEventPlanReady, map[string]any{"task_id": "HZ-0001"}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+	outboxes := webhookOutboxes(root)
+	if len(outboxes) != 1 || outboxes[0] != project {
+		t.Fatalf("outboxes = %v", outboxes)
+	}
+	if sent, err := deliverDueWebhooks(context.Background(), project, srv.Client(), time.Now()); err != nil || sent != 2 {
+		t.Fatalf("sent=%d err=%v", sent, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	q, _ := readWebhookQueue(project)
+	if len(q.Deliveries) != 0 || len(sigs) != 2 {
+		t.Fatalf("signatures = %v, queue = %+v", sigs, q.Deliveries)
+	}
+}
+
+func TestFlushWebhooksDeliversProjectQueues(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	project := filepath.Join(hazelDir(root), "projects", "app")
+	cfg := defaultConfig()
+	cfg.Webhooks = []WebhookConfig{{URL: srv.URL}}
+	if err := ensureDir(hazelDir(project)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeYAMLFile(configPath(project), &cfg); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := emitWebhookEvent(project, webhookEventTaskStatusChanged, map[string]any{"task_id": "HZ-0001"}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	// While another process delivers from the queue, this one sends nothing.
+	held, err := tryFileLock(webhookQueuePath(project)+".lock", func() error {
+		FlushWebhooks(context.Background(), root)
+		return nil
+	})
+	if err != nil || !held {
+		t.Fatalf("hold queue lock: %v %v", held, err)
+	}
+	if q, _ := readWebhookQueue(project); len(q.Deliveries) != 1 {
+		t.Fatalf("delivered while the queue was locked: %+v", q.Deliveries)
+	}
+
+	FlushWebhooks(context.Background(), root)
+	mu.Lock()
+	defer mu.Unlock()
+	if q, _ := readWebhookQueue(project); len(q.Deliveries) != 0 || calls != 1 {
+		t.Fatalf("calls = %d, queue = %+v", calls, q.Deliveries)
+	}
+}
@@ -0,0 +1,57 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func projectWikiDir(storageRoot string) string {
+	return filepath.Join(storageRoot, "wiki")
+}
+
+// sourceReadmeFile is the wiki copy of the tracked repo's README.md.
+const sourceReadmeFile = "SOURCE_README.md"
+
+// generatedWikiPage reports whether Hazel rewrites the page on its own, so
+// that edits made to it would be lost.
+func generatedWikiPage(rel string) bool {
+	switch rel {
+	case wikiIndexFile, codeMapFile, changelogFile, sourceReadmeFile:
+		return true
+	}
+	return false
+}
+
+// cleanWikiRel normalizes a wiki-relative page path (slash separated) and
+// rejects anything that would escape the wiki directory.
+func cleanWikiRel(rel string) (string, bool) {
+	rel = strings.TrimSpace(rel)
+	if rel == "" {
+		return "", false
+	}
+	rel = filepath.ToSlash(filepath.Clean(filepath.FromSlash(rel)))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || strings.Contains(rel, "/../") || strings.HasPrefix(rel, "/") {
+		return "", false
+	}
+	return rel, true
+}
+
+// listWikiFiles returns every file under wikiDir as sorted slash-separated relative paths.
+func listWikiFiles(wikiDir string) []string {
+	var files []string
+	_ = filepath.WalkDir(wikiDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, rerr := filepath.Rel(wikiDir, path)
+		if rerr != nil {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,270 @@
+package hazel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Wiki pages edited from the UI are versioned as snapshot files under
+// .hazel/wiki_history/<page>/ next to the wiki they belong to: <rev>.md holds
+// the content and revisions.json the log. Content written outside the editor
+// (sync-wiki, a text editor) is captured as an "external" revision before the
+// next UI change, so history never loses a version.
+
+// Wiki revision operations.
+const (
+	wikiOpCreate   = "create"
+	wikiOpEdit     = "edit"
+	wikiOpRename   = "rename"
+	wikiOpDelete   = "delete"
+	wikiOpRestore  = "restore"
+	wikiOpExternal = "external"
+	wikiOpDistill  = "distill" // an accepted task distill proposal
+	wikiOpMirror   = "mirror"  // copied from the repo by a wiki_mirrors rule
+)
+
+type wikiRevision struct {
+	Rev  int       `json:"rev"`
+	Op   string    `json:"op"`
+	At   time.Time `json:"at"`
+	Size int       `json:"size"`
+	Sum  string    `json:"sum,omitempty"`  // sha256 of the content; empty for delete
+	From string    `json:"from,omitempty"` // previous path for rename
+}
+
+func wikiHistoryDir(base string) string {
+	return filepath.Join(hazelDir(base), "wiki_history")
+}
+
+func wikiPageHistoryDir(base, rel string) string {
+	return filepath.Join(wikiHistoryDir(base), filepath.FromSlash(rel))
+}
+
+func wikiSum(b []byte) string {
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:])
+}
+
+// cleanWikiPagePath validates a page path for create/rename; pages are markdown.
+func cleanWikiPagePath(rel string) (string, error) {
+	clean, ok := cleanWikiRel(rel)
+	if !ok {
+		return "", fmt.Errorf("invalid wiki path %q", rel)
+	}
+	if ext := strings.ToLower(filepath.Ext(clean)); ext == "" {
+		clean += ".md"
+	} else if ext != ".md" && ext != ".markdown" {
+		return "", fmt.Errorf("wiki pages must be markdown (.md)")
+	}
+	return clean, nil
+}
+
+func wikiPageHistory(base, rel string) ([]wikiRevision, error) {
+	b, err := os.ReadFile(filepath.Join(wikiPageHistoryDir(base, rel), "revisions.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return []wikiRevision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revs []wikiRevision
+	if err := json.Unmarshal(b, &revs); err != nil {
+		return nil, fmt.Errorf("parse wiki history for %s: %w", rel, err)
+	}
+	return revs, nil
+}
+
+func wikiRevisionContent(base, rel string, rev int) ([]byte, error) {
+	return os.ReadFile(filepath.Join(wikiPageHistoryDir(base, rel), strconv.Itoa(rev)+".md"))
+}
+
+func recordWikiRevision(base, rel, op string, content []byte, from string) (wikiRevision, error) {
+	revs, err := wikiPageHistory(base, rel)
+	if err != nil {
+		return wikiRevision{}, err
+	}
+	dir := wikiPageHistoryDir(base, rel)
+	if err := ensureDir(dir); err != nil {
+		return wikiRevision{}, err
+	}
+	r := wikiRevision{Rev: len(revs) + 1, Op: op, At: time.Now(), Size: len(content), From: from}
+	if op != wikiOpDelete {
+		r.Sum = wikiSum(content)
+		if err := writeFileAtomic(filepath.Join(dir, strconv.Itoa(r.Rev)+".md"), content, 0o644); err != nil {
+			return wikiRevision{}, err
+		}
+	}
+	revs = append(revs, r)
+	return r, writeFileAtomic(filepath.Join(dir, "revisions.json"), mustJSONIndent(revs), 0o644)
+}
+
+// captureExternalWikiRevision snapshots the page's current content when it
+// differs from the last recorded revision.
+func captureExternalWikiRevision(base, rel string) error {
+	b, err := os.ReadFile(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	revs, err := wikiPageHistory(base, rel)
+	if err != nil {
+		return err
+	}
+	if n := len(revs); n > 0 && revs[n-1].Sum == wikiSum(b) {
+		return nil
+	}
+	_, err = recordWikiRevision(base, rel, wikiOpExternal, b, "")
+	return err
+}
+
+// saveWikiPage writes a page and records the revision. baseSum, when set, is
+// the content hash the editor was opened with; a mismatch means someone else
+// changed the page meanwhile.
+func saveWikiPage(base, rel string, content []byte, create bool, baseSum, op string) (wikiRevision, error) {
+	path := filepath.Join(projectWikiDir(base), filepath.FromSlash(rel))
+	cur, err := os.ReadFile(path)
+	switch {
+	case create && err == nil:
+		return wikiRevision{}, fmt.Errorf("wiki page %s already exists", rel)
+	case !create && err != nil:
+		return wikiRevision{}, fmt.Errorf("wiki page %s not found", rel)
+	case !create && baseSum != "" && wikiSum(cur) != baseSum:
+		return wikiRevision{}, fmt.Errorf("wiki page %s changed since it was opened; reload and reapply your edit", rel)
+	}
+	if !create {
+		if bytes.Equal(cur, content) {
+			revs, _ := wikiPageHistory(base, rel)
+			if n := len(revs); n > 0 {
+				return revs[n-1], nil
+			}
+		}
+		if err := captureExternalWikiRevision(base, rel); err != nil {
+			return wikiRevision{}, err
+		}
+	}
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return wikiRevision{}, err
+	}
+	if err := writeFileAtomic(path, content, 0o644); err != nil {
+		return wikiRevision{}, err
+	}
+	if op == "" {
+		op = wikiOpEdit
+		if create {
+			op = wikiOpCreate
+		}
+	}
+	return recordWikiRevision(base, rel, op, content, "")
+}
+
+// renameWikiPage moves a page and its history.
+func renameWikiPage(base, from, to string) error {
+	src := filepath.Join(projectWikiDir(base), filepath.FromSlash(from))
+	dst := filepath.Join(projectWikiDir(base), filepath.FromSlash(to))
+	if !exists(src) {
+		return fmt.Errorf("wiki page %s not found", from)
+	}
+	if exists(dst) || exists(wikiPageHistoryDir(base, to)) {
+		return fmt.Errorf("wiki page %s already exists", to)
+	}
+	if err := captureExternalWikiRevision(base, from); err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	if err := ensureDir(filepath.Dir(wikiPageHistoryDir(base, to))); err != nil {
+		return err
+	}
+	if err := os.Rename(wikiPageHistoryDir(base, from), wikiPageHistoryDir(base, to)); err != nil {
+		return err
+	}
+	b, err := os.ReadFile(dst)
+	if err != nil {
+		return err
+	}
+	_, err = recordWikiRevision(base, to, wikiOpRename, b, from)
+	return err
+}
+
+// deleteWikiPage removes a page; its history stays so it can be restored.
+func deleteWikiPage(base, rel string) error {
+	path := filepath.Join(projectWikiDir(base), filepath.FromSlash(rel))
+	if !exists(path) {
+		return fmt.Errorf("wiki page %s not found", rel)
+	}
+	if err := captureExternalWikiRevision(base, rel); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	_, err := recordWikiRevision(base, rel, wikiOpDelete, nil, "")
+	return err
+}
+
+// restoreWikiRevision writes an old revision back as the current content.
+func restoreWikiRevision(base, rel string, rev int) (wikiRevision, error) {
+	b, err := wikiRevisionContent(base, rel, rev)
+	if err != nil {
+		return wikiRevision{}, fmt.Errorf("revision %d of %s not found", rev, rel)
+	}
+	create := !exists(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))
+	return saveWikiPage(base, rel, b, create, "", wikiOpRestore)
+}
+
+// wikiRevisionDiff returns a unified diff between two revisions; rev 0 (or a
+// delete) counts as an empty page.
+func wikiRevisionDiff(base, rel string, from, to int) (string, error) {
+	read := func(rev int) []byte {
+		if rev <= 0 {
+			return nil
+		}
+		b, _ := wikiRevisionContent(base, rel, rev)
+		return b
+	}
+	return unifiedTextDiff(read(from), read(to), "r"+strconv.Itoa(from)+"/"+rel, "r"+strconv.Itoa(to)+"/"+rel)
+}
+
+// unifiedTextDiff diffs two texts with `git diff --no-index`.
+func unifiedTextDiff(a, b []byte, labelA, labelB string) (string, error) {
+	tmp, err := os.MkdirTemp("", "hazel-diff-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	pa, pb := filepath.Join(tmp, "a"), filepath.Join(tmp, "b")
+	if err := os.WriteFile(pa, a, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(pb, b, 0o644); err != nil {
+		return "", err
+	}
+	cmd := exec.Command("git", "diff", "--no-index", "--no-color", "--src-prefix=", "--dst-prefix=", "a", "b")
+	cmd.Dir = tmp
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if err != nil && !(errors.As(err, &exit) && exit.ExitCode() == 1) {
+		return "", fmt.Errorf("git diff --no-index failed: %w", err)
+	}
+	// Relabel the temp file names.
+	s := string(out)
+	s = strings.Replace(s, "--- a\n", "--- "+labelA+"\n", 1)
+	s = strings.Replace(s, "+++ b\n", "+++ "+labelB+"\n", 1)
+	if i := strings.Index(s, "\n--- "); i >= 0 {
+		s = s[i+1:] // drop the "diff --git"/"index" header lines
+	}
+	return s, nil
+}
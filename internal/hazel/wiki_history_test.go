@@ -0,0 +1,131 @@
+package hazel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWikiPageHistory(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	page := filepath.Join(projectWikiDir(root), "guides", "deploy.md")
+
+	if _, err := cleanWikiPagePath("../etc/passwd"); err == nil {
+		t.Fatalf("traversal path accepted")
+	}
+	rel, err := cleanWikiPagePath("guides/deploy")
+	if err != nil || rel != "guides/deploy.md" {
+		t.Fatalf("clean path = %q, %v", rel, err)
+	}
+	if _, err := saveWikiPage(root, rel, []byte("# Deploy\n\nv1\n"), true, "", ""); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := saveWikiPage(root, rel, []byte("again"), true, "", ""); err == nil {
+		t.Fatalf("create over existing page accepted")
+	}
+	r2, err := saveWikiPage(root, rel, []byte("# Deploy\n\nv2\n"), false, wikiSum([]byte("# Deploy\n\nv1\n")), "")
+	if err != nil || r2.Rev != 2 || r2.Op != wikiOpEdit {
+		t.Fatalf("edit = %+v, %v", r2, err)
+	}
+
+	// Edits from outside the UI become their own revision; stale editors conflict.
+	if err := os.WriteFile(page, []byte("# Deploy\n\nv3 from sync\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := saveWikiPage(root, rel, []byte("lost"), false, r2.Sum, ""); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Fatalf("conflict err = %v", err)
+	}
+	if _, err := saveWikiPage(root, rel, []byte("# Deploy\n\nv4\n"), false, "", ""); err != nil {
+		t.Fatalf("edit after external change: %v", err)
+	}
+	revs, _ := wikiPageHistory(root, rel)
+	if len(revs) != 4 || revs[2].Op != wikiOpExternal {
+		t.Fatalf("revs = %+v", revs)
+	}
+	diff, err := wikiRevisionDiff(root, rel, 3, 4)
+	if err != nil || !strings.Contains(diff, "-v3 from sync") || !strings.Contains(diff, "+v4") || !strings.HasPrefix(diff, "--- r3/guides/deploy.md") {
+		t.Fatalf("diff = %q, %v", diff, err)
+	}
+
+	// Rename carries the history along; delete keeps it for restore.
+	if err := renameWikiPage(root, rel, "ops/deploy.md"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if exists(page) || exists(wikiPageHistoryDir(root, rel)) {
+		t.Fatalf("old page or history left behind")
+	}
+	if revs, _ := wikiPageHistory(root, "ops/deploy.md"); len(revs) != 5 || revs[4].From != rel {
+		t.Fatalf("renamed revs = %+v", revs)
+	}
+	if err := deleteWikiPage(root, "ops/deploy.md"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := restoreWikiRevision(root, "ops/deploy.md", 2); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	b, _ := os.ReadFile(filepath.Join(projectWikiDir(root), "ops", "deploy.md"))
+	if string(b) != "# Deploy\n\nv2\n" {
+		t.Fatalf("restored content = %q", b)
+	}
+	if revs, _ := wikiPageHistory(root, "ops/deploy.md"); len(revs) != 7 || revs[5].Op != wikiOpDelete || revs[6].Op != wikiOpRestore {
+		t.Fatalf("final revs = %+v", revs)
+	}
+}
+
+func TestGeneratedWikiPagesAreReadOnly(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	changelog := filepath.Join(projectWikiDir(root), changelogFile)
+	if err := ensureDir(projectWikiDir(root)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(changelog, []byte("# Changelog\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := saveWikiPage(root, "notes.md", []byte("# Notes\n"), true, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	post := func(handler func(http.ResponseWriter, *http.Request, string, *Nexus), form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler(rec, req, root, nil)
+		return rec
+	}
+
+	rec := post(uiMutateWikiSave, url.Values{"file": {changelogFile}, "content": {"mine"}})
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "generated by Hazel") {
+		t.Fatalf("save = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = post(uiMutateWikiSave, url.Values{"file": {codeMapFile}, "content": {"mine"}, "create": {"1"}})
+	if rec.Code != http.StatusConflict || exists(filepath.Join(projectWikiDir(root), codeMapFile)) {
+		t.Fatalf("create of a generated page = %d", rec.Code)
+	}
+	rec = post(uiMutateWikiDelete, url.Values{"file": {changelogFile}})
+	if loc := rec.Header().Get("Location"); !strings.Contains(loc, "error=") || !exists(changelog) {
+		t.Fatalf("delete redirected to %q", loc)
+	}
+	rec = post(uiMutateWikiRename, url.Values{"file": {"notes.md"}, "to": {wikiIndexFile}})
+	if loc := rec.Header().Get("Location"); !strings.Contains(loc, "error=") || !exists(filepath.Join(projectWikiDir(root), "notes.md")) {
+		t.Fatalf("rename onto %s redirected to %q", wikiIndexFile, loc)
+	}
+	if b, _ := os.ReadFile(changelog); string(b) != "# Changelog\n" {
+		t.Fatalf("generated page changed: %q", b)
+	}
+
+	rec = httptest.NewRecorder()
+	uiWikiEdit(rec, httptest.NewRequest(http.MethodGet, "/wiki/edit?file="+changelogFile, nil), root, nil)
+	if body := rec.Body.String(); !strings.Contains(body, "generated by Hazel") || !strings.Contains(body, " readonly>") {
+		t.Fatalf("editor not read-only:\n%s", body)
+	}
+}
@@ -0,0 +1,429 @@
+package hazel
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Wiki pages and task markdown can link with [[Page Name]], [[guides/deploy]]
+// or [[HZ-0012]]; [[target|label]] overrides the link text. Page targets match
+// a page's path, file name or first heading, ignoring case, extension and
+// -/_/space differences.
+
+// wikiIndexFile is the generated per-project index page.
+const wikiIndexFile = "INDEX.md"
+
+var wikiLinkRe = regexp.MustCompile(`^\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]+))?\]\]`)
+
+const (
+	wikiLinkPage = "page"
+	wikiLinkTask = "task"
+)
+
+type wikiLinkTarget struct {
+	Kind     string // wikiLinkPage or wikiLinkTask
+	Rel      string // page path
+	TaskID   string
+	Archived bool
+}
+
+// wikiLinks resolves [[...]] targets for one project (or the single-repo root).
+type wikiLinks struct {
+	project string
+	embed   bool
+	pages   map[string]string // normalized name -> page path
+	titles  map[string]string // page path -> title
+	tasks   map[string]bool   // task ID -> archived
+}
+
+func wikiLinkKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, ".md"), ".markdown")
+	s = strings.NewReplacer("_", " ", "-", " ").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// wikiPageTitle returns the first level-one heading after any frontmatter,
+// or the file name.
+func wikiPageTitle(rel string, b []byte) string {
+	_, body, _ := splitWikiFrontmatter(b)
+	for _, line := range strings.Split(string(body), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			if t := strings.TrimSpace(line[2:]); t != "" {
+				return t
+			}
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+}
+
+func isWikiMarkdown(rel string) bool {
+	ext := strings.ToLower(filepath.Ext(rel))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// loadWikiLinks indexes the pages and tasks under base for link resolution.
+func loadWikiLinks(base, project string) *wikiLinks {
+	wl := &wikiLinks{project: project, pages: map[string]string{}, titles: map[string]string{}, tasks: map[string]bool{}}
+	var pages []string
+	for _, rel := range listWikiFiles(projectWikiDir(base)) {
+		if !isWikiMarkdown(rel) {
+			continue
+		}
+		pages = append(pages, rel)
+		b, _ := os.ReadFile(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))
+		wl.titles[rel] = wikiPageTitle(rel, b)
+	}
+	// Paths win over file names, which win over titles.
+	for _, name := range []func(string) string{
+		func(rel string) string { return rel },
+		func(rel string) string { return filepath.Base(rel) },
+		func(rel string) string { return wl.titles[rel] },
+	} {
+		for _, rel := range pages {
+			if k := wikiLinkKey(name(rel)); k != "" {
+				if _, taken := wl.pages[k]; !taken {
+					wl.pages[k] = rel
+				}
+			}
+		}
+	}
+	for dir, archived := range map[string]bool{tasksDir(base): false, archiveDir(base): true} {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if e.IsDir() && taskIDRe.MatchString(e.Name()) {
+				if _, ok := wl.tasks[e.Name()]; !ok || !archived {
+					wl.tasks[e.Name()] = archived
+				}
+			}
+		}
+	}
+	return wl
+}
+
+func (wl *wikiLinks) resolve(target string) (wikiLinkTarget, bool) {
+	if wl == nil {
+		return wikiLinkTarget{}, false
+	}
+	target = strings.TrimSpace(target)
+	if id := strings.ToUpper(target); taskIDRe.MatchString(id) {
+		archived, ok := wl.tasks[id]
+		return wikiLinkTarget{Kind: wikiLinkTask, TaskID: id, Archived: archived}, ok
+	}
+	rel, ok := wl.pages[wikiLinkKey(target)]
+	return wikiLinkTarget{Kind: wikiLinkPage, Rel: rel}, ok
+}
+
+// href returns the UI URL for a resolved target; archived tasks have none.
+func (wl *wikiLinks) href(t wikiLinkTarget) string {
+	if t.Kind == wikiLinkTask {
+		if t.Archived {
+			return ""
+		}
+		if wl.project != "" {
+			return "/task/" + wl.project + "/" + t.TaskID
+		}
+		return "/task/" + t.TaskID
+	}
+	u := "/wiki?project=" + url.QueryEscape(wl.project) + "&file=" + url.QueryEscape(t.Rel)
+	if wl.embed {
+		u += "&embed=1"
+	}
+	return u
+}
+
+// wikiLinkNode is the inline AST node for a [[...]] link.
+type wikiLinkNode struct {
+	ast.BaseInline
+	Target string
+	Label  string
+}
+
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+func (n *wikiLinkNode) Kind() ast.NodeKind { return kindWikiLink }
+
+func (n *wikiLinkNode) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Target": n.Target, "Label": n.Label}, nil)
+}
+
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte { return []byte{'['} }
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	m := wikiLinkRe.FindSubmatch(line)
+	if m == nil || strings.TrimSpace(string(m[1])) == "" {
+		return nil
+	}
+	block.Advance(len(m[0]))
+	n := &wikiLinkNode{Target: strings.TrimSpace(string(m[1])), Label: strings.TrimSpace(string(m[2]))}
+	if n.Label == "" {
+		n.Label = n.Target
+	}
+	return n
+}
+
+type wikiLinkRenderer struct {
+	links *wikiLinks
+}
+
+func (r wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, r.render)
+}
+
+func (r wikiLinkRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*wikiLinkNode)
+	label := util.EscapeHTML([]byte(n.Label))
+	t, ok := r.links.resolve(n.Target)
+	switch {
+	case !ok:
+		fmt.Fprintf(w, `<span class="wikilink broken" title="No page or task named %s">%s</span>`, util.EscapeHTML([]byte(n.Target)), label)
+	case r.links.href(t) == "":
+		fmt.Fprintf(w, `<span class="wikilink" title="Archived task">%s</span>`, label)
+	default:
+		target := ""
+		if t.Kind == wikiLinkTask {
+			target = ` target="_top"`
+		}
+		fmt.Fprintf(w, `<a class="wikilink" href="%s"%s>%s</a>`, util.EscapeHTML([]byte(r.links.href(t))), target, label)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// wikiLinkExtension adds [[...]] links to a goldmark instance. links may be
+// nil, in which case every link renders as unresolved.
+type wikiLinkExtension struct {
+	links *wikiLinks
+}
+
+func (e wikiLinkExtension) Extend(m goldmark.Markdown) {
+	// Ahead of the standard link parser (200) so "[[" is not read as "[".
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(wikiLinkParser{}, 199)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(wikiLinkRenderer{links: e.links}, 199)))
+}
+
+// markdownWikiLinks returns the [[...]] targets in src, skipping code.
+func markdownWikiLinks(src []byte) []string {
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM, wikiLinkExtension{}))
+	doc := md.Parser().Parse(text.NewReader(src))
+	var out []string
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if l, ok := n.(*wikiLinkNode); ok && entering {
+			out = append(out, l.Target)
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// wikiLinkRef is one [[...]] occurrence in a wiki page or task file.
+type wikiLinkRef struct {
+	Source   string // "wiki/<page>" or "<task>/<file>"
+	Kind     string // wikiLinkPage or wikiLinkTask, of the source
+	Rel      string // source page path
+	TaskID   string // source task
+	Target   string
+	Resolved bool
+	To       wikiLinkTarget
+}
+
+// scanWikiLinks collects every [[...]] link in the wiki pages and the
+// task.md/impl.md files (board and archive) under base. The generated index
+// is skipped.
+func scanWikiLinks(base string, wl *wikiLinks) []wikiLinkRef {
+	var refs []wikiLinkRef
+	add := func(src wikiLinkRef, b []byte) {
+		for _, target := range markdownWikiLinks(b) {
+			r := src
+			r.Target = target
+			r.To, r.Resolved = wl.resolve(target)
+			refs = append(refs, r)
+		}
+	}
+	for _, rel := range listWikiFiles(projectWikiDir(base)) {
+		if !isWikiMarkdown(rel) || rel == wikiIndexFile {
+			continue
+		}
+		if b, err := os.ReadFile(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel))); err == nil {
+			add(wikiLinkRef{Source: "wiki/" + rel, Kind: wikiLinkPage, Rel: rel}, b)
+		}
+	}
+	ids := make([]string, 0, len(wl.tasks))
+	for id := range wl.tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		dir := filepath.Join(tasksDir(base), id)
+		if wl.tasks[id] {
+			dir = filepath.Join(archiveDir(base), id)
+		}
+		for _, name := range []string{"task.md", "impl.md"} {
+			if b, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+				add(wikiLinkRef{Source: id + "/" + name, Kind: wikiLinkTask, TaskID: id}, b)
+			}
+		}
+	}
+	return refs
+}
+
+// wikiBacklink is a page or task that links to the one being viewed.
+type wikiBacklink struct {
+	Title string
+	Href  string
+}
+
+// wikiBacklinks lists the pages and tasks linking to target, one entry per source.
+func wikiBacklinks(base string, wl *wikiLinks, target wikiLinkTarget) []wikiBacklink {
+	var out []wikiBacklink
+	seen := map[string]bool{}
+	for _, r := range scanWikiLinks(base, wl) {
+		if !r.Resolved || r.To.Kind != target.Kind || r.To.Rel != target.Rel || r.To.TaskID != target.TaskID {
+			continue
+		}
+		if r.Kind == target.Kind && r.Rel == target.Rel && r.TaskID == target.TaskID {
+			continue // self link
+		}
+		key := r.Rel + r.TaskID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if r.Kind == wikiLinkPage {
+			out = append(out, wikiBacklink{Title: wl.titles[r.Rel], Href: wl.href(wikiLinkTarget{Kind: wikiLinkPage, Rel: r.Rel})})
+			continue
+		}
+		archived := wl.tasks[r.TaskID]
+		out = append(out, wikiBacklink{Title: r.TaskID, Href: wl.href(wikiLinkTarget{Kind: wikiLinkTask, TaskID: r.TaskID, Archived: archived})})
+	}
+	return out
+}
+
+// brokenWikiLinks returns "source: [[target]]" for every unresolved link.
+func brokenWikiLinks(base string) []string {
+	var out []string
+	for _, r := range scanWikiLinks(base, loadWikiLinks(base, "")) {
+		if !r.Resolved {
+			out = append(out, fmt.Sprintf("%s: broken link [[%s]]", r.Source, r.Target))
+		}
+	}
+	return out
+}
+
+// wikiIndexGroup is the index section for a page: its type, or for untyped
+// pages its top-level folder ("Pages" at the top level).
+func wikiIndexGroup(p wikiPage) string {
+	if p.Meta.Type != "" {
+		return wikiTypeHeading(p.Meta.Type)
+	}
+	if i := strings.Index(p.Rel, "/"); i > 0 {
+		return p.Rel[:i]
+	}
+	return wikiTypeHeading("")
+}
+
+// writeWikiIndex regenerates wiki/INDEX.md: pages due for review, the most
+// recently changed pages, then every page grouped by type, newest first. The
+// file is only rewritten when it changes.
+func writeWikiIndex(base string) error {
+	dir := projectWikiDir(base)
+	if !exists(dir) {
+		return nil
+	}
+	pages := loadWikiPages(base)
+	sort.SliceStable(pages, func(i, j int) bool {
+		if !pages[i].ModTime.Equal(pages[j].ModTime) {
+			return pages[i].ModTime.After(pages[j].ModTime)
+		}
+		return pages[i].Rel < pages[j].Rel
+	})
+	line := func(p wikiPage, note string) string {
+		target := strings.TrimSuffix(p.Rel, filepath.Ext(p.Rel))
+		return fmt.Sprintf("- [[%s|%s]] · %s\n", target, p.Title, note)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("# Index\n\nGenerated by Hazel from the pages in this wiki; edits to this file are overwritten.\n")
+	if len(pages) == 0 {
+		b.WriteString("\nNo pages yet.\n")
+	}
+	now := time.Now()
+	var stale []wikiPage
+	for _, p := range pages {
+		if p.Stale(now) {
+			stale = append(stale, p)
+		}
+	}
+	if len(stale) > 0 {
+		b.WriteString("\n## Needs Review\n\n")
+		for _, p := range stale {
+			b.WriteString(line(p, "review by "+p.Meta.ReviewBy))
+		}
+	}
+	if len(pages) > 0 {
+		b.WriteString("\n## Recently Changed\n\n")
+		for i, p := range pages {
+			if i == 10 {
+				break
+			}
+			b.WriteString(line(p, p.ModTime.Format("2006-01-02")))
+		}
+	}
+	groups := map[string][]wikiPage{}
+	for _, p := range pages {
+		groups[wikiIndexGroup(p)] = append(groups[wikiIndexGroup(p)], p)
+	}
+	// Typed sections in type order, then top-level pages, then folders.
+	rank := func(g string) int {
+		for i, t := range wikiPageTypes {
+			if g == wikiTypeHeading(t) {
+				return i
+			}
+		}
+		if g == wikiTypeHeading("") {
+			return len(wikiPageTypes)
+		}
+		return len(wikiPageTypes) + 1
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if rank(names[i]) != rank(names[j]) {
+			return rank(names[i]) < rank(names[j])
+		}
+		return names[i] < names[j]
+	})
+	for _, g := range names {
+		fmt.Fprintf(&b, "\n## %s\n\n", g)
+		for _, p := range groups[g] {
+			b.WriteString(line(p, p.ModTime.Format("2006-01-02")))
+		}
+	}
+	path := filepath.Join(dir, wikiIndexFile)
+	if cur, err := os.ReadFile(path); err == nil && bytes.Equal(cur, b.Bytes()) {
+		return nil
+	}
+	return writeFileAtomic(path, b.Bytes(), 0o644)
+}
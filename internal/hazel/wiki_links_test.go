@@ -0,0 +1,115 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWikiLinksResolveAndBacklinks(t *testing.T) {
+	root := t.TempDir()
+	if err := InitRepo(nil, root, InitOptions{}); err != nil { // ctx unused
+		t.Fatalf("init repo: %v", err)
+	}
+	write := func(path, body string) {
+		t.Helper()
+		if err := ensureDir(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wiki := projectWikiDir(root)
+	write(filepath.Join(wiki, "FEATURES_AND_USAGE.md"), "# Features and Usage\n\nSee [[Deploy Guide]] and [[HZ-0012|the canary task]].\n")
+	write(filepath.Join(wiki, "guides", "deploy.md"), "# Deploy Guide\n\nBack to [[features-and-usage]]. Not a link: `[[Nowhere]]`.\n\n[[Missing Page]]\n")
+	write(taskFile(root, "HZ-0012", "task.md"), "# Task\n\nDocs live in [[guides/deploy]]; see [[HZ-0001]].\n")
+	write(filepath.Join(archiveDir(root), "HZ-0001", "task.md"), "# Task\n")
+
+	wl := loadWikiLinks(root, "app")
+	for target, want := range map[string]string{
+		"Deploy Guide":          "guides/deploy.md",
+		"guides/deploy.md":      "guides/deploy.md",
+		"deploy":                "guides/deploy.md",
+		"Features and Usage":    "FEATURES_AND_USAGE.md",
+		"features_and_usage.md": "FEATURES_AND_USAGE.md",
+	} {
+		if got, ok := wl.resolve(target); !ok || got.Rel != want {
+			t.Fatalf("resolve(%q) = %+v, %v", target, got, ok)
+		}
+	}
+	if got, ok := wl.resolve("hz-0001"); !ok || !got.Archived {
+		t.Fatalf("archived task = %+v, %v", got, ok)
+	}
+
+	html := string(renderWikiMarkdown([]byte("[[Deploy Guide]] [[HZ-0012|canary]] [[HZ-0001]] [[Nope]] `[[code]]`"), wl))
+	for _, want := range []string{
+		`<a class="wikilink" href="/wiki?project=app&amp;file=guides%2Fdeploy.md">Deploy Guide</a>`,
+		`<a class="wikilink" href="/task/app/HZ-0012" target="_top">canary</a>`,
+		`<span class="wikilink" title="Archived task">HZ-0001</span>`,
+		`<span class="wikilink broken" title="No page or task named Nope">Nope</span>`,
+		`<code>[[code]]</code>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("rendered %s\nmissing %s", html, want)
+		}
+	}
+
+	back := wikiBacklinks(root, wl, wikiLinkTarget{Kind: wikiLinkPage, Rel: "guides/deploy.md"})
+	if len(back) != 2 || back[0].Title != "Features and Usage" || back[1].Href != "/task/app/HZ-0012" {
+		t.Fatalf("page backlinks = %+v", back)
+	}
+	if back := wikiBacklinks(root, wl, wikiLinkTarget{Kind: wikiLinkTask, TaskID: "HZ-0012"}); len(back) != 1 || back[0].Title != "Features and Usage" {
+		t.Fatalf("task backlinks = %+v", back)
+	}
+	if broken := brokenWikiLinks(root); len(broken) != 1 || broken[0] != "wiki/guides/deploy.md: broken link [[Missing Page]]" {
+		t.Fatalf("broken = %v", broken)
+	}
+}
+
+func TestWriteWikiIndex(t *testing.T) {
+	base := t.TempDir()
+	wiki := projectWikiDir(base)
+	if err := ensureDir(filepath.Join(wiki, "runbooks")); err != nil {
+		t.Fatal(err)
+	}
+	for rel, body := range map[string]string{
+		"README.md":             "# Overview\n",
+		"runbooks/restart.md":   "# Restart the API\n",
+		"runbooks/rollback.md":  "no heading\n",
+		"diagrams/network.png":  "",
+		"decisions/postgres.md": "# Use Postgres\n",
+	} {
+		p := filepath.Join(wiki, filepath.FromSlash(rel))
+		_ = ensureDir(filepath.Dir(p))
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeWikiIndex(base); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(wiki, wikiIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := string(b)
+	for _, want := range []string{"## Recently Changed", "## Pages\n\n- [[README|Overview]]", "## decisions", "- [[runbooks/restart|Restart the API]]", "[[runbooks/rollback|rollback]]"} {
+		if !strings.Contains(idx, want) {
+			t.Fatalf("index missing %q:\n%s", want, idx)
+		}
+	}
+	if strings.Contains(idx, "network") || strings.Index(idx, "## Pages") > strings.Index(idx, "## decisions") {
+		t.Fatalf("unexpected index layout:\n%s", idx)
+	}
+	// Every index link resolves.
+	if broken := brokenWikiLinks(base); len(broken) != 0 {
+		t.Fatalf("broken = %v", broken)
+	}
+	for _, target := range markdownWikiLinks(b) {
+		if _, ok := loadWikiLinks(base, "").resolve(target); !ok {
+			t.Fatalf("index link %q does not resolve", target)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package hazel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Wiki pages may start with YAML frontmatter:
+//
+//	---
+//	type: runbook
+//	tags: [deploy, k8s]
+//	owner: platform
+//	review_by: 2026-06-01
+//	source_task: HZ-0012
+//	---
+//
+// Every field is optional; pages without frontmatter stay untyped.
+
+var wikiPageTypes = []string{"decision", "runbook", "glossary", "architecture", "note"}
+
+type wikiPageMeta struct {
+	Type       string   `yaml:"type,omitempty" json:"type,omitempty"`
+	Tags       []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Owner      string   `yaml:"owner,omitempty" json:"owner,omitempty"`
+	ReviewBy   string   `yaml:"review_by,omitempty" json:"review_by,omitempty"` // YYYY-MM-DD
+	SourceTask string   `yaml:"source_task,omitempty" json:"source_task,omitempty"`
+}
+
+func (m wikiPageMeta) empty() bool {
+	return m.Type == "" && len(m.Tags) == 0 && m.Owner == "" && m.ReviewBy == "" && m.SourceTask == ""
+}
+
+// splitWikiFrontmatter separates a page's frontmatter from its body. The body
+// is returned without the frontmatter even when the YAML is invalid.
+func splitWikiFrontmatter(b []byte) (wikiPageMeta, []byte, error) {
+	var meta wikiPageMeta
+	src := bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(src, []byte("---\n")) {
+		return meta, b, nil
+	}
+	rest := src[4:]
+	end := bytes.Index(rest, []byte("\n---\n"))
+	var head, body []byte
+	switch {
+	case bytes.HasPrefix(rest, []byte("---\n")):
+		body = rest[4:]
+	case end >= 0:
+		head, body = rest[:end], rest[end+5:]
+	case bytes.HasSuffix(rest, []byte("\n---")):
+		head = rest[:len(rest)-4]
+	default:
+		return meta, b, nil // an opening rule with no closing one is just markdown
+	}
+	if err := yaml.Unmarshal(head, &meta); err != nil {
+		return wikiPageMeta{}, body, fmt.Errorf("frontmatter: %w", err)
+	}
+	meta.Type = strings.ToLower(strings.TrimSpace(meta.Type))
+	meta.Owner = strings.TrimSpace(meta.Owner)
+	meta.ReviewBy = strings.TrimSpace(meta.ReviewBy)
+	meta.SourceTask = strings.ToUpper(strings.TrimSpace(meta.SourceTask))
+	tags := meta.Tags[:0]
+	for _, t := range meta.Tags {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	meta.Tags = tags
+	if meta.Type != "" && !slices.Contains(wikiPageTypes, meta.Type) {
+		return meta, body, fmt.Errorf("frontmatter: unknown type %q (want one of %s)", meta.Type, strings.Join(wikiPageTypes, ", "))
+	}
+	if meta.ReviewBy != "" {
+		if _, err := time.Parse("2006-01-02", meta.ReviewBy); err != nil {
+			return meta, body, fmt.Errorf("frontmatter: review_by %q is not YYYY-MM-DD", meta.ReviewBy)
+		}
+	}
+	if meta.SourceTask != "" && !taskIDRe.MatchString(meta.SourceTask) {
+		return meta, body, fmt.Errorf("frontmatter: source_task %q is not a task ID", meta.SourceTask)
+	}
+	return meta, body, nil
+}
+
+// wikiPage is a markdown page with its parsed frontmatter.
+type wikiPage struct {
+	Rel     string
+	Title   string
+	Meta    wikiPageMeta
+	MetaErr string
+	ModTime time.Time
+}
+
+// Stale reports whether the page is past its review_by date.
+func (p wikiPage) Stale(now time.Time) bool {
+	d, err := time.ParseInLocation("2006-01-02", p.Meta.ReviewBy, now.Location())
+	return err == nil && now.After(d.AddDate(0, 0, 1))
+}
+
+// loadWikiPages reads every markdown page under base's wiki except the
+// generated index, sorted by path.
+func loadWikiPages(base string) []wikiPage {
+	dir := projectWikiDir(base)
+	var pages []wikiPage
+	for _, rel := range listWikiFiles(dir) {
+		if !isWikiMarkdown(rel) || rel == wikiIndexFile {
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		st, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		b, _ := os.ReadFile(path)
+		p := wikiPage{Rel: rel, Title: wikiPageTitle(rel, b), ModTime: st.ModTime()}
+		meta, _, err := splitWikiFrontmatter(b)
+		p.Meta = meta
+		if err != nil {
+			p.MetaErr = err.Error()
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// wikiPageWarnings reports invalid frontmatter and pages past review_by.
+func wikiPageWarnings(base string, now time.Time) []string {
+	var out []string
+	for _, p := range loadWikiPages(base) {
+		switch {
+		case p.MetaErr != "":
+			out = append(out, fmt.Sprintf("wiki/%s: %s", p.Rel, p.MetaErr))
+		case p.Stale(now):
+			out = append(out, fmt.Sprintf("wiki/%s: past review_by %s", p.Rel, p.Meta.ReviewBy))
+		}
+	}
+	return out
+}
+
+// wikiTypeHeading is the index section name for a page type.
+func wikiTypeHeading(t string) string {
+	switch t {
+	case "glossary", "architecture":
+		return strings.ToUpper(t[:1]) + t[1:]
+	case "":
+		return "Pages"
+	}
+	return strings.ToUpper(t[:1]) + t[1:] + "s"
+}
+
+// wikiChange is one entry in the recently changed feed.
+type wikiChange struct {
+	Rel    string
+	Op     string
+	At     time.Time
+	Exists bool
+}
+
+// recentWikiChanges returns the latest change per page, newest first: the
+// last history revision, or the file's mtime when it was changed outside the
+// editor afterwards. Deleted pages appear through their history.
+func recentWikiChanges(base string, limit int) []wikiChange {
+	latest := map[string]wikiChange{}
+	hist := wikiHistoryDir(base)
+	_ = filepath.WalkDir(hist, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() != "revisions.json" {
+			return nil
+		}
+		rel, rerr := filepath.Rel(hist, filepath.Dir(path))
+		if rerr != nil {
+			return nil
+		}
+		b, rerr := os.ReadFile(path)
+		var revs []wikiRevision
+		if rerr != nil || json.Unmarshal(b, &revs) != nil || len(revs) == 0 {
+			return nil
+		}
+		last := revs[len(revs)-1]
+		latest[filepath.ToSlash(rel)] = wikiChange{Rel: filepath.ToSlash(rel), Op: last.Op, At: last.At}
+		return nil
+	})
+	for _, p := range loadWikiPages(base) {
+		c, ok := latest[p.Rel]
+		if !ok || p.ModTime.After(c.At.Add(time.Second)) {
+			c = wikiChange{Rel: p.Rel, Op: wikiOpEdit, At: p.ModTime}
+			if !ok {
+				c.Op = wikiOpCreate
+			}
+		}
+		c.Exists = true
+		latest[p.Rel] = c
+	}
+	out := make([]wikiChange, 0, len(latest))
+	for _, c := range latest {
+		if c.Rel == wikiIndexFile || (!c.Exists && c.Op != wikiOpDelete) {
+			continue // renamed away, or the generated index
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].At.Equal(out[j].At) {
+			return out[i].At.After(out[j].At)
+		}
+		return out[i].Rel < out[j].Rel
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// wikiTemplatesDir holds the per-type page templates used by the editor.
+func wikiTemplatesDir(base string) string {
+	return filepath.Join(hazelDir(base), "templates", "wiki")
+}
+
+// wikiTemplate returns the project's template for a page type, falling back
+// to the built-in one.
+func wikiTemplate(base, typ string) (string, bool) {
+	body, ok := wikiTemplates[typ]
+	if !ok {
+		return "", false
+	}
+	if b, err := os.ReadFile(filepath.Join(wikiTemplatesDir(base), typ+".md")); err == nil {
+		return string(b), true
+	}
+	return body, true
+}
+
+func mustWikiTemplate(base, typ string) string {
+	body, _ := wikiTemplate(base, typ)
+	return body
+}
+
+// ensureWikiTemplates writes the built-in templates that are missing.
+func ensureWikiTemplates(base string) error {
+	if err := ensureDir(wikiTemplatesDir(base)); err != nil {
+		return err
+	}
+	for _, typ := range wikiPageTypes {
+		p := filepath.Join(wikiTemplatesDir(base), typ+".md")
+		if exists(p) {
+			continue
+		}
+		if err := writeFileAtomic(p, []byte(wikiTemplates[typ]), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var wikiTemplates = map[string]string{
+	"decision": `---
+type: decision
+tags: []
+owner:
+review_by:
+source_task:
+---
+# Decision: TITLE
+
+## Status
+
+Accepted
+
+## Context
+
+What forces are at play and why a decision is needed.
+
+## Decision
+
+What we chose.
+
+## Consequences
+
+What becomes easier or harder because of it.
+`,
+	"runbook": `---
+type: runbook
+tags: []
+owner:
+review_by:
+---
+# Runbook: TITLE
+
+## When To Use
+
+## Steps
+
+1.
+
+## Verify
+
+## Rollback
+`,
+	"glossary": `---
+type: glossary
+tags: []
+---
+# Glossary
+
+| Term | Meaning |
+| --- | --- |
+| | |
+`,
+	"architecture": `---
+type: architecture
+tags: []
+owner:
+review_by:
+---
+# Architecture: TITLE
+
+## Overview
+
+## Components
+
+## Data Flow
+
+## Constraints
+`,
+	"note": `---
+type: note
+tags: []
+source_task:
+---
+# TITLE
+`,
+}
@@ -0,0 +1,138 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitWikiFrontmatter(t *testing.T) {
+	meta, body, err := splitWikiFrontmatter([]byte("---\r\ntype: Runbook\r\ntags: [Deploy, k8s, deploy]\r\nowner: ops\r\nreview_by: 2026-01-31\r\nsource_task: hz-0012\r\n---\r\n# Restart\r\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if meta.Type != "runbook" || strings.Join(meta.Tags, ",") != "deploy,k8s" || meta.Owner != "ops" || meta.ReviewBy != "2026-01-31" || meta.SourceTask != "HZ-0012" {
+		t.Fatalf("meta = %+v", meta)
+	}
+	if string(body) != "# Restart\n" {
+		t.Fatalf("body = %q", body)
+	}
+	p := wikiPage{Meta: meta}
+	if p.Stale(time.Date(2026, 1, 31, 23, 0, 0, 0, time.Local)) || !p.Stale(time.Date(2026, 2, 1, 1, 0, 0, 0, time.Local)) {
+		t.Fatalf("stale boundary wrong")
+	}
+
+	for _, src := range []string{"# Plain page\n", "---\nnot closed\n"} {
+		if meta, body, err := splitWikiFrontmatter([]byte(src)); err != nil || !meta.empty() || string(body) != src {
+			t.Fatalf("%q: meta=%+v body=%q err=%v", src, meta, body, err)
+		}
+	}
+	for src, want := range map[string]string{
+		"---\ntype: essay\n---\nx\n":        "unknown type",
+		"---\nreview_by: soon\n---\nx\n":    "YYYY-MM-DD",
+		"---\nsource_task: 12\n---\nx\n":    "not a task ID",
+		"---\ntags: [unclosed\n---\nbody\n": "frontmatter",
+	} {
+		_, body, err := splitWikiFrontmatter([]byte(src))
+		if err == nil || !strings.Contains(err.Error(), want) || strings.Contains(string(body), "---") {
+			t.Fatalf("%q: body=%q err=%v", src, body, err)
+		}
+	}
+}
+
+func TestTypedWikiPages(t *testing.T) {
+	root := t.TempDir()
+	project := TrackedProject{Key: "app", Name: "App", RepoPath: t.TempDir(), StorageRoot: root}
+	if err := ensureProjectWiki(project); err != nil {
+		t.Fatalf("ensure wiki: %v", err)
+	}
+	wiki := projectWikiDir(root)
+	if exists(filepath.Join(wiki, "FEATURES_AND_USAGE.md")) {
+		t.Fatalf("generic scaffold still seeded")
+	}
+	for _, typ := range wikiPageTypes {
+		if !exists(filepath.Join(wikiTemplatesDir(root), typ+".md")) {
+			t.Fatalf("missing %s template", typ)
+		}
+	}
+	pages := map[string]wikiPage{}
+	for _, p := range loadWikiPages(root) {
+		pages[p.Rel] = p
+	}
+	if p := pages["ARCHITECTURE.md"]; p.Meta.Type != "architecture" || p.Title != "Architecture: App" || p.MetaErr != "" {
+		t.Fatalf("architecture seed = %+v", p)
+	}
+	if p := pages["runbooks/local-development.md"]; p.Meta.Type != "runbook" || p.Title != "Runbook: Local Development" {
+		t.Fatalf("runbook seed = %+v", p)
+	}
+
+	// A stale decision and a broken page show up in the index and in doctor.
+	if err := os.WriteFile(filepath.Join(wiki, "postgres.md"), []byte("---\ntype: decision\nreview_by: 2020-01-01\n---\n# Use Postgres\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wiki, "bad.md"), []byte("---\ntype: essay\n---\n# Bad\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeWikiIndex(root); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(filepath.Join(wiki, wikiIndexFile))
+	idx := string(b)
+	for _, want := range []string{"## Needs Review\n\n- [[postgres|Use Postgres]] · review by 2020-01-01", "## Decisions", "## Runbooks", "## Architecture", "## Glossary"} {
+		if !strings.Contains(idx, want) {
+			t.Fatalf("index missing %q:\n%s", want, idx)
+		}
+	}
+	if strings.Index(idx, "## Decisions") > strings.Index(idx, "## Runbooks") || strings.Index(idx, "## Architecture") > strings.Index(idx, "## Pages") {
+		t.Fatalf("sections out of order:\n%s", idx)
+	}
+	warns := strings.Join(wikiPageWarnings(root, time.Now()), "\n")
+	if !strings.Contains(warns, "wiki/postgres.md: past review_by 2020-01-01") || !strings.Contains(warns, `wiki/bad.md: frontmatter: unknown type "essay"`) {
+		t.Fatalf("warnings = %s", warns)
+	}
+
+	// The feed prefers history revisions and includes deleted pages.
+	if _, err := saveWikiPage(root, "notes/today.md", []byte("# Today\n"), true, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := deleteWikiPage(root, "bad.md"); err != nil {
+		t.Fatal(err)
+	}
+	recent := recentWikiChanges(root, 3)
+	if len(recent) != 3 || recent[0].Rel != "bad.md" || recent[0].Op != wikiOpDelete || recent[0].Exists || recent[1].Rel != "notes/today.md" || recent[1].Op != wikiOpCreate {
+		t.Fatalf("recent = %+v", recent)
+	}
+}
+
+func TestSourceReadmeOnlyRewrittenWhenChanged(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# App\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project := TrackedProject{Key: "app", Name: "App", RepoPath: repo, StorageRoot: t.TempDir()}
+	if err := ensureProjectWiki(project); err != nil {
+		t.Fatalf("ensure wiki: %v", err)
+	}
+	page := filepath.Join(projectWikiDir(project.StorageRoot), sourceReadmeFile)
+	old := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(page, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureProjectWiki(project); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := os.Stat(page); err != nil || !st.ModTime().Equal(old) {
+		t.Fatalf("unchanged README rewritten: %v, %v", st.ModTime(), err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("# App v2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureProjectWiki(project); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(page); !strings.Contains(string(b), "# App v2") {
+		t.Fatalf("changed README not synced:\n%s", b)
+	}
+}
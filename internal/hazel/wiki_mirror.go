@@ -0,0 +1,338 @@
+package hazel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mirror rules copy repository docs into the project wiki on sync-wiki:
+//
+//	wiki_mirrors:
+//	  - source: docs/**/*.md      # ** matches any number of directories
+//	    target: docs              # wiki folder; defaults to the glob's fixed prefix
+//	  - docs/adr/*.md             # shorthand for {source: docs/adr/*.md}
+//
+// Mirrored pages keep the source hierarchy below the prefix, are read-only in
+// the editor and are removed when their source disappears. Which page came
+// from which file is recorded in .hazel/wiki_mirror.json.
+
+// WikiMirrorRule is one entry of `wiki_mirrors:`.
+type WikiMirrorRule struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target,omitempty"`
+}
+
+func (m *WikiMirrorRule) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		m.Source = n.Value
+		return nil
+	}
+	type plain WikiMirrorRule
+	return n.Decode((*plain)(m))
+}
+
+type wikiMirrorManifest struct {
+	Pages   map[string]string `json:"pages"`             // wiki page -> repo source
+	Skipped []string          `json:"skipped,omitempty"` // reported by doctor
+}
+
+func wikiMirrorManifestPath(base string) string {
+	return filepath.Join(hazelDir(base), "wiki_mirror.json")
+}
+
+func loadWikiMirrorManifest(base string) wikiMirrorManifest {
+	var m wikiMirrorManifest
+	if b, err := os.ReadFile(wikiMirrorManifestPath(base)); err == nil {
+		_ = json.Unmarshal(b, &m)
+	}
+	if m.Pages == nil {
+		m.Pages = map[string]string{}
+	}
+	return m
+}
+
+// wikiMirrorSource returns the repo file a wiki page is mirrored from.
+func wikiMirrorSource(base, rel string) (string, bool) {
+	src, ok := loadWikiMirrorManifest(base).Pages[rel]
+	return src, ok
+}
+
+// checkWikiMirror refuses UI changes to mirrored pages.
+func checkWikiMirror(base, rel string) error {
+	if src, ok := wikiMirrorSource(base, rel); ok {
+		return fmt.Errorf("wiki page %s is mirrored from %s; edit it in the repository", rel, src)
+	}
+	return nil
+}
+
+// checkWikiWritable refuses UI changes to generated and mirrored pages.
+func checkWikiWritable(base, rel string) error {
+	if generatedWikiPage(rel) {
+		return fmt.Errorf("wiki page %s is generated by Hazel; edits would be overwritten", rel)
+	}
+	return checkWikiMirror(base, rel)
+}
+
+func wikiMirrorGlobMatch(pattern, name string) bool {
+	return wikiMirrorMatchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func wikiMirrorMatchSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if wikiMirrorMatchSegments(pat[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], parts[0]); !ok {
+			return false
+		}
+		pat, parts = pat[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// wikiMirrorPrefix is the directory part of a glob before its first wildcard;
+// mirrored paths keep everything below it.
+func wikiMirrorPrefix(pattern string) string {
+	segs := strings.Split(pattern, "/")
+	for i, s := range segs {
+		if strings.ContainsAny(s, "*?[") {
+			return strings.Join(segs[:i], "/")
+		}
+	}
+	return strings.Join(segs[:len(segs)-1], "/")
+}
+
+func validWikiMirrorGlob(pattern string) bool {
+	for _, s := range strings.Split(pattern, "/") {
+		if _, err := path.Match(s, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// syncWikiMirrors applies the rules to the docs committed on the project's
+// base branch (origin's copy when there is one), so drafts in the worktree or
+// on a checked-out task branch never reach the wiki. Pages that exist but were
+// not mirrored are never overwritten; the conflict is recorded for doctor
+// instead.
+func syncWikiMirrors(p TrackedProject, rules []WikiMirrorRule, branch string, fileURL func(file string, raw bool) string) error {
+	base := p.StorageRoot
+	old := loadWikiMirrorManifest(base)
+	if len(rules) == 0 && len(old.Pages) == 0 {
+		return nil
+	}
+	ref := baseRef(p.RepoPath, branch)
+	files, err := listRefFiles(p.RepoPath, ref)
+	if err != nil {
+		// Leave the mirror as it is rather than delete every page.
+		old.Skipped = []string{fmt.Sprintf("wiki_mirrors: cannot list %s: %v; mirrored pages left as they were", ref, err)}
+		return writeFileAtomic(wikiMirrorManifestPath(base), mustJSONIndent(old), 0o644)
+	}
+	next := wikiMirrorManifest{Pages: map[string]string{}}
+	srcToWiki := map[string]string{}
+	for i, rule := range rules {
+		pattern := strings.Trim(strings.TrimSpace(filepath.ToSlash(rule.Source)), "/")
+		if pattern == "" || strings.HasPrefix(pattern, "../") || !validWikiMirrorGlob(pattern) {
+			next.Skipped = append(next.Skipped, fmt.Sprintf("wiki_mirrors[%d]: invalid source %q", i, rule.Source))
+			continue
+		}
+		prefix := wikiMirrorPrefix(pattern)
+		target := strings.Trim(strings.TrimSpace(rule.Target), "/")
+		if target == "" {
+			target = prefix
+		}
+		for _, f := range files {
+			if !isWikiMarkdown(f) || !wikiMirrorGlobMatch(pattern, f) {
+				continue
+			}
+			if _, done := srcToWiki[f]; done {
+				continue // an earlier rule already mirrors it
+			}
+			rest := f
+			if prefix != "" {
+				rest = strings.TrimPrefix(f, prefix+"/")
+			}
+			rel, ok := cleanWikiRel(path.Join(target, rest))
+			if !ok {
+				next.Skipped = append(next.Skipped, fmt.Sprintf("wiki_mirrors[%d]: target %q leaves the wiki", i, rule.Target))
+				break
+			}
+			if other, taken := next.Pages[rel]; taken {
+				next.Skipped = append(next.Skipped, fmt.Sprintf("wiki/%s: %s and %s both mirror to it; keeping %s", rel, other, f, other))
+				continue
+			}
+			_, mirrored := old.Pages[rel]
+			if generatedWikiPage(rel) || (!mirrored && exists(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))) {
+				next.Skipped = append(next.Skipped, fmt.Sprintf("wiki/%s: page exists and is not mirrored; not overwriting it with %s", rel, f))
+				continue
+			}
+			next.Pages[rel] = f
+			srcToWiki[f] = rel
+		}
+	}
+
+	rels := make([]string, 0, len(next.Pages))
+	for rel := range next.Pages {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		src := next.Pages[rel]
+		text, err := runCmd(p.RepoPath, nil, "git", "show", ref+":"+src)
+		if err != nil {
+			return err
+		}
+		body := mirrorWikiPage(src, rel, []byte(text+"\n"), srcToWiki, fileURL)
+		cur, err := os.ReadFile(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel)))
+		if err == nil && bytes.Equal(cur, body) {
+			continue
+		}
+		if _, err := saveWikiPage(base, rel, body, err != nil, "", wikiOpMirror); err != nil {
+			return err
+		}
+	}
+	for rel := range old.Pages {
+		if _, keep := next.Pages[rel]; keep {
+			continue
+		}
+		if exists(filepath.Join(projectWikiDir(base), filepath.FromSlash(rel))) {
+			if err := deleteWikiPage(base, rel); err != nil {
+				return err
+			}
+		}
+	}
+	return writeFileAtomic(wikiMirrorManifestPath(base), mustJSONIndent(next), 0o644)
+}
+
+// listRefFiles lists the files committed at ref, skipping Hazel's own state.
+func listRefFiles(repo, ref string) ([]string, error) {
+	out, err := runCmd(repo, nil, "git", "ls-tree", "-r", "-z", "--name-only", ref)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" && !strings.HasPrefix(f, ".hazel/") {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+var (
+	mirrorInlineLinkRe = regexp.MustCompile(`(!?)\[([^\]\n]*)\]\(([^)\s]+)((?:\s+"[^"\n]*")?)\)`)
+	mirrorRefLinkRe    = regexp.MustCompile(`^(\s{0,3}\[[^\]\n]+\]:\s*)(\S+)(.*)$`)
+)
+
+// mirrorWikiPage returns the wiki copy of a repo doc: a source banner after
+// any frontmatter, and relative links rewritten. Links to other mirrored docs
+// become [[wiki links]]; links to other repo files point at the forge when
+// the origin has a web URL.
+func mirrorWikiPage(src, rel string, b []byte, srcToWiki map[string]string, fileURL func(file string, raw bool) string) []byte {
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	front := ""
+	if strings.HasPrefix(text, "---\n") {
+		if end := strings.Index(text[4:], "\n---\n"); end >= 0 {
+			front, text = text[:4+end+5], text[4+end+5:]
+		}
+	}
+	lines := strings.Split(text, "\n")
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := mirrorRefLinkRe.FindStringSubmatch(line); m != nil {
+			if u, ok := mirrorRepoURL(src, m[2], false, fileURL); ok {
+				lines[i] = m[1] + u + m[3]
+			}
+			continue
+		}
+		lines[i] = mirrorInlineLinkRe.ReplaceAllStringFunc(line, func(s string) string {
+			m := mirrorInlineLinkRe.FindStringSubmatch(s)
+			image, label, target, title := m[1] == "!", m[2], m[3], m[4]
+			if !image && !strings.Contains(label, "|") {
+				if file, _, ok := mirrorResolve(src, target); ok {
+					if page, mirrored := srcToWiki[file]; mirrored {
+						page = strings.TrimSuffix(page, path.Ext(page))
+						if label == "" {
+							return "[[" + page + "]]"
+						}
+						return "[[" + page + "|" + label + "]]"
+					}
+				}
+			}
+			if u, ok := mirrorRepoURL(src, target, image, fileURL); ok {
+				return m[1] + "[" + label + "](" + u + title + ")"
+			}
+			return s
+		})
+	}
+	banner := fmt.Sprintf("> Mirrored from `%s` by `hazel sync-wiki`; edit the source in the repository.\n\n", src)
+	return []byte(front + banner + strings.Join(lines, "\n"))
+}
+
+// mirrorResolve resolves a relative link in src to a repo file and anchor.
+func mirrorResolve(src, target string) (string, string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") || strings.HasPrefix(target, "//") {
+		return "", "", false
+	}
+	anchor := ""
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target, anchor = target[:i], target[i:]
+	}
+	var file string
+	if strings.HasPrefix(target, "/") {
+		file = path.Clean(strings.TrimPrefix(target, "/"))
+	} else {
+		file = path.Clean(path.Join(path.Dir(src), target))
+	}
+	if file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return "", "", false
+	}
+	return file, anchor, true
+}
+
+func mirrorRepoURL(src, target string, raw bool, fileURL func(file string, raw bool) string) (string, bool) {
+	file, anchor, ok := mirrorResolve(src, target)
+	if !ok || fileURL == nil {
+		return "", false
+	}
+	u := fileURL(file, raw)
+	if u == "" {
+		return "", false
+	}
+	return u + anchor, true
+}
+
+// wikiMirrorWarnings reports the mirror conflicts from the last sync.
+func wikiMirrorWarnings(base string) []string {
+	return loadWikiMirrorManifest(base).Skipped
+}
@@ -0,0 +1,178 @@
+package hazel
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWikiMirrorGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"docs/**/*.md", "docs/intro.md", true},
+		{"docs/**/*.md", "docs/adr/0001-use-go.md", true},
+		{"docs/**/*.md", "src/docs/intro.md", false},
+		{"docs/adr/*.md", "docs/adr/x/y.md", false},
+		{"**/DESIGN.md", "pkg/cache/DESIGN.md", true},
+		{"**/DESIGN.md", "DESIGN.md", true},
+	} {
+		if got := wikiMirrorGlobMatch(tc.pattern, tc.name); got != tc.want {
+			t.Fatalf("match(%q, %q) = %v", tc.pattern, tc.name, got)
+		}
+	}
+	if p := wikiMirrorPrefix("docs/**/*.md"); p != "docs" {
+		t.Fatalf("prefix = %q", p)
+	}
+	if p := wikiMirrorPrefix("notes/design.md"); p != "notes" {
+		t.Fatalf("prefix = %q", p)
+	}
+}
+
+func TestSyncWikiMirrors(t *testing.T) {
+	repo := t.TempDir()
+	write := func(dir, rel, body string) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := ensureDir(filepath.Dir(p)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(repo, "docs/guide.md", "---\ntitle: Guide\n---\n# Guide\n\nSee [the ADR](adr/0001-use-go.md#context), [setup](../CONTRIBUTING.md) and ![diagram](img/flow.png).\n\n```md\n[keep](adr/0001-use-go.md)\n```\n\n[ref]: ../Makefile\n")
+	write(repo, "docs/adr/0001-use-go.md", "# Use Go\n\nBack to the [guide](../guide.md).\n")
+	write(repo, "docs/drafts/skip.md", "# Draft\n")
+	write(repo, ".gitignore", "docs/drafts/\n")
+	write(repo, "CONTRIBUTING.md", "# Contributing\n")
+	write(repo, "notes.md", "# Repo notes\n")
+	git := testGitRepo(t, repo)
+	git("add", "-A")
+	git("commit", "-q", "-m", "docs")
+	// Only committed docs on the base branch are mirrored.
+	write(repo, "docs/wip.md", "# Not committed\n")
+	git("checkout", "-q", "-b", "task/hz-0001-draft")
+	write(repo, "docs/branch.md", "# Branch only\n")
+	git("add", "docs/branch.md")
+	git("commit", "-q", "-m", "branch doc")
+	root := t.TempDir()
+	project := TrackedProject{Key: "app", Name: "App", RepoPath: repo, StorageRoot: root}
+	if err := ensureProjectWiki(project); err != nil {
+		t.Fatalf("ensure wiki: %v", err)
+	}
+	write(projectWikiDir(root), "handbook/notes.md", "# Mine\n")
+	fileURL := func(file string, raw bool) string {
+		if raw {
+			return "https://git.example/app/raw/main/" + file
+		}
+		return "https://git.example/app/blob/main/" + file
+	}
+	rules := []WikiMirrorRule{{Source: "docs/**/*.md", Target: "handbook"}, {Source: "CONTRIBUTING.md", Target: "handbook"}, {Source: "notes.md", Target: "handbook"}}
+	if err := syncWikiMirrors(project, rules, "main", fileURL); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	read := func(rel string) string {
+		b, _ := os.ReadFile(filepath.Join(projectWikiDir(root), filepath.FromSlash(rel)))
+		return string(b)
+	}
+	guide := read("handbook/guide.md")
+	for _, want := range []string{
+		"---\ntitle: Guide\n---\n> Mirrored from `docs/guide.md`",
+		"[[handbook/adr/0001-use-go|the ADR]]",
+		"[[handbook/CONTRIBUTING|setup]]",
+		"![diagram](https://git.example/app/raw/main/docs/img/flow.png)",
+		"[keep](adr/0001-use-go.md)",
+		"[ref]: https://git.example/app/blob/main/Makefile",
+	} {
+		if !strings.Contains(guide, want) {
+			t.Fatalf("guide missing %q:\n%s", want, guide)
+		}
+	}
+	if !strings.Contains(read("handbook/adr/0001-use-go.md"), "[[handbook/guide|guide]]") {
+		t.Fatalf("adr:\n%s", read("handbook/adr/0001-use-go.md"))
+	}
+	for _, rel := range []string{"drafts/skip.md", "wip.md", "branch.md"} {
+		if exists(filepath.Join(projectWikiDir(root), "handbook", filepath.FromSlash(rel))) {
+			t.Fatalf("handbook/%s mirrored from outside the base branch", rel)
+		}
+	}
+	if read("handbook/notes.md") != "# Mine\n" {
+		t.Fatal("human page overwritten")
+	}
+	if w := wikiMirrorWarnings(root); len(w) != 1 || !strings.HasPrefix(w[0], "wiki/handbook/notes.md: page exists") {
+		t.Fatalf("warnings = %v", w)
+	}
+	if revs, _ := wikiPageHistory(root, "handbook/guide.md"); len(revs) != 1 || revs[0].Op != wikiOpMirror {
+		t.Fatalf("revs = %+v", revs)
+	}
+	if err := checkWikiMirror(root, "handbook/guide.md"); err == nil || !strings.Contains(err.Error(), "docs/guide.md") {
+		t.Fatalf("guard = %v", err)
+	}
+	if err := checkWikiMirror(root, "handbook/notes.md"); err != nil {
+		t.Fatalf("guard on own page = %v", err)
+	}
+
+	// A second sync with no changes writes nothing; a removed source removes its page.
+	if err := syncWikiMirrors(project, rules, "main", fileURL); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := wikiPageHistory(root, "handbook/guide.md"); len(revs) != 1 {
+		t.Fatalf("unchanged sync recorded %d revisions", len(revs))
+	}
+	git("checkout", "-q", "main")
+	git("rm", "-q", "docs/adr/0001-use-go.md")
+	git("commit", "-q", "-m", "drop adr")
+	if err := syncWikiMirrors(project, rules, "main", fileURL); err != nil {
+		t.Fatal(err)
+	}
+	if exists(filepath.Join(projectWikiDir(root), "handbook", "adr", "0001-use-go.md")) {
+		t.Fatal("page of a removed source kept")
+	}
+	if _, ok := wikiMirrorSource(root, "handbook/adr/0001-use-go.md"); ok {
+		t.Fatal("manifest still lists the removed page")
+	}
+
+	// A base branch that cannot be listed keeps the pages and tells doctor.
+	if err := syncWikiMirrors(project, rules, "nope", fileURL); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := wikiMirrorSource(root, "handbook/guide.md"); !ok || !exists(filepath.Join(projectWikiDir(root), "handbook", "guide.md")) {
+		t.Fatal("pages dropped when the base branch could not be listed")
+	}
+	if w := wikiMirrorWarnings(root); len(w) != 1 || !strings.HasPrefix(w[0], "wiki_mirrors: cannot list nope") {
+		t.Fatalf("warnings = %v", w)
+	}
+}
+
+func TestSyncProjectWikiMirrorsNexusBaseBranch(t *testing.T) {
+	repo := t.TempDir()
+	git := testGitRepo(t, repo)
+	git("checkout", "-q", "-b", "trunk")
+	if err := ensureDir(filepath.Join(repo, "docs")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "docs", "guide.md"), []byte("# Guide\n\nSee [the setup](../SETUP.md).\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "-A")
+	git("commit", "-q", "-m", "docs")
+	git("remote", "add", "origin", "https://github.com/acme/app.git")
+
+	root := t.TempDir()
+	p := TrackedProject{Key: "app", Name: "App", RepoPath: repo, StorageRoot: root}
+	cfg := Config{GitBaseBranch: "trunk", WikiMirrors: []WikiMirrorRule{{Source: "docs/*.md"}}}
+	if err := syncProjectWiki(p, cfg); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(projectWikiDir(root), "docs", "guide.md"))
+	if err != nil {
+		t.Fatalf("guide not mirrored from trunk: %v (warnings %v)", err, wikiMirrorWarnings(root))
+	}
+	if !strings.Contains(string(b), "https://github.com/acme/app/blob/trunk/SETUP.md") {
+		t.Fatalf("forge link not on trunk:\n%s", b)
+	}
+}